@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"sitemap-go/pkg/backend"
+)
+
+// backendSecurityFlags are the backend TLS and auth flags shared by the monitor and `outbox flush`
+type backendSecurityFlags struct {
+	auth       *string
+	tokenFile  *string
+	keyID      *string
+	certFile   *string
+	keyFile    *string
+	caFile     *string
+	pins       *string
+	minVersion *string
+}
+
+func registerBackendSecurityFlags(fs *flag.FlagSet) *backendSecurityFlags {
+	return &backendSecurityFlags{
+		auth:       fs.String("backend-auth", getEnvOrDefault("BACKEND_AUTH", backend.AuthAPIKey), "Backend auth scheme: api-key, bearer or hmac (env: BACKEND_AUTH)"),
+		tokenFile:  fs.String("backend-token-file", getEnvOrDefault("BACKEND_TOKEN_FILE", ""), "Read the backend credential from this file instead of -backend-api-key (env: BACKEND_TOKEN_FILE)"),
+		keyID:      fs.String("backend-key-id", getEnvOrDefault("BACKEND_KEY_ID", ""), "Key ID sent with HMAC signatures (env: BACKEND_KEY_ID)"),
+		certFile:   fs.String("backend-tls-cert", getEnvOrDefault("BACKEND_TLS_CERT", ""), "Client certificate (PEM) for mutual TLS (env: BACKEND_TLS_CERT)"),
+		keyFile:    fs.String("backend-tls-key", getEnvOrDefault("BACKEND_TLS_KEY", ""), "Client certificate key (PEM) (env: BACKEND_TLS_KEY)"),
+		caFile:     fs.String("backend-tls-ca", getEnvOrDefault("BACKEND_TLS_CA", ""), "CA bundle (PEM) trusted instead of the system roots (env: BACKEND_TLS_CA)"),
+		pins:       fs.String("backend-tls-pins", getEnvOrDefault("BACKEND_TLS_PINS", ""), "Comma-separated sha256/<base64> SPKI pins (env: BACKEND_TLS_PINS)"),
+		minVersion: fs.String("backend-tls-min-version", getEnvOrDefault("BACKEND_TLS_MIN_VERSION", "1.2"), "Minimum TLS version: 1.2 or 1.3 (env: BACKEND_TLS_MIN_VERSION)"),
+	}
+}
+
+func (f *backendSecurityFlags) config() (backend.TLSConfig, backend.AuthConfig) {
+	var pins []string
+	for _, pin := range strings.Split(*f.pins, ",") {
+		if pin = strings.TrimSpace(pin); pin != "" {
+			pins = append(pins, pin)
+		}
+	}
+	tlsConfig := backend.TLSConfig{
+		CertFile:   *f.certFile,
+		KeyFile:    *f.keyFile,
+		CAFile:     *f.caFile,
+		PinnedSPKI: pins,
+		MinVersion: *f.minVersion,
+	}
+	auth := backend.AuthConfig{
+		Scheme:    *f.auth,
+		TokenFile: *f.tokenFile,
+		KeyID:     *f.keyID,
+	}
+	return tlsConfig, auth
+}
+
+// hasCredential reports whether a credential source besides the API key flag is configured
+func (f *backendSecurityFlags) hasCredential() bool {
+	return *f.tokenFile != ""
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"sitemap-go/pkg/monitor"
+)
+
+// fileSetting is a config file value for a flag; empty values leave the flag alone
+type fileSetting struct {
+	flag  string
+	env   string
+	value string
+}
+
+// fileSettings maps a config file onto the monitor and serve flags
+func fileSettings(config *monitor.FileConfig) []fileSetting {
+	g, b := config.Global, config.Backend
+	settings := []fileSetting{
+		{"sitemaps", "SITEMAP_URLS", strings.Join(config.SitemapURLs(), ",")},
+		{"trends-api-url", "TRENDS_API_URL", config.EndpointList()},
+		{"sinks", "OUTPUT_SINKS", config.SinkList()},
+		{"workers", "SITEMAP_WORKERS", formatInt(g.Workers)},
+		{"api-workers", "API_WORKERS", formatInt(g.APIWorkers)},
+		{"api-rate-limit", "API_RATE_LIMIT", formatFloat(g.APIRateLimit)},
+		{"max-urls", "MAX_URLS_PER_SITEMAP", formatInt(g.MaxURLs)},
+		{"batch-size", "BATCH_SIZE", formatInt(g.BatchSize)},
+		{"api-lb-strategy", "API_LB_STRATEGY", g.LBStrategy},
+		{"api-daily-quota", "API_DAILY_QUOTA", formatInt(int(g.DailyQuota))},
+		{"api-monthly-quota", "API_MONTHLY_QUOTA", formatInt(int(g.MonthlyQuota))},
+		{"locales", "KEYWORD_LOCALES", g.Locales},
+		{"locale-detect", "LOCALE_DETECT", formatBool(g.LocaleDetect)},
+		{"change-events", "CHANGE_EVENTS", formatBool(g.ChangeEvents)},
+		{"changed-only", "CHANGED_ONLY", formatBool(g.ChangedOnly)},
+		{"removal-grace", "REMOVAL_GRACE", g.RemovalGrace},
+		{"schedule", "SCHEDULE", g.Schedule},
+		{"run-timeout", "RUN_TIMEOUT", g.RunTimeout},
+		{"drain-timeout", "DRAIN_TIMEOUT", g.DrainTimeout},
+		{"backend-url", "BACKEND_URL", b.URL},
+		{"backend-auth", "BACKEND_AUTH", b.Auth},
+		{"backend-token-file", "BACKEND_TOKEN_FILE", b.TokenFile},
+		{"backend-key-id", "BACKEND_KEY_ID", b.KeyID},
+		{"backend-stream", "BACKEND_STREAM", formatBool(b.Stream)},
+		{"backend-stream-max-bytes", "BACKEND_STREAM_MAX_BYTES", formatInt(b.StreamMaxBytes)},
+		{"backend-tls-cert", "BACKEND_TLS_CERT", b.TLS.CertFile},
+		{"backend-tls-key", "BACKEND_TLS_KEY", b.TLS.KeyFile},
+		{"backend-tls-ca", "BACKEND_TLS_CA", b.TLS.CAFile},
+		{"backend-tls-pins", "BACKEND_TLS_PINS", strings.Join(b.TLS.Pins, ",")},
+		{"backend-tls-min-version", "BACKEND_TLS_MIN_VERSION", b.TLS.MinVersion},
+	}
+	if p := g.Politeness; p != nil {
+		settings = append(settings,
+			fileSetting{"host-max-concurrent", "HOST_MAX_CONCURRENT", formatInt(p.MaxConcurrent)},
+			fileSetting{"host-min-interval", "HOST_MIN_INTERVAL", p.MinInterval},
+			fileSetting{"host-burst", "HOST_BURST", formatInt(p.Burst)},
+		)
+	}
+	if g.Hedge != nil {
+		settings = append(settings,
+			fileSetting{"api-hedge", "API_HEDGE", strconv.FormatBool(g.Hedge.Enabled)},
+			fileSetting{"api-hedge-percentile", "API_HEDGE_PERCENTILE", formatFloat(g.Hedge.Percentile)},
+			fileSetting{"api-hedge-budget", "API_HEDGE_BUDGET", formatFloat(g.Hedge.Budget)},
+		)
+	}
+	return settings
+}
+
+// applyFileConfig sets flags from the config file unless the command line or the
+// environment already did: flags > env > file > defaults
+func applyFileConfig(fs *flag.FlagSet, config *monitor.FileConfig) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for _, setting := range fileSettings(config) {
+		if setting.value == "" || explicit[setting.flag] || os.Getenv(setting.env) != "" {
+			continue
+		}
+		if fs.Lookup(setting.flag) == nil {
+			continue // serve-only flags in a one-shot run
+		}
+		if err := fs.Set(setting.flag, setting.value); err != nil {
+			return fmt.Errorf("config value for -%s: %w", setting.flag, err)
+		}
+	}
+	return nil
+}
+
+func formatInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+func formatFloat(value float64) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*value)
+}
+
+// runConfig checks a config file without running anything
+func runConfig(args []string) int {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	configFile := fs.String("config", getEnvOrDefault("SITEMAP_CONFIG", ""), "JSON config file (env: SITEMAP_CONFIG)")
+	fs.Usage = func() {
+		fmt.Println("USAGE:")
+		fmt.Println("    ./sitemap-go config validate [-config FILE]")
+		fmt.Println("")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "validate" {
+		fs.Usage()
+		return 2
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *configFile == "" && fs.NArg() > 0 {
+		*configFile = fs.Arg(0)
+	}
+	if *configFile == "" {
+		fmt.Println("ERROR: config validate needs -config FILE (or SITEMAP_CONFIG).")
+		return 2
+	}
+
+	config, err := monitor.LoadFileConfig(*configFile)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ %s is valid: %d sites, %d sitemaps, %d providers, %d sinks\n",
+		*configFile, len(config.Sites), len(config.SitemapURLs()), len(config.Providers), len(config.Sinks))
+	for _, site := range config.Sites {
+		details := []string{fmt.Sprintf("%d sitemaps", len(site.Sitemaps))}
+		if site.Extraction != "" {
+			details = append(details, "extraction="+site.Extraction)
+		}
+		if site.Locale != "" {
+			details = append(details, "locale="+site.Locale)
+		}
+		if site.Schedule != "" {
+			details = append(details, "schedule="+site.Schedule)
+		}
+		if len(site.Tags) > 0 {
+			details = append(details, "tags="+strings.Join(site.Tags, ","))
+		}
+		fmt.Printf("   • %s: %s\n", site.Name, strings.Join(details, ", "))
+	}
+	return 0
+}
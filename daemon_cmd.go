@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"sitemap-go/pkg/monitor"
+)
+
+// daemonFlags are the scheduling flags added by `serve`, on top of the monitor flags
+type daemonFlags struct {
+	schedule      *string
+	siteSchedules *string
+	runTimeout    *time.Duration
+	drainTimeout  *time.Duration
+}
+
+func registerDaemonFlags(fs *flag.FlagSet) *daemonFlags {
+	return &daemonFlags{
+		schedule:      fs.String("schedule", getEnvOrDefault("SCHEDULE", "@every 6h"), "Default schedule: @every 6h, @daily or a 5-field UTC cron expression (env: SCHEDULE)"),
+		siteSchedules: fs.String("site-schedules", getEnvOrDefault("SITE_SCHEDULES", ""), "Per-site schedules, e.g. poki.com=@every 2h;y8.com=0 */4 * * * (env: SITE_SCHEDULES)"),
+		runTimeout:    fs.Duration("run-timeout", getEnvDurationOrDefault("RUN_TIMEOUT", monitor.DefaultRunTimeout), "Hard limit for one scheduled run (env: RUN_TIMEOUT)"),
+		drainTimeout:  fs.Duration("drain-timeout", getEnvDurationOrDefault("DRAIN_TIMEOUT", monitor.DefaultDrainTimeout), "How long SIGTERM waits for in-flight runs and submissions (env: DRAIN_TIMEOUT)"),
+	}
+}
+
+// rules returns the -site-schedules rules, then the config file's site rules, then the default
+// schedule; on equally specific hosts the earlier rule wins
+func (f *daemonFlags) rules(siteRules []monitor.ScheduleRule) ([]monitor.ScheduleRule, error) {
+	rules, err := monitor.ParseScheduleRules(*f.siteSchedules)
+	if err != nil {
+		return nil, err
+	}
+	rules = append(rules, siteRules...)
+	if *f.schedule == "" {
+		return rules, nil
+	}
+	defaults, err := monitor.ParseScheduleRules("*=" + *f.schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -schedule: %w", err)
+	}
+	return append(rules, defaults...), nil
+}
+
+// runDaemon monitors the sitemaps on their schedules until SIGINT or SIGTERM
+func runDaemon(sitemapMonitor *monitor.SitemapMonitor, urls []string, workers int, flags *daemonFlags, siteRules []monitor.ScheduleRule) int {
+	rules, err := flags.rules(siteRules)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return 2
+	}
+	daemon, err := monitor.NewDaemon(sitemapMonitor, urls, monitor.DaemonConfig{
+		Rules:        rules,
+		Workers:      workers,
+		RunTimeout:   *flags.runTimeout,
+		DrainTimeout: *flags.drainTimeout,
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return 2
+	}
+	daemon.OnRun(func(run monitor.DaemonRun) {
+		failed := 0
+		for _, result := range run.Results {
+			if !result.Success {
+				failed++
+			}
+		}
+		status := "✅"
+		if run.Err != nil || failed > 0 {
+			status = "⚠️ "
+		}
+		fmt.Printf("%s [%s] %s: %d keywords queried, %d records accepted in %s\n",
+			status, run.Started.Format(time.RFC3339), strings.Join(run.Sites, ", "),
+			run.Summary.QueriedKeywords, run.Summary.BackendAccepted, run.Duration.Round(time.Second))
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("🕰️  Daemon monitoring %d sites (SIGTERM or Ctrl+C drains and stops)\n", len(urls))
+	if err := daemon.Run(ctx); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return 1
+	}
+	fmt.Printf("👋 Daemon stopped at %s\n", time.Now().Format(time.RFC3339))
+	return 0
+}
@@ -9,8 +9,11 @@ import (
 	"strings"
 	"time"
 
+	"sitemap-go/pkg/backend"
 	"sitemap-go/pkg/logger"
 	"sitemap-go/pkg/monitor"
+	"sitemap-go/pkg/parser"
+	"sitemap-go/pkg/storage"
 )
 
 // getEnvOrDefault returns environment variable value or default
@@ -41,6 +44,34 @@ func getEnvBoolOrDefault(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvDurationOrDefault returns environment variable as time.Duration or default
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationVal, err := time.ParseDuration(value); err == nil {
+			return durationVal
+		}
+	}
+	return defaultValue
+}
+
+// configSource describes where settings came from, for the startup log
+func configSource(configFile string) string {
+	if configFile != "" {
+		return "config_file_env_vars_and_flags"
+	}
+	return "env_vars_and_flags"
+}
+
+// getEnvFloatOrDefault returns environment variable as float64 or default
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 func main() {
 	// Global panic recovery to prevent application crash
 	defer func() {
@@ -51,6 +82,24 @@ func main() {
 			os.Exit(1)
 		}
 	}()
+
+	// Subcommands are dispatched before flag parsing; serve takes the monitor flags too
+	daemonMode := false
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve-mock":
+			os.Exit(runServeMock(os.Args[2:]))
+		case "outbox":
+			os.Exit(runOutbox(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "removals":
+			os.Exit(runRemovals(os.Args[2:]))
+		case "serve", "daemon":
+			daemonMode = true
+			os.Args = append(os.Args[:1:1], os.Args[2:]...)
+		}
+	}
 	
 	// Environment variable defaults (GitHub Actions friendly)
 	defaultSitemaps := getEnvOrDefault("SITEMAP_URLS", "")
@@ -66,6 +115,30 @@ func main() {
 	defaultAPIWorkers := getEnvIntOrDefault("API_WORKERS", 8)
 	defaultAPIRateLimit := getEnvOrDefault("API_RATE_LIMIT", "2.0")
 	defaultMaxURLs := getEnvIntOrDefault("MAX_URLS_PER_SITEMAP", 100000)
+	defaultLBStrategy := getEnvOrDefault("API_LB_STRATEGY", "weighted")
+	defaultHedge := getEnvBoolOrDefault("API_HEDGE", false)
+	defaultHedgePercentile := getEnvFloatOrDefault("API_HEDGE_PERCENTILE", 0.95)
+	defaultHedgeBudget := getEnvFloatOrDefault("API_HEDGE_BUDGET", 0.1)
+	defaultDailyQuota := getEnvIntOrDefault("API_DAILY_QUOTA", 0)
+	defaultMonthlyQuota := getEnvIntOrDefault("API_MONTHLY_QUOTA", 0)
+	defaultLocales := getEnvOrDefault("KEYWORD_LOCALES", "")
+	defaultLocaleDetect := getEnvBoolOrDefault("LOCALE_DETECT", true)
+	defaultSinks := getEnvOrDefault("OUTPUT_SINKS", "")
+	defaultWebhookSecret := getEnvOrDefault("WEBHOOK_SECRET", "")
+	defaultStream := getEnvBoolOrDefault("BACKEND_STREAM", false)
+	defaultDryRun := getEnvBoolOrDefault("DRY_RUN", false)
+	defaultDryRunQuery := getEnvBoolOrDefault("DRY_RUN_QUERY", false)
+	defaultDryRunReport := getEnvOrDefault("DRY_RUN_REPORT", "dry-run-report.json")
+	defaultStreamMaxBytes := getEnvIntOrDefault("BACKEND_STREAM_MAX_BYTES", 5<<20)
+	defaultChangeEvents := getEnvBoolOrDefault("CHANGE_EVENTS", false)
+	defaultChangedOnly := getEnvBoolOrDefault("CHANGED_ONLY", false)
+	defaultRemovalGrace := getEnvDurationOrDefault("REMOVAL_GRACE", storage.DefaultRemovalGrace)
+	defaultConfigFile := getEnvOrDefault("SITEMAP_CONFIG", "")
+	defaultResume := getEnvBoolOrDefault("RESUME", false)
+	defaultCheckpointMaxAge := getEnvDurationOrDefault("CHECKPOINT_MAX_AGE", storage.DefaultCheckpointMaxAge)
+	defaultHostMaxConcurrent := getEnvIntOrDefault("HOST_MAX_CONCURRENT", parser.DefaultHostMaxConcurrent)
+	defaultHostMinInterval := getEnvDurationOrDefault("HOST_MIN_INTERVAL", 0)
+	defaultHostBurst := getEnvIntOrDefault("HOST_BURST", parser.DefaultHostBurst)
 	
 	// Command line flags (override environment variables)
 	var (
@@ -83,7 +156,39 @@ func main() {
 		apiWorkers   = flag.Int("api-workers", defaultAPIWorkers, "Number of API query workers (default: 8, env: API_WORKERS)")
 		apiRateLimit = flag.String("api-rate-limit", defaultAPIRateLimit, "API requests per second (env: API_RATE_LIMIT)")
 		maxURLs      = flag.Int("max-urls", defaultMaxURLs, "Maximum URLs per sitemap (env: MAX_URLS_PER_SITEMAP)")
+		lbStrategy   = flag.String("api-lb-strategy", defaultLBStrategy, "Load balancing across API endpoints: weighted or least-loaded (env: API_LB_STRATEGY)")
+		hedge           = flag.Bool("api-hedge", defaultHedge, "Hedge slow API batches to another endpoint (env: API_HEDGE)")
+		hedgePercentile = flag.Float64("api-hedge-percentile", defaultHedgePercentile, "Latency percentile that triggers a hedge (env: API_HEDGE_PERCENTILE)")
+		hedgeBudget     = flag.Float64("api-hedge-budget", defaultHedgeBudget, "Max hedges as a fraction of API requests (env: API_HEDGE_BUDGET)")
+		dailyQuota      = flag.Int("api-daily-quota", defaultDailyQuota, "API requests per endpoint per UTC day, 0 = unlimited (env: API_DAILY_QUOTA)")
+		monthlyQuota    = flag.Int("api-monthly-quota", defaultMonthlyQuota, "API requests per endpoint per UTC month, 0 = unlimited (env: API_MONTHLY_QUOTA)")
+		locales         = flag.String("locales", defaultLocales, "Per-site/path keyword locales, e.g. poki.de=de-DE,example.com/fr/=fr-FR (env: KEYWORD_LOCALES)")
+		localeDetect    = flag.Bool("locale-detect", defaultLocaleDetect, "Detect locales from hreflang and /de/-style paths (env: LOCALE_DETECT)")
+		sinks           = flag.String("sinks", defaultSinks, "Result outputs, e.g. backend,jsonl:out/metrics.jsonl,csv:out/metrics.csv (env: OUTPUT_SINKS)")
+		webhookSecret   = flag.String("webhook-secret", defaultWebhookSecret, "HMAC secret for signing webhook sink requests (env: WEBHOOK_SECRET)")
+		stream          = flag.Bool("backend-stream", defaultStream, "Stream results to the backend as gzipped NDJSON while querying (env: BACKEND_STREAM)")
+		streamMaxBytes  = flag.Int("backend-stream-max-bytes", defaultStreamMaxBytes, "Max compressed bytes per streamed request (env: BACKEND_STREAM_MAX_BYTES)")
+		dryRun          = flag.Bool("dry-run", defaultDryRun, "Run the pipeline without submitting or persisting anything; write a report instead (env: DRY_RUN)")
+		dryRunQuery     = flag.Bool("dry-run-query", defaultDryRunQuery, "Let -dry-run query the keyword API, spending real quota (env: DRY_RUN_QUERY)")
+		dryRunReport    = flag.String("dry-run-report", defaultDryRunReport, "Where -dry-run writes its report (env: DRY_RUN_REPORT)")
+		changeEvents    = flag.Bool("change-events", defaultChangeEvents, "Report URLs added, removed or modified since the last run, and confirmed removals, to the backend (env: CHANGE_EVENTS)")
+		changedOnly     = flag.Bool("changed-only", defaultChangedOnly, "Extract and query only pages added or modified since the last run (env: CHANGED_ONLY)")
+		removalGrace    = flag.Duration("removal-grace", defaultRemovalGrace, "How long a page must be missing from its sitemap, over at least two runs, before it counts as removed (env: REMOVAL_GRACE)")
+		configFile      = flag.String("config", defaultConfigFile, "JSON config file with sites and pipeline settings; flags and env vars override it (env: SITEMAP_CONFIG)")
+		resume          = flag.Bool("resume", defaultResume, "Continue the last interrupted run from its checkpoint instead of starting over (env: RESUME)")
+		checkpointAge   = flag.Duration("checkpoint-max-age", defaultCheckpointMaxAge, "Checkpoints older than this are discarded instead of resumed (env: CHECKPOINT_MAX_AGE)")
+		hostMaxConc     = flag.Int("host-max-concurrent", defaultHostMaxConcurrent, "Sitemap requests in flight per host, 0 = unlimited (env: HOST_MAX_CONCURRENT)")
+		hostMinInterval = flag.Duration("host-min-interval", defaultHostMinInterval, "Minimum gap between sitemap requests to one host once the burst is spent (env: HOST_MIN_INTERVAL)")
+		hostBurst       = flag.Int("host-burst", defaultHostBurst, "Sitemap requests per host that may start back to back (env: HOST_BURST)")
 	)
+	backendSecurity := registerBackendSecurityFlags(flag.CommandLine)
+	var daemon *daemonFlags
+	var shutdownGrace *time.Duration
+	if daemonMode {
+		daemon = registerDaemonFlags(flag.CommandLine)
+	} else {
+		shutdownGrace = flag.Duration("shutdown-grace", getEnvDurationOrDefault("SHUTDOWN_GRACE", defaultShutdownGrace), "How long in-flight API batches and submissions get after SIGINT or SIGTERM (env: SHUTDOWN_GRACE)")
+	}
 	
 	flag.Parse()
 	
@@ -92,18 +197,54 @@ func main() {
 		return
 	}
 	
+	// Config file values fill in whatever flags and env vars left at their defaults
+	var fileConfig *monitor.FileConfig
+	if *configFile != "" {
+		var configErr error
+		if fileConfig, configErr = monitor.LoadFileConfig(*configFile); configErr != nil {
+			fmt.Printf("ERROR: %v\n", configErr)
+			os.Exit(1)
+		}
+		if configErr = applyFileConfig(flag.CommandLine, fileConfig); configErr != nil {
+			fmt.Printf("ERROR: %v\n", configErr)
+			os.Exit(1)
+		}
+	}
+	
+	rateLimit, rateErr := strconv.ParseFloat(*apiRateLimit, 64)
+	if rateErr != nil || rateLimit <= 0 {
+		fmt.Printf("ERROR: Invalid -api-rate-limit value %q: must be a positive number of requests per second\n", *apiRateLimit)
+		os.Exit(1)
+	}
+	if shutdownGrace != nil && *shutdownGrace <= 0 {
+		fmt.Printf("ERROR: Invalid -shutdown-grace value %s: must be positive\n", *shutdownGrace)
+		os.Exit(1)
+	}
+	
 	// Validate required parameters
-	if *backendURL == "" {
+	sinkSpecs, sinkErr := backend.ParseSinkList(*sinks)
+	if sinkErr != nil {
+		fmt.Printf("ERROR: Invalid -sinks value: %v\n", sinkErr)
+		fmt.Println("")
+		printUsage()
+		os.Exit(1)
+	}
+	// A dry run records submissions locally, so it never needs the backend
+	useBackend := !*dryRun && (len(sinkSpecs) == 0 || backend.HasSink(sinkSpecs, backend.SinkBackend))
+	
+	if useBackend && *backendURL == "" {
 		fmt.Println("ERROR: Backend URL is required for monitoring to be meaningful.")
-		fmt.Println("Use -backend-url flag or BACKEND_URL environment variable.")
+		fmt.Println("Use -backend-url flag or BACKEND_URL environment variable,")
+		fmt.Println("or write results elsewhere with -sinks (e.g. -sinks jsonl:out/metrics.jsonl).")
 		fmt.Println("")
 		printUsage()
 		os.Exit(1)
 	}
 	
-	if *backendAPIKey == "" {
+	if useBackend && *backendAPIKey == "" && !backendSecurity.hasCredential() {
 		fmt.Println("ERROR: Backend API key is required for authentication.")
-		fmt.Println("Use -backend-api-key flag or BACKEND_API_KEY environment variable.")
+		fmt.Println("Use -backend-api-key flag or BACKEND_API_KEY environment variable,")
+		fmt.Println("or read the credential from a file with -backend-token-file.")
 		fmt.Println("⚠️  SECURITY WARNING: Never hardcode API keys in source code!")
 		fmt.Println("")
 		printUsage()
@@ -146,8 +287,15 @@ func main() {
 		"api_rate_limit":      *apiRateLimit,
 		"batch_size":          *batchSize,
 		"max_urls":            *maxURLs,
+		"api_lb_strategy":     *lbStrategy,
+		"api_hedge":           *hedge,
+		"api_daily_quota":     *dailyQuota,
+		"api_monthly_quota":   *monthlyQuota,
+		"locale_detect":       *localeDetect,
+		"locale_rules_set":    *locales != "",
 		"backend_url_set":     *backendURL != "",
-		"config_source":       "env_vars_and_flags",
+		"sinks":               len(sinkSpecs),
+		"config_source":       configSource(*configFile),
 	})
 	
 	if *debug {
@@ -156,7 +304,7 @@ func main() {
 	
 	log.Info("Starting Sitemap Content Monitor Script")
 	
-	// Default sitemap URLs for game sites monitoring
+	// Built-in game sites, monitored when neither -sitemaps nor a config file lists any
 	defaultSitemapList := []string{
 		"https://poki.com/sitemap.xml",
 		"https://www.y8.com/sitemap.xml",
@@ -174,6 +322,7 @@ func main() {
 		}
 	} else {
 		urls = defaultSitemapList
+		log.Info("No sitemaps configured, monitoring the built-in game sites (use -sitemaps or -config)")
 	}
 	
 	secureLog.SafeInfo("Sitemap configuration loaded", map[string]interface{}{
@@ -181,33 +330,98 @@ func main() {
 		"workers":       *workers,
 	})
 	
+	// -workers, -api-workers, -api-rate-limit and -max-urls tune the pipeline's concurrency
+	concurrency := monitor.DefaultConcurrencyConfig()
+	concurrency.MainWorkers = *workers
+	concurrency.APIWorkers = *apiWorkers
+	concurrency.APIRequestsPerSecond = rateLimit
+	concurrency.MaxURLsPerSitemap = *maxURLs
+	
 	// Create sitemap monitor with backend configuration using builder pattern
-	sitemapMonitor, createErr := monitor.NewMonitorConfigBuilder().
+	monitorBuilder := monitor.NewMonitorConfigBuilder().
 		WithTrendsAPI(*trendsAPIURL).
-		WithBackend(*backendURL, *backendAPIKey).
+		WithLoadBalancing(*lbStrategy).
+		WithHedging(*hedge, *hedgePercentile, *hedgeBudget).
+		WithQuota(int64(*dailyQuota), int64(*monthlyQuota)).
+		WithLocales(*locales, *localeDetect).
+		WithSinks(*sinks, *webhookSecret).
+		WithStreaming(*stream, *streamMaxBytes).
+		WithDryRun(*dryRun, *dryRunQuery).
+		WithChangeEvents(*changeEvents).
+		WithChangedOnly(*changedOnly).
+		WithRemovalGrace(*removalGrace).
+		WithCheckpoints(*resume, *checkpointAge).
+		WithHostLimits(parser.HostLimit{MaxConcurrent: *hostMaxConc, MinInterval: *hostMinInterval, Burst: *hostBurst}).
 		WithBatchSize(*batchSize).
 		WithWorkers(*workers).
-		WithEncryptionKey(*encryptionKey).
-		Build()
+		WithConcurrency(concurrency).
+		WithEncryptionKey(*encryptionKey)
+	if fileConfig != nil {
+		monitorBuilder = monitorBuilder.WithSites(fileConfig.Sites)
+	}
+	if useBackend {
+		backendTLS, backendAuth := backendSecurity.config()
+		monitorBuilder = monitorBuilder.WithBackend(*backendURL, *backendAPIKey).
+			WithBackendSecurity(backendTLS, backendAuth)
+	}
+	sitemapMonitor, createErr := monitorBuilder.Build()
 	if createErr != nil {
 		log.WithError(createErr).Fatal("Failed to create sitemap monitor")
 	}
+	// An interrupted or failed run exits with its own code, but only after the monitor is closed
+	var shutdown *runShutdown
+	exitCode := 0
 	defer func() {
 		if err := sitemapMonitor.Close(); err != nil {
 			log.WithError(err).Warn("Failed to close sitemap monitor cleanly")
 		}
+		shutdown.stop()
+		if code := shutdown.exitCode(); code != 0 {
+			exitCode = code
+		}
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
 	}()
 	
-	// Backend submission is always enabled now
-	secureLog.SafeInfo("Backend submission configured", map[string]interface{}{
-		"backend_url":     secureLog.MaskAPIEndpoint(*backendURL),
-		"backend_api_key": "api-key#" + secureLog.GenerateHash(*backendAPIKey)[:8],
-		"batch_size":      *batchSize,
-	})
+	if useBackend {
+		credential := "token-file"
+		if *backendAPIKey != "" {
+			credential = "api-key#" + secureLog.GenerateHash(*backendAPIKey)[:8]
+		}
+		secureLog.SafeInfo("Backend submission configured", map[string]interface{}{
+			"backend_url":     secureLog.MaskAPIEndpoint(*backendURL),
+			"backend_api_key": credential,
+			"backend_auth":    *backendSecurity.auth,
+			"mutual_tls":      *backendSecurity.certFile != "",
+			"batch_size":      *batchSize,
+			"stream":          *stream,
+		})
+	}
+	if len(sinkSpecs) > 0 {
+		sinkTypes := make([]string, len(sinkSpecs))
+		for i, spec := range sinkSpecs {
+			sinkTypes[i] = spec.Type + "(" + spec.OnFailure + ")"
+		}
+		log.WithField("sinks", strings.Join(sinkTypes, ",")).Info("Output sinks configured")
+	}
+	
+	if daemon != nil {
+		var siteRules []monitor.ScheduleRule
+		if fileConfig != nil {
+			siteRules = monitor.SiteScheduleRules(fileConfig.Sites)
+		}
+		if code := runDaemon(sitemapMonitor, urls, *workers, daemon, siteRules); code != 0 {
+			sitemapMonitor.Close()
+			os.Exit(code)
+		}
+		return
+	}
 	
 	// Run monitoring with panic recovery and timeout control
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute) // Prevent infinite hang
 	defer cancel()
+	shutdown = watchShutdown(sitemapMonitor, cancel, *shutdownGrace)
 	startTime := time.Now()
 	
 	// Create progress tracker for cleaner output
@@ -215,6 +429,9 @@ func main() {
 	progressTracker.StartOperation("sitemap_processing", len(urls), "Processing sitemaps")
 
 	log.Info("🚀 Starting sitemap monitoring...")
+	if *dryRun {
+		log.WithField("query_api", *dryRunQuery).Warn("🧪 Dry run: nothing will be submitted or persisted")
+	}
 
 	var results []*monitor.MonitorResult
 	var err error
@@ -233,8 +450,9 @@ func main() {
 	progressTracker.CompleteOperation("sitemap_processing")
 	
 	if err != nil {
-		log.WithError(err).Fatal("Monitoring failed")
-		// Note: log.Fatal() already calls os.Exit(1), no need for explicit os.Exit(1)
+		log.WithError(err).Error("Monitoring failed")
+		exitCode = 1
+		return
 	}
 	
 	duration := time.Since(startTime)
@@ -271,6 +489,89 @@ func main() {
 	}
 	fmt.Printf("🔑 Total Keywords Extracted: %d\n", totalKeywords)
 
+	// Pages changed since each sitemap's previous snapshot
+	var changes monitor.SitemapChanges
+	compared := 0
+	for _, result := range results {
+		if result.Changes != nil && !result.Changes.FirstSnapshot {
+			changes.Added += result.Changes.Added
+			changes.Removed += result.Changes.Removed
+			changes.Modified += result.Changes.Modified
+			compared++
+		}
+	}
+	if compared > 0 {
+		fmt.Printf("🔄 Page Changes: %d added, %d removed, %d modified across %d sitemaps\n",
+			changes.Added, changes.Removed, changes.Modified, compared)
+	}
+
+	// Let queued submissions finish so the backend counts below are final
+	waitCtx, waitCancel := shutdown.drainContext(ctx, 30*time.Second)
+	if !sitemapMonitor.WaitForSubmissions(waitCtx) {
+		log.Warn("Backend submissions still running, counts below are partial")
+	}
+	waitCancel()
+	
+	// API budget spent across runs and keywords deferred to the next quota window
+	runSummary := sitemapMonitor.GetRunSummary(ctx)
+	if runSummary.Resumed {
+		fmt.Printf("♻️  Resumed Run %s: %d sitemaps from checkpoint, %d keywords already queried\n",
+			runSummary.RunID, runSummary.ResumedSitemaps, runSummary.ResumedQueried)
+	}
+	if len(runSummary.QuotaUsage) > 0 {
+		fmt.Printf("\n💳 API Budget:\n")
+		for _, usage := range runSummary.QuotaUsage {
+			fmt.Printf("   • %s - today: %d used, %s remaining; month: %d used, %s remaining\n",
+				secureLog.MaskAPIEndpoint(usage.Endpoint),
+				usage.DailyUsed, formatQuotaRemaining(usage.DailyRemaining()),
+				usage.MonthlyUsed, formatQuotaRemaining(usage.MonthlyRemaining()))
+		}
+	}
+	if throttled := throttledHosts(runSummary.HostUsage); len(throttled) > 0 {
+		fmt.Printf("\n🚦 Host Politeness:\n")
+		for _, host := range throttled {
+			fmt.Printf("   • %s - %d requests, %d held back for %s total, peak %d concurrent\n",
+				host.Host, host.Requests, host.Throttled, time.Duration(host.WaitMs)*time.Millisecond, host.PeakActive)
+		}
+	}
+	if runSummary.DeferredKeywords > 0 {
+		fmt.Printf("⏸️  Deferred Keywords (quota exhausted): %d\n", runSummary.DeferredKeywords)
+	}
+	if runSummary.NoDataKeywords > 0 {
+		fmt.Printf("🕳️  Keywords Without API Data (retry later): %d\n", runSummary.NoDataKeywords)
+	}
+	if runSummary.UnqueriedKeywords > 0 {
+		fmt.Printf("⏭️  Carried Over Keywords (run ended first): %d\n", runSummary.UnqueriedKeywords)
+	}
+	if submitted := runSummary.BackendAccepted + runSummary.BackendRejected + runSummary.BackendDuplicates; submitted > 0 && !*dryRun {
+		fmt.Printf("📤 Backend Records: %d accepted, %d duplicate, %d rejected\n",
+			runSummary.BackendAccepted, runSummary.BackendDuplicates, runSummary.BackendRejected)
+	}
+	if runSummary.ChangeEvents > 0 {
+		fmt.Printf("🆕 Sitemap Change Events: %d\n", runSummary.ChangeEvents)
+	}
+	if runSummary.ConfirmedRemovals > 0 || runSummary.ReappearedURLs > 0 || runSummary.PendingRemovals > 0 {
+		fmt.Printf("🪦 Removed Pages: %d confirmed, %d reappeared, %d pending the %s grace period (see: ./sitemap-go removals list)\n",
+			runSummary.ConfirmedRemovals, runSummary.ReappearedURLs, runSummary.PendingRemovals, *removalGrace)
+	}
+	if runSummary.SubmitWaitMs > 0 || runSummary.SpilledTasks > 0 {
+		fmt.Printf("⏳ Submission Backpressure: waited %s for queue space, %d tasks spilled to outbox (peak depth %d)\n",
+			(time.Duration(runSummary.SubmitWaitMs) * time.Millisecond).String(), runSummary.SpilledTasks, runSummary.PeakQueueDepth)
+	}
+	if runSummary.BackendRejected > 0 {
+		fmt.Printf("☠️  Rejected records were dead-lettered; inspect them with: ./sitemap-go outbox dead-letters\n")
+	}
+	if sig := shutdown.received(); sig != nil {
+		fmt.Printf("\n🛑 Partial Run (stopped by %s, exit code %d):\n", sig, shutdown.exitCode())
+		fmt.Printf("   • Keywords carried over to the next run: %d\n", runSummary.UnqueriedKeywords)
+		if runSummary.QueuedSubmissions > 0 {
+			fmt.Printf("   • Submissions left in the outbox: %d (sent on the next start or with: ./sitemap-go outbox flush)\n", runSummary.QueuedSubmissions)
+		}
+		if !*dryRun {
+			fmt.Printf("   • Checkpoint kept: continue with -resume\n")
+		}
+	}
+
 	// Show only failed results for cleaner output
 	failedResults := 0
 	for _, result := range results {
@@ -289,6 +590,22 @@ func main() {
 		}
 	}
 
+	if *dryRun {
+		report, reportErr := sitemapMonitor.WriteDryRunReport(ctx, *dryRunReport)
+		if reportErr != nil {
+			log.WithError(reportErr).Error("Failed to write dry-run report")
+			return
+		}
+		fmt.Printf("\n🧪 Dry Run (nothing submitted or persisted):\n")
+		fmt.Printf("   • Keywords that would be queried: %d\n", len(report.Candidates))
+		if report.QueriedAPI {
+			fmt.Printf("   • Records that would be submitted: %d\n", len(report.Submissions))
+		}
+		fmt.Printf("   • State keys that would change: %d written, %d deleted\n", len(report.StateWrites), len(report.StateDeletes))
+		fmt.Printf("   • Report: %s\n", *dryRunReport)
+		return
+	}
+	
 	fmt.Printf("\n💾 Results have been saved to local storage for future reference.\n")
 	
 	// Export data summary for GitHub Actions
@@ -310,17 +627,56 @@ func main() {
 	}
 }
 
+// formatQuotaRemaining renders a remaining quota, where -1 means unlimited
+func formatQuotaRemaining(remaining int64) string {
+	if remaining < 0 {
+		return "unlimited"
+	}
+	return strconv.FormatInt(remaining, 10)
+}
+
+// throttledHosts returns the hosts whose politeness limits held requests back
+func throttledHosts(usage []parser.HostStats) []parser.HostStats {
+	var throttled []parser.HostStats
+	for _, host := range usage {
+		if host.Throttled > 0 {
+			throttled = append(throttled, host)
+		}
+	}
+	return throttled
+}
+
 func printUsage() {
 	fmt.Println("Sitemap-Go Content Monitoring Script")
 	fmt.Println("")
 	fmt.Println("USAGE:")
 	fmt.Println("    ./sitemap-go -backend-url <URL> [OPTIONS]")
 	fmt.Println("    ./sitemap-go  # Uses environment variables")
+	fmt.Println("    ./sitemap-go serve [-schedule SPEC] [-site-schedules RULES] [OPTIONS]")
+	fmt.Println("    ./sitemap-go serve-mock [-api-addr ADDR] [-backend-addr ADDR] [-error-rate F] ...")
+	fmt.Println("    ./sitemap-go outbox list|flush|dead-letters [-data-dir DIR]")
+	fmt.Println("    ./sitemap-go removals list|reappear [-data-dir DIR] [PAGE_URL]")
+	fmt.Println("")
+	fmt.Println("SUBCOMMANDS:")
+	fmt.Println("    serve                  Keep running and monitor each site on its schedule (alias: daemon);")
+	fmt.Println("                           takes every option below plus -schedule, -site-schedules,")
+	fmt.Println("                           -run-timeout and -drain-timeout")
+	fmt.Println("    serve-mock             Run mock keyword API and backend servers for local testing")
+	fmt.Println("    outbox list            Show backend submissions waiting in the durable outbox")
+	fmt.Println("    outbox flush           Send outbox submissions now (otherwise replayed at next startup and daemon run)")
+	fmt.Println("                           through the backend, or the -sinks/-config outputs")
+	fmt.Println("    outbox dead-letters    Show records the backend rejected (not retried)")
+	fmt.Println("    removals list          Show pages missing from their sitemaps, pending or confirmed removed")
+	fmt.Println("    removals reappear URL  Clear a page from the removal ledger, e.g. after a mistaken delisting")
+	fmt.Println("    config validate        Check a -config file and list its sites")
 	fmt.Println("")
-	fmt.Println("REQUIRED:")
+	fmt.Println("REQUIRED (unless -sinks lists only file or webhook outputs):")
 	fmt.Println("    -backend-url string    Backend API URL (env: BACKEND_URL)")
 	fmt.Println("")
 	fmt.Println("BASIC OPTIONS:")
+	fmt.Println("    -config string         JSON config file: global settings, providers, backend, sinks and sites")
+	fmt.Println("                           with per-site filters, extraction, locale, rate limits, schedule and")
+	fmt.Println("                           tags; flags and env vars override its values (env: SITEMAP_CONFIG)")
 	fmt.Println("    -sitemaps string       Comma-separated sitemap URLs (env: SITEMAP_URLS)")
 	fmt.Println("    -workers int           Sitemap workers (default: 15, env: SITEMAP_WORKERS)")
 	fmt.Println("    -debug                 Enable debug logging (env: DEBUG)")
@@ -330,24 +686,102 @@ func printUsage() {
 	fmt.Println("ADVANCED OPTIONS:")
 	fmt.Println("    -api-workers int       API query workers (default: 8, env: API_WORKERS)")
 	fmt.Println("    -api-rate-limit string API requests/sec (default: 2.0, env: API_RATE_LIMIT)")
-	fmt.Println("    -max-urls int          Max URLs per sitemap (default: 100000, env: MAX_URLS_PER_SITEMAP)")
+	fmt.Println("    -max-urls int          Max URLs per sitemap, 0 = unlimited (default: 100000, env: MAX_URLS_PER_SITEMAP)")
+	fmt.Println("    -api-lb-strategy string Multi-endpoint selection: weighted|least-loaded (env: API_LB_STRATEGY)")
+	fmt.Println("    -api-hedge             Hedge slow API batches to another endpoint (env: API_HEDGE)")
+	fmt.Println("    -api-hedge-percentile  Latency percentile triggering a hedge (default: 0.95)")
+	fmt.Println("    -api-hedge-budget      Max hedges per API request (default: 0.1)")
+	fmt.Println("    -api-daily-quota       API requests per endpoint per UTC day (default: 0 = unlimited)")
+	fmt.Println("    -api-monthly-quota     API requests per endpoint per UTC month (default: 0 = unlimited)")
+	fmt.Println("    -locales string        Keyword locales per site or path: poki.de=de-DE,example.com/fr/=fr-FR,*=en")
+	fmt.Println("    -locale-detect         Detect locales from hreflang and /de/ paths (default: true)")
+	fmt.Println("    -sinks string          Outputs: backend,jsonl:PATH,csv:PATH,webhook:URL; per sink")
+	fmt.Println("                           options: ;batch=50;on-failure=retry|drop (default: backend)")
+	fmt.Println("    -webhook-secret string HMAC-SHA256 secret for webhook signatures (env: WEBHOOK_SECRET)")
+	fmt.Println("    -backend-stream        Stream results as gzipped NDJSON (backend must accept application/x-ndjson)")
+	fmt.Println("    -dry-run               Discover, parse, extract and dedupe without submitting or persisting;")
+	fmt.Println("                           writes what would have happened to -dry-run-report (dry-run-report.json)")
+	fmt.Println("    -dry-run-query         Also query the keyword API during -dry-run (spends real quota)")
+	fmt.Println("    -backend-stream-max-bytes int Max compressed bytes per streamed request (default: 5242880)")
+	fmt.Println("    -change-events         Send URLs added, removed or modified since the last run to the backend,")
+	fmt.Println("                           plus delisted/relisted notices (also to jsonl, csv and webhook sinks)")
+	fmt.Println("    -changed-only          Extract and query only pages added or modified since the last run;")
+	fmt.Println("                           modified pages are queried again (first runs process every page);")
+	fmt.Println("                           changes past -max-urls are left for the next runs")
+	fmt.Println("    -removal-grace         How long a page must be missing, over at least two runs, before it")
+	fmt.Println("                           counts as removed and is delisted (default: 24h)")
+	fmt.Println("    -resume                Continue the last interrupted run from its checkpoint: parsed sitemaps")
+	fmt.Println("                           and queried keywords are skipped, the run ID is kept")
+	fmt.Println("    -checkpoint-max-age    Discard checkpoints older than this instead of resuming (default: 24h)")
+	fmt.Println("    -host-max-concurrent   Sitemap requests in flight per host across all parsers (default: 4)")
+	fmt.Println("    -host-min-interval     Minimum gap between requests to one host, e.g. 250ms (default: 0)")
+	fmt.Println("    -host-burst            Requests per host that may start before -host-min-interval applies (default: 1)")
+	fmt.Println("                           Sites override all three with rate_limit in -config")
+	fmt.Println("    -shutdown-grace        Time in-flight API batches and submissions get after SIGINT/SIGTERM;")
+	fmt.Println("                           the run then exits with 130 (SIGINT) or 143 (SIGTERM) (default: 30s)")
+	fmt.Println("    -backend-auth string   Backend auth: api-key (default), bearer or hmac; the credential is")
+	fmt.Println("                           -backend-api-key or the contents of -backend-token-file")
+	fmt.Println("    -backend-token-file    File holding the backend credential, re-read when it changes")
+	fmt.Println("    -backend-key-id string Key ID sent as X-Key-Id with hmac signatures")
+	fmt.Println("    -backend-tls-cert/-backend-tls-key  Client certificate and key (PEM) for mutual TLS")
+	fmt.Println("    -backend-tls-ca string CA bundle (PEM) trusted instead of the system roots")
+	fmt.Println("    -backend-tls-pins      Comma-separated sha256/<base64> SPKI pins the server chain must match")
+	fmt.Println("    -backend-tls-min-version Minimum TLS version: 1.2 (default) or 1.3")
 	fmt.Println("    -help                  Show this help message")
 	fmt.Println("")
 	fmt.Println("ENVIRONMENT VARIABLES (GitHub Actions friendly):")
-	fmt.Println("    BACKEND_URL            Backend API URL (required)")
+	fmt.Println("    BACKEND_URL            Backend API URL (required when the backend sink is used)")
 	fmt.Println("    BACKEND_API_KEY        Backend API key")
+	fmt.Println("    SITEMAP_CONFIG         JSON config file")
 	fmt.Println("    SITEMAP_URLS           Comma-separated sitemap URLs")
 	fmt.Println("    SITEMAP_WORKERS        Number of sitemap workers (15)")
 	fmt.Println("    API_WORKERS            Number of API workers (8)")
 	fmt.Println("    API_RATE_LIMIT         API requests per second (2.0)")
 	fmt.Println("    BATCH_SIZE             Keywords per API batch (5)")
 	fmt.Println("    MAX_URLS_PER_SITEMAP   Max URLs per sitemap (100000)")
+	fmt.Println("    TRENDS_API_URL         Keyword API endpoint(s), comma-separated; per endpoint")
+	fmt.Println("                           options: url;weight=3;rps=2.5;concurrency=10;daily=5000")
+	fmt.Println("    API_LB_STRATEGY        weighted (default) or least-loaded")
+	fmt.Println("    API_HEDGE              Enable request hedging across endpoints (false)")
+	fmt.Println("    API_DAILY_QUOTA        API requests per endpoint per UTC day (0 = unlimited)")
+	fmt.Println("    KEYWORD_LOCALES        Keyword locales per site or path (e.g. poki.de=de-DE)")
+	fmt.Println("    LOCALE_DETECT          Detect locales from hreflang and paths (true)")
+	fmt.Println("    OUTPUT_SINKS           Result outputs (e.g. backend,jsonl:out/metrics.jsonl)")
+	fmt.Println("    WEBHOOK_SECRET         Webhook signing secret")
+	fmt.Println("    BACKEND_STREAM         Stream results to the backend as NDJSON (false)")
+	fmt.Println("    DRY_RUN                Report instead of submitting or persisting (false)")
+	fmt.Println("    DRY_RUN_QUERY          Query the keyword API during a dry run (false)")
+	fmt.Println("    BACKEND_STREAM_MAX_BYTES Max compressed bytes per streamed request (5242880)")
+	fmt.Println("    CHANGE_EVENTS          Send sitemap change events to the backend (false)")
+	fmt.Println("    CHANGED_ONLY           Process only pages added or modified since the last run (false)")
+	fmt.Println("    REMOVAL_GRACE          How long a page must be missing before it counts as removed (24h)")
+	fmt.Println("    RESUME                 Resume the last interrupted run (false)")
+	fmt.Println("    CHECKPOINT_MAX_AGE     Max age of a resumable checkpoint (24h)")
+	fmt.Println("    HOST_MAX_CONCURRENT    Sitemap requests in flight per host (4)")
+	fmt.Println("    HOST_MIN_INTERVAL      Minimum gap between requests to one host (0)")
+	fmt.Println("    HOST_BURST             Requests per host before the interval applies (1)")
+	fmt.Println("    SHUTDOWN_GRACE         Grace period for in-flight work on SIGINT/SIGTERM (30s)")
+	fmt.Println("    SCHEDULE               serve: default schedule, e.g. @every 6h or 0 */4 * * * (@every 6h)")
+	fmt.Println("    SITE_SCHEDULES         serve: per-site schedules, e.g. poki.com=@every 2h;y8.com=@daily")
+	fmt.Println("    RUN_TIMEOUT            serve: hard limit for one run (30m)")
+	fmt.Println("    DRAIN_TIMEOUT          serve: time SIGTERM waits for in-flight work (2m)")
+	fmt.Println("    BACKEND_AUTH           Backend auth scheme: api-key, bearer or hmac (api-key)")
+	fmt.Println("    BACKEND_TOKEN_FILE     File holding the backend credential")
+	fmt.Println("    BACKEND_KEY_ID         Key ID sent with hmac signatures")
+	fmt.Println("    BACKEND_TLS_CERT       Client certificate (PEM) for mutual TLS")
+	fmt.Println("    BACKEND_TLS_KEY        Client certificate key (PEM)")
+	fmt.Println("    BACKEND_TLS_CA         CA bundle (PEM) for a private CA")
+	fmt.Println("    BACKEND_TLS_PINS       Comma-separated SPKI pins")
+	fmt.Println("    BACKEND_TLS_MIN_VERSION Minimum TLS version (1.2)")
 	fmt.Println("    DEBUG                  Enable debug logging (false)")
 	fmt.Println("")
 	fmt.Println("EXAMPLES:")
 	fmt.Println("    # Command line usage")
 	fmt.Println("    ./sitemap-go -backend-url \"https://api.example.com\"")
 	fmt.Println("    ./sitemap-go -backend-url \"https://api.example.com\" -workers 20 -api-workers 8")
+	fmt.Println("    ./sitemap-go -sinks \"jsonl:out/metrics.jsonl,csv:out/metrics.csv\"  # No backend needed")
+	fmt.Println("    ./sitemap-go -dry-run -sitemaps \"https://new-site.example/sitemap.xml\"  # Try a site list safely")
+	fmt.Println("    ./sitemap-go config validate -config sites.json && ./sitemap-go -config sites.json")
 	fmt.Println("")
 	fmt.Println("    # Environment variables (GitHub Actions)")
 	fmt.Println("    export BACKEND_URL=\"https://api.example.com\"")
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/monitor"
+	"sitemap-go/pkg/storage"
+)
+
+// runOutbox inspects or flushes backend submissions persisted by earlier runs
+func runOutbox(args []string) int {
+	fs := flag.NewFlagSet("outbox", flag.ContinueOnError)
+	var (
+		dataDir       = fs.String("data-dir", "./data", "Directory holding the encrypted run state")
+		encryptionKey = fs.String("encryption-key", getEnvOrDefault("ENCRYPTION_KEY", ""), "Encryption key used by the monitor (env: ENCRYPTION_KEY)")
+		backendURL    = fs.String("backend-url", getEnvOrDefault("BACKEND_URL", ""), "Backend API URL, required for flush (env: BACKEND_URL)")
+		backendAPIKey = fs.String("backend-api-key", getEnvOrDefault("BACKEND_API_KEY", ""), "Backend API key, required for flush (env: BACKEND_API_KEY)")
+		batchSize     = fs.Int("batch-size", getEnvIntOrDefault("BATCH_SIZE", 5), "Records per backend request (env: BATCH_SIZE)")
+		sinks         = fs.String("sinks", getEnvOrDefault("OUTPUT_SINKS", ""), "Outputs to flush to, as for the monitor (env: OUTPUT_SINKS)")
+		webhookSecret = fs.String("webhook-secret", getEnvOrDefault("WEBHOOK_SECRET", ""), "HMAC secret for signing webhook sink requests (env: WEBHOOK_SECRET)")
+		configFile    = fs.String("config", getEnvOrDefault("SITEMAP_CONFIG", ""), "JSON config file supplying the backend and sinks (env: SITEMAP_CONFIG)")
+		timeout       = fs.Duration("timeout", 10*time.Minute, "Maximum time for flush")
+	)
+	backendSecurity := registerBackendSecurityFlags(fs)
+	fs.Usage = func() {
+		fmt.Println("USAGE:")
+		fmt.Println("    ./sitemap-go outbox list  [-data-dir DIR]")
+		fmt.Println("    ./sitemap-go outbox flush [-data-dir DIR] [-backend-url URL] [-backend-api-key KEY] [-sinks LIST] [-config FILE]")
+		fmt.Println("    ./sitemap-go outbox dead-letters [-data-dir DIR]")
+		fmt.Println("")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		fs.Usage()
+		return 2
+	}
+	action := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *configFile != "" {
+		fileConfig, err := monitor.LoadFileConfig(*configFile)
+		if err == nil {
+			err = applyFileConfig(fs, fileConfig)
+		}
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			return 1
+		}
+	}
+	if *encryptionKey == "" {
+		fmt.Println("ERROR: Encryption key is required to read the outbox.")
+		fmt.Println("Use -encryption-key flag or ENCRYPTION_KEY environment variable.")
+		return 1
+	}
+
+	storageService, err := storage.NewEncryptedFileStorage(storage.StorageConfig{
+		DataDir:     *dataDir,
+		EncryptData: true,
+	}, *encryptionKey)
+	if err != nil {
+		fmt.Printf("ERROR: failed to open data directory: %v\n", err)
+		return 1
+	}
+	outbox := storage.NewOutbox(storageService)
+
+	switch action {
+	case "list":
+		return listOutbox(outbox)
+	case "dead-letters":
+		return listDeadLetters(storage.NewDeadLetterStore(storageService))
+	case "flush":
+		sinkSpecs, err := backend.ParseSinkList(*sinks)
+		if err != nil {
+			fmt.Printf("ERROR: Invalid -sinks value: %v\n", err)
+			return 1
+		}
+		useBackend := len(sinkSpecs) == 0 || backend.HasSink(sinkSpecs, backend.SinkBackend)
+		if useBackend && (*backendURL == "" || (*backendAPIKey == "" && !backendSecurity.hasCredential())) {
+			fmt.Println("ERROR: flush needs -backend-url and -backend-api-key (or BACKEND_URL and BACKEND_API_KEY),")
+			fmt.Println("or -sinks naming outputs without the backend.")
+			return 1
+		}
+		backendTLS, backendAuth := backendSecurity.config()
+		backendConfig := backend.BackendConfig{
+			BaseURL:    *backendURL,
+			APIKey:     *backendAPIKey,
+			BatchSize:  *batchSize,
+			EnableGzip: true,
+			Timeout:    60 * time.Second,
+			TLS:        backendTLS,
+			Auth:       backendAuth,
+		}
+		var client backend.BackendClient
+		closeSinks := func() error { return nil }
+		if len(sinkSpecs) == 0 {
+			client, err = backend.NewBackendClient(backendConfig)
+		} else {
+			var fanOut *backend.FanOutClient
+			if fanOut, err = backend.NewFanOutClient(sinkSpecs, backend.SinkOptions{Backend: backendConfig, WebhookSecret: *webhookSecret}); err == nil {
+				client, closeSinks = fanOut, fanOut.Close
+			}
+		}
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			return 1
+		}
+		pool := backend.NewSubmissionPool(client, 1)
+		pool.SetOutbox(outbox)
+		pool.SetDeadLetters(storage.NewDeadLetterStore(storageService))
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		sent, pending, err := pool.FlushOutbox(ctx)
+		if closeErr := closeSinks(); err == nil {
+			err = closeErr
+		}
+		fmt.Printf("📮 Outbox flush: %d sent, %d still pending\n", sent, pending)
+		if rejected := pool.GetStats().RejectedItems; rejected > 0 {
+			fmt.Printf("☠️  %d records rejected by the backend, see: ./sitemap-go outbox dead-letters\n", rejected)
+		}
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			return 1
+		}
+		if pending > 0 {
+			return 1
+		}
+		return 0
+	default:
+		fmt.Printf("ERROR: unknown outbox action %q\n\n", action)
+		fs.Usage()
+		return 2
+	}
+}
+
+func listOutbox(outbox *storage.Outbox) int {
+	entries, err := outbox.List(context.Background())
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Println("📮 Outbox is empty")
+		return 0
+	}
+
+	records := 0
+	fmt.Printf("%-24s %-20s %-14s %7s %8s  %s\n", "ID", "CREATED", "KIND", "RECORDS", "ATTEMPTS", "LAST ERROR")
+	for _, entry := range entries {
+		records += entry.Items
+		kind := entry.Kind
+		if kind == "" {
+			kind = "metrics"
+		}
+		fmt.Printf("%-24s %-20s %-14s %7d %8d  %s\n", entry.ID, entry.CreatedAt.Format("2006-01-02 15:04:05"), kind, entry.Items, entry.Attempts, entry.LastError)
+	}
+	fmt.Printf("📮 %d submissions pending (%d records)\n", len(entries), records)
+	return 0
+}
+
+func listDeadLetters(deadLetters *storage.DeadLetterStore) int {
+	letters, err := deadLetters.List(context.Background())
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return 1
+	}
+	if len(letters) == 0 {
+		fmt.Println("☠️  No dead letters")
+		return 0
+	}
+
+	fmt.Printf("%-20s %-30s %5s  %-40s %s\n", "REJECTED", "KEYWORD", "TIMES", "REASON", "URL")
+	for _, letter := range letters {
+		fmt.Printf("%-20s %-30s %5d  %-40s %s\n", letter.RejectedAt.Format("2006-01-02 15:04:05"), letter.Keyword, letter.Rejections, letter.Reason, letter.URL)
+	}
+	fmt.Printf("☠️  %d records rejected by the backend\n", len(letters))
+	return 0
+}
@@ -3,13 +3,14 @@ package api
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
 	"sync/atomic"
 	"time"
 
-	"github.com/valyala/fasthttp"
 	"sitemap-go/pkg/logger"
 )
 
@@ -114,20 +115,6 @@ func (c *httpAPIClient) Query(ctx context.Context, keywords []string) (*APIRespo
 
 
 func (c *httpAPIClient) doQuery(ctx context.Context, keywords []string, result **APIResponse) error {
-	// Acquire concurrency permit if limiter is configured (inspired by 1.js)
-	if c.concurrencyLimiter != nil {
-		if err := c.concurrencyLimiter.Acquire(ctx); err != nil {
-			return fmt.Errorf("failed to acquire concurrency permit: %w", err)
-		}
-		defer c.concurrencyLimiter.Release() // Ensure permit is always released
-	}
-
-	// Create fasthttp request
-	req := fasthttp.AcquireRequest()
-	resp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(resp)
-
 	// Set request properties - use next URL from pool for load balancing
 	baseURL := c.urlPool.Next()
 	if baseURL == "" {
@@ -151,17 +138,8 @@ func (c *httpAPIClient) doQuery(ctx context.Context, keywords []string, result *
 		// User provided base URL - add the parameter
 		fullURL = baseURL + "?keyword=" + url.QueryEscape(keywordParam)
 	}
-	req.SetRequestURI(fullURL)
-	req.Header.SetMethod(fasthttp.MethodGet)
-	
-	// Set headers for API
-	req.Header.Set("User-Agent", "sitemap-go/1.0")
-	req.Header.Set("Accept", "application/json")
-	
-	// Set authorization header only if API key is provided
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
+	locale := LocaleFromContext(ctx)
+	fullURL = locale.appendQuery(fullURL)
 	
 	// Execute request using connection manager with configurable timeout
 	// Default to 80 seconds for SEOKey API as per user preference
@@ -171,37 +149,79 @@ func (c *httpAPIClient) doQuery(ctx context.Context, keywords []string, result *
 			timeout = parsedTimeout
 		}
 	}
-	err := c.connManager.GetFastHTTPClient().DoTimeout(req, resp, timeout)
+
+	// Acquire concurrency permit if limiter is configured (inspired by 1.js)
+	if c.concurrencyLimiter != nil {
+		if err := c.concurrencyLimiter.Acquire(ctx); err != nil {
+			return fmt.Errorf("failed to acquire concurrency permit: %w", err)
+		}
+		defer c.concurrencyLimiter.Release() // Ensure permit is always released
+	}
+	statusCode, body, err := c.execute(ctx, fullURL, timeout)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
 	
 	// Check status code with environment-aware error handling
-	if resp.StatusCode() != fasthttp.StatusOK {
+	if statusCode != http.StatusOK {
 		// In development, provide more detailed error information
 		if os.Getenv("DEBUG") == "true" || os.Getenv("ENVIRONMENT") == "development" {
 			// Safely truncate response body for debugging (max 200 chars)
-			respBody := string(resp.Body())
+			respBody := string(body)
 			if len(respBody) > 200 {
 				respBody = respBody[:200] + "..."
 			}
-			return fmt.Errorf("API returned status %d: %s", resp.StatusCode(), respBody)
+			return fmt.Errorf("API returned status %d: %s", statusCode, respBody)
 		}
 		// In production, hide response body for security
-		return fmt.Errorf("API returned status %d (response body hidden for security)", resp.StatusCode())
+		return fmt.Errorf("API returned status %d (response body hidden for security)", statusCode)
 	}
 	
 	// Use unified SEOKey parser for consistent response handling
 	parser := NewSEOKeyParser()
-	apiResp, err := parser.ParseResponse(resp.Body())
+	apiResp, err := parser.ParseResponse(body)
 	if err != nil {
 		return fmt.Errorf("failed to parse SEOKey response: %w", err)
 	}
+	apiResp.setLocale(locale)
 
 	*result = apiResp
 	return nil
 }
 
+// execute performs the GET request and returns status and body
+// It uses the pooled net/http client because fasthttp requests cannot be cancelled: the
+// request ends with ctx, so a cancelled one (e.g. a hedge that lost) closes its connection
+// at once instead of holding it, and the caller's permit, until the timeout
+func (c *httpAPIClient) execute(ctx context.Context, fullURL string, timeout time.Duration) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Set headers for API
+	req.Header.Set("User-Agent", "sitemap-go/1.0")
+	req.Header.Set("Accept", "application/json")
+
+	// Set authorization header only if API key is provided
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.connManager.GetClient().Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
+
 // SetConcurrencyLimiter sets the concurrency limiter for this client
 // Allows dynamic configuration of concurrency control
 // Implements ConcurrencyConfigurable interface
@@ -131,6 +131,7 @@ func (c *EnhancedHTTPAPIClient) doQueryWithHealthTracking(ctx context.Context, k
 	} else {
 		fullURL = baseURL + "?keyword=" + url.QueryEscape(keywordParam)
 	}
+	fullURL = LocaleFromContext(ctx).appendQuery(fullURL)
 
 	req.SetRequestURI(fullURL)
 	req.Header.SetMethod(fasthttp.MethodGet)
@@ -170,7 +171,11 @@ func (c *EnhancedHTTPAPIClient) doQueryWithHealthTracking(ctx context.Context, k
 	}
 
 	// Parse response (reuse existing logic)
-	return c.parseResponse(resp.Body(), result)
+	if err := c.parseResponse(resp.Body(), result); err != nil {
+		return err
+	}
+	(*result).setLocale(LocaleFromContext(ctx))
+	return nil
 }
 
 // parseResponse handles API response parsing using unified SEOKey parser
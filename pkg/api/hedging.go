@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HedgeOptions configures request hedging for WeightedAPIClient
+// When a batch has not answered within the observed latency percentile, the same batch
+// is sent to another healthy endpoint and the first successful answer wins
+type HedgeOptions struct {
+	Enabled     bool          `json:"enabled"`
+	Percentile  float64       `json:"percentile"`   // Latency percentile that triggers a hedge (0-1)
+	MinDelay    time.Duration `json:"min_delay"`    // Never hedge sooner than this
+	MaxDelay    time.Duration `json:"max_delay"`    // Delay used until enough samples exist, and the upper bound
+	MinSamples  int           `json:"min_samples"`  // Samples required before the percentile is trusted
+	BudgetRatio float64       `json:"budget_ratio"` // Max hedges as a fraction of primary requests
+	BudgetBurst int           `json:"budget_burst"` // Hedges allowed before the ratio applies
+}
+
+// DefaultHedgeOptions returns conservative hedging settings (disabled by default)
+func DefaultHedgeOptions() HedgeOptions {
+	return HedgeOptions{
+		Enabled:     false,
+		Percentile:  0.95,
+		MinDelay:    2 * time.Second,
+		MaxDelay:    20 * time.Second,
+		MinSamples:  20,
+		BudgetRatio: 0.1,
+		BudgetBurst: 2,
+	}
+}
+
+// HedgeStats reports hedging activity
+type HedgeStats struct {
+	PrimaryRequests uint64 `json:"primary_requests"`
+	HedgesSent      uint64 `json:"hedges_sent"`
+	HedgesWon       uint64 `json:"hedges_won"`
+	HedgesDenied    uint64 `json:"hedges_denied"` // Skipped because the budget was spent
+}
+
+// latencyWindow keeps the most recent successful latencies for percentile estimates
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+	full    bool
+	mu      sync.Mutex
+}
+
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{samples: make([]time.Duration, size)}
+}
+
+func (lw *latencyWindow) add(latency time.Duration) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.samples[lw.next] = latency
+	lw.next = (lw.next + 1) % len(lw.samples)
+	if lw.next == 0 {
+		lw.full = true
+	}
+}
+
+// percentile returns the p-th latency and the number of samples it was computed from
+func (lw *latencyWindow) percentile(p float64) (time.Duration, int) {
+	lw.mu.Lock()
+	count := lw.next
+	if lw.full {
+		count = len(lw.samples)
+	}
+	sorted := append([]time.Duration(nil), lw.samples[:count]...)
+	lw.mu.Unlock()
+
+	if count == 0 {
+		return 0, 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(p * float64(count-1))
+	return sorted[index], count
+}
+
+// hedger tracks latency and the hedge budget for one client
+type hedger struct {
+	options   HedgeOptions
+	latencies *latencyWindow
+
+	mu    sync.Mutex
+	stats HedgeStats
+}
+
+func newHedger(options HedgeOptions) *hedger {
+	defaults := DefaultHedgeOptions()
+	if options.Percentile <= 0 || options.Percentile >= 1 {
+		options.Percentile = defaults.Percentile
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = defaults.MaxDelay
+	}
+	if options.MinSamples <= 0 {
+		options.MinSamples = defaults.MinSamples
+	}
+	if options.BudgetRatio <= 0 {
+		options.BudgetRatio = defaults.BudgetRatio
+	}
+	return &hedger{
+		options:   options,
+		latencies: newLatencyWindow(200),
+	}
+}
+
+// delay returns how long to wait for the primary before hedging
+func (h *hedger) delay() time.Duration {
+	observed, samples := h.latencies.percentile(h.options.Percentile)
+	if samples < h.options.MinSamples {
+		return h.options.MaxDelay
+	}
+	if observed < h.options.MinDelay {
+		return h.options.MinDelay
+	}
+	if observed > h.options.MaxDelay {
+		return h.options.MaxDelay
+	}
+	return observed
+}
+
+func (h *hedger) recordPrimary() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stats.PrimaryRequests++
+}
+
+// tryAcquire spends one hedge from the budget if available
+func (h *hedger) tryAcquire() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	allowed := h.options.BudgetRatio*float64(h.stats.PrimaryRequests) + float64(h.options.BudgetBurst)
+	if float64(h.stats.HedgesSent+1) > allowed {
+		h.stats.HedgesDenied++
+		return false
+	}
+	h.stats.HedgesSent++
+	return true
+}
+
+func (h *hedger) recordWin() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stats.HedgesWon++
+}
+
+func (h *hedger) snapshot() HedgeStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.stats
+}
+
+// attempt is the outcome of one endpoint call in a hedged query
+type attempt struct {
+	endpoint *weightedEndpoint
+	resp     *APIResponse
+	err      error
+	hedge    bool
+}
+
+// hedgedQuery races the primary endpoint against a delayed hedge on another endpoint
+// The loser is cancelled through its context; tried records every endpoint that was used
+func (w *WeightedAPIClient) hedgedQuery(ctx context.Context, primary *weightedEndpoint, keywords []string, tried map[*weightedEndpoint]bool) (*APIResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Cancels whichever request is still running
+
+	results := make(chan attempt, 2)
+	launch := func(endpoint *weightedEndpoint, hedge bool) {
+		go func() {
+			resp, err := w.queryEndpoint(ctx, endpoint, keywords)
+			results <- attempt{endpoint: endpoint, resp: resp, err: err, hedge: hedge}
+		}()
+	}
+
+	start := time.Now()
+	w.hedger.recordPrimary()
+	launch(primary, false)
+	pending := 1
+	primaryPending := true
+
+	timer := time.NewTimer(w.hedger.delay())
+	defer timer.Stop()
+
+	var lastErr error
+	for pending > 0 {
+		select {
+		case result := <-results:
+			pending--
+			if !result.hedge {
+				primaryPending = false
+			}
+			if result.err == nil {
+				if result.hedge {
+					w.hedger.recordWin()
+				}
+				if !result.hedge || primaryPending {
+					// A hedge win still samples the primary's elapsed time, a lower bound on its
+					// latency, so a slow endpoint keeps raising the hedge delay
+					w.hedger.latencies.add(time.Since(start))
+				}
+				return result.resp, nil
+			}
+			lastErr = result.err
+		case <-timer.C:
+			hedgeEndpoint := w.selectEndpoint(tried)
+			if hedgeEndpoint == nil || !w.hedger.tryAcquire() {
+				continue
+			}
+			tried[hedgeEndpoint] = true
+			w.log.WithField("endpoint", w.secureLog.MaskAPIEndpoint(hedgeEndpoint.config.URL)).Debug("Sending hedged API request")
+			launch(hedgeEndpoint, true)
+			pending++
+		}
+	}
+	return nil, lastErr
+}
@@ -15,6 +15,44 @@ type Keyword struct {
 	SearchVolume int     `json:"search_volume"`
 	Competition  float64 `json:"competition"`
 	CPC          float64 `json:"cpc"`
+
+	// Provider-reported details, carried through unchanged to the backend
+	LatestSearches        int             `json:"latest_searches"`
+	CompetitionLevel      string          `json:"competition_level,omitempty"`
+	CompetitionIndex      int             `json:"competition_index"`
+	LowTopOfPageBidMicro  int64           `json:"low_top_of_page_bid_micro"`
+	HighTopOfPageBidMicro int64           `json:"high_top_of_page_bid_micro"`
+	MonthlySearches       []MonthlySearch `json:"monthly_searches,omitempty"`
+	Locale                string          `json:"locale,omitempty"` // Locale the metrics were queried for, "" = untargeted
+
+	// MissingFields lists provider fields that were absent from the response
+	// (see the Missing* constants) so consumers never mistake omitted data for zeros
+	MissingFields []string `json:"missing_fields,omitempty"`
+}
+
+// MonthlySearch is a single month of provider-reported search volume
+type MonthlySearch struct {
+	Year     int   `json:"year"`
+	Month    int   `json:"month"` // 1-12
+	Searches int64 `json:"searches"`
+}
+
+// Missing field markers reported in Keyword.MissingFields
+const (
+	MissingMonthlySearches  = "monthly_searches"
+	MissingLatestSearches   = "latest_searches"
+	MissingCompetitionIndex = "competition_index"
+	MissingTopOfPageBids    = "top_of_page_bids"
+)
+
+// HasMissing reports whether the provider omitted the given field
+func (k Keyword) HasMissing(field string) bool {
+	for _, missing := range k.MissingFields {
+		if missing == field {
+			return true
+		}
+	}
+	return false
 }
 
 // APIClient interface for Google Trends API
@@ -25,4 +63,10 @@ type APIClient interface {
 // ConcurrencyConfigurable interface for clients that support concurrency control
 type ConcurrencyConfigurable interface {
 	SetConcurrencyLimiter(limiter ConcurrencyLimiter)
-}
\ No newline at end of file
+}
+
+// SelfRateLimited is implemented by clients that pace requests per endpoint internally
+// Callers should skip their own rate limiting when HandlesRateLimiting returns true
+type SelfRateLimited interface {
+	HandlesRateLimiting() bool
+}
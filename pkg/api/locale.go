@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Locale selects the language and geo target for keyword metric queries
+// The zero value means no targeting (provider default, usually global English)
+type Locale struct {
+	Language string `json:"language"`          // ISO 639-1, lower case (e.g. "de")
+	Country  string `json:"country,omitempty"` // ISO 3166-1 alpha-2, upper case (e.g. "DE"); empty = all countries
+}
+
+// ParseLocale parses "de", "de-DE", "de_DE" or "pt-br" into a Locale
+func ParseLocale(raw string) (Locale, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Locale{}, nil
+	}
+	parts := strings.FieldsFunc(raw, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 || len(parts) > 2 || !isLetters(parts[0], 2, 3) {
+		return Locale{}, fmt.Errorf("invalid locale %q (expected language or language-COUNTRY)", raw)
+	}
+	locale := Locale{Language: strings.ToLower(parts[0])}
+	if len(parts) == 2 {
+		if !isLetters(parts[1], 2, 2) {
+			return Locale{}, fmt.Errorf("invalid country in locale %q", raw)
+		}
+		locale.Country = strings.ToUpper(parts[1])
+	}
+	return locale, nil
+}
+
+// String returns the BCP 47 style tag ("de-DE", "de") or "" for the zero Locale
+func (l Locale) String() string {
+	if l.Country == "" {
+		return l.Language
+	}
+	return l.Language + "-" + l.Country
+}
+
+// IsZero reports whether no locale is set
+func (l Locale) IsZero() bool {
+	return l.Language == "" && l.Country == ""
+}
+
+// appendQuery adds language/country parameters to a request URL
+func (l Locale) appendQuery(fullURL string) string {
+	if l.IsZero() {
+		return fullURL
+	}
+	params := url.Values{}
+	if l.Language != "" {
+		params.Set("language", l.Language)
+	}
+	if l.Country != "" {
+		params.Set("country", l.Country)
+	}
+	separator := "?"
+	if strings.Contains(fullURL, "?") {
+		separator = "&"
+	}
+	return fullURL + separator + params.Encode()
+}
+
+func isLetters(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+type localeContextKey struct{}
+
+// WithLocale returns a context whose API queries target the given locale
+// The locale travels through the context so every APIClient wrapper passes it on unchanged
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	if locale.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// LocaleFromContext returns the locale set by WithLocale, or the zero Locale
+func LocaleFromContext(ctx context.Context) Locale {
+	locale, _ := ctx.Value(localeContextKey{}).(Locale)
+	return locale
+}
+
+// localeKeySeparator never appears in keywords formatted from URL slugs
+const localeKeySeparator = "@"
+
+// LocaleKey identifies a keyword or page in a locale: it is the query key used for deduplication,
+// the keyword-volume cache, the pending and failed keyword stores and the processed-URL cache
+// Keywords without a locale keep their plain form so existing stored data stays valid
+func LocaleKey(keyword string, locale Locale) string {
+	if locale.IsZero() {
+		return keyword
+	}
+	return keyword + localeKeySeparator + locale.String()
+}
+
+// SplitLocaleKey reverses LocaleKey
+func SplitLocaleKey(key string) (string, Locale) {
+	index := strings.LastIndex(key, localeKeySeparator)
+	if index < 0 {
+		return key, Locale{}
+	}
+	locale, err := ParseLocale(key[index+1:])
+	if err != nil {
+		return key, Locale{}
+	}
+	return key[:index], locale
+}
+
+// GroupByLocale splits locale keys into plain keywords per locale, preserving order
+// Each group can be sent as one query with WithLocale
+func GroupByLocale(keys []string) ([]Locale, map[Locale][]string) {
+	var order []Locale
+	groups := make(map[Locale][]string)
+	for _, key := range keys {
+		keyword, locale := SplitLocaleKey(key)
+		if _, exists := groups[locale]; !exists {
+			order = append(order, locale)
+		}
+		groups[locale] = append(groups[locale], keyword)
+	}
+	return order, groups
+}
+
+// setLocale stamps the queried locale on every returned keyword
+func (r *APIResponse) setLocale(locale Locale) {
+	if r == nil || locale.IsZero() {
+		return
+	}
+	for i := range r.Keywords {
+		r.Keywords[i].Locale = locale.String()
+	}
+}
+
+// LocaleKey returns the keyword's identity including the locale it was queried for
+func (k Keyword) LocaleKey() string {
+	locale, _ := ParseLocale(k.Locale)
+	return LocaleKey(k.Word, locale)
+}
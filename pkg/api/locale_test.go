@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseLocaleAndKeys(t *testing.T) {
+	locale, err := ParseLocale("pt_br")
+	if err != nil || locale.Language != "pt" || locale.Country != "BR" || locale.String() != "pt-BR" {
+		t.Fatalf("Unexpected locale %+v (err %v)", locale, err)
+	}
+	for _, invalid := range []string{"german", "de-DEU", "d3"} {
+		if _, err := ParseLocale(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+
+	key := LocaleKey("subway surfers", locale)
+	if key != "subway surfers@pt-BR" {
+		t.Errorf("Unexpected key %q", key)
+	}
+	if word, parsed := SplitLocaleKey(key); word != "subway surfers" || parsed != locale {
+		t.Errorf("Round trip failed: %q %+v", word, parsed)
+	}
+	if LocaleKey("plain", Locale{}) != "plain" {
+		t.Error("Keys without a locale must stay unchanged")
+	}
+
+	order, groups := GroupByLocale([]string{"a@de", "b", "c@de"})
+	if len(order) != 2 || len(groups[Locale{Language: "de"}]) != 2 || groups[Locale{}][0] != "b" {
+		t.Errorf("Unexpected grouping: %v %v", order, groups)
+	}
+}
+
+func TestHTTPAPIClientSendsLocale(t *testing.T) {
+	var gotLanguage, gotCountry string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLanguage = r.URL.Query().Get("language")
+		gotCountry = r.URL.Query().Get("country")
+		w.Write([]byte(`{"status":"success","data":[{"keyword":"test","metrics":{"avg_monthly_searches":10}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPAPIClient(server.URL, "")
+	ctx := WithLocale(context.Background(), Locale{Language: "de", Country: "DE"})
+	resp, err := client.Query(ctx, []string{"test"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if gotLanguage != "de" || gotCountry != "DE" {
+		t.Errorf("Expected language=de country=DE, got %q %q", gotLanguage, gotCountry)
+	}
+	if resp.Keywords[0].Locale != "de-DE" || resp.Keywords[0].LocaleKey() != "test@de-DE" {
+		t.Errorf("Expected keyword stamped with locale, got %+v", resp.Keywords[0])
+	}
+}
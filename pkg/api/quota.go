@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrQuotaExhausted signals that the provider budget for an endpoint is spent
+// Callers should stop querying and defer remaining keywords instead of treating them as failures
+var ErrQuotaExhausted = errors.New("API quota exhausted")
+
+// QuotaGuard accounts API spend per endpoint
+// Reserve must return an error wrapping ErrQuotaExhausted once the budget is spent
+type QuotaGuard interface {
+	Available(ctx context.Context, endpoint string) bool
+	Reserve(ctx context.Context, endpoint string) error
+}
+
+// quotaLimitedClient reserves budget before every query
+type quotaLimitedClient struct {
+	client   APIClient
+	guard    QuotaGuard
+	endpoint string
+}
+
+// NewQuotaLimitedClient wraps a client so each Query is charged to endpoint's quota
+func NewQuotaLimitedClient(client APIClient, guard QuotaGuard, endpoint string) APIClient {
+	return &quotaLimitedClient{client: client, guard: guard, endpoint: endpoint}
+}
+
+func (q *quotaLimitedClient) Query(ctx context.Context, keywords []string) (*APIResponse, error) {
+	if err := q.guard.Reserve(ctx, q.endpoint); err != nil {
+		return nil, err
+	}
+	return q.client.Query(ctx, keywords)
+}
+
+// SetConcurrencyLimiter forwards to the wrapped client when supported
+func (q *quotaLimitedClient) SetConcurrencyLimiter(limiter ConcurrencyLimiter) {
+	if configurable, ok := q.client.(ConcurrencyConfigurable); ok {
+		configurable.SetConcurrencyLimiter(limiter)
+	}
+}
+
+// HandlesRateLimiting forwards to the wrapped client when supported
+func (q *quotaLimitedClient) HandlesRateLimiting() bool {
+	if limited, ok := q.client.(SelfRateLimited); ok {
+		return limited.HandlesRateLimiting()
+	}
+	return false
+}
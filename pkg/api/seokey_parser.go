@@ -3,6 +3,10 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // SEOKeyResponse represents the raw response structure from SEOKey API
@@ -10,15 +14,60 @@ import (
 type SEOKeyResponse struct {
 	Status string `json:"status"`
 	Data   []struct {
-		Keyword string `json:"keyword"`
-		Metrics struct {
-			AvgMonthlySearches int    `json:"avg_monthly_searches"`
-			Competition        string `json:"competition"`
-			LatestSearches     int    `json:"latest_searches"`
-		} `json:"metrics"`
+		Keyword string        `json:"keyword"`
+		Metrics SEOKeyMetrics `json:"metrics"`
 	} `json:"data"`
 }
 
+// SEOKeyMetrics holds the per-keyword metrics block of a SEOKey response
+// Optional fields are pointers so an omitted value can be told apart from zero
+type SEOKeyMetrics struct {
+	AvgMonthlySearches    int                   `json:"avg_monthly_searches"`
+	Competition           string                `json:"competition"`
+	LatestSearches        *int                  `json:"latest_searches"`
+	CompetitionIndex      *int                  `json:"competition_index"`
+	LowTopOfPageBidMicro  *int64                `json:"low_top_of_page_bid_micro"`
+	HighTopOfPageBidMicro *int64                `json:"high_top_of_page_bid_micro"`
+	MonthlySearches       []SEOKeyMonthlySearch `json:"monthly_searches"`
+}
+
+// SEOKeyMonthlySearch is one entry of the monthly_searches array
+// Year and month arrive as numbers, numeric strings or month names depending on the upstream source
+type SEOKeyMonthlySearch struct {
+	Year     flexibleInt `json:"year"`
+	Month    flexibleInt `json:"month"`
+	Searches *int64      `json:"searches"`
+}
+
+// flexibleInt decodes JSON numbers, numeric strings and English month names ("JANUARY", "Jan")
+type flexibleInt int
+
+func (f *flexibleInt) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*f = flexibleInt(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("expected number or string, got %s", string(data))
+	}
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		*f = flexibleInt(n)
+		return nil
+	}
+	for month := time.January; month <= time.December; month++ {
+		name := month.String()
+		if strings.EqualFold(s, name) || strings.EqualFold(s, name[:3]) {
+			*f = flexibleInt(month)
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognised month or year value: %q", s)
+}
+
 // SEOKeyParser provides unified parsing logic for SEOKey API responses
 // Follows DRY principle by centralizing duplicate parsing logic
 type SEOKeyParser struct{}
@@ -69,18 +118,88 @@ func (p *SEOKeyParser) ParseResponse(body []byte) (*APIResponse, error) {
 			continue // Skip empty keywords
 		}
 
-		keyword := Keyword{
-			Word:         data.Keyword,
-			SearchVolume: data.Metrics.AvgMonthlySearches,
-			Competition:  p.mapCompetitionValue(data.Metrics.Competition),
-			CPC:          0, // SEOKey API doesn't provide CPC data
-		}
-		apiResp.Keywords = append(apiResp.Keywords, keyword)
+		apiResp.Keywords = append(apiResp.Keywords, p.convertKeyword(data.Keyword, data.Metrics))
 	}
 
 	return &apiResp, nil
 }
 
+// convertKeyword maps a raw SEOKey metrics block onto Keyword, recording omitted fields
+// instead of filling them with made-up values
+func (p *SEOKeyParser) convertKeyword(word string, metrics SEOKeyMetrics) Keyword {
+	keyword := Keyword{
+		Word:             word,
+		SearchVolume:     metrics.AvgMonthlySearches,
+		Competition:      p.mapCompetitionValue(metrics.Competition),
+		CompetitionLevel: strings.ToUpper(strings.TrimSpace(metrics.Competition)),
+		MonthlySearches:  p.convertMonthlySearches(metrics.MonthlySearches),
+	}
+
+	if len(keyword.MonthlySearches) == 0 {
+		keyword.MissingFields = append(keyword.MissingFields, MissingMonthlySearches)
+	}
+
+	switch {
+	case metrics.LatestSearches != nil:
+		keyword.LatestSearches = *metrics.LatestSearches
+	case len(keyword.MonthlySearches) > 0:
+		keyword.LatestSearches = int(keyword.MonthlySearches[len(keyword.MonthlySearches)-1].Searches)
+	default:
+		// Left at 0 rather than borrowing the average, which would pass it off as provider data
+		keyword.MissingFields = append(keyword.MissingFields, MissingLatestSearches)
+	}
+
+	if metrics.CompetitionIndex != nil {
+		keyword.CompetitionIndex = *metrics.CompetitionIndex
+	} else {
+		keyword.MissingFields = append(keyword.MissingFields, MissingCompetitionIndex)
+	}
+
+	if metrics.LowTopOfPageBidMicro != nil || metrics.HighTopOfPageBidMicro != nil {
+		if metrics.LowTopOfPageBidMicro != nil {
+			keyword.LowTopOfPageBidMicro = *metrics.LowTopOfPageBidMicro
+		}
+		if metrics.HighTopOfPageBidMicro != nil {
+			keyword.HighTopOfPageBidMicro = *metrics.HighTopOfPageBidMicro
+		}
+		// CPC is the bid range midpoint in currency units
+		keyword.CPC = float64(keyword.LowTopOfPageBidMicro+keyword.HighTopOfPageBidMicro) / 2 / 1000000
+	} else {
+		keyword.MissingFields = append(keyword.MissingFields, MissingTopOfPageBids)
+	}
+
+	return keyword
+}
+
+// convertMonthlySearches validates and sorts the monthly series chronologically
+// Entries with an invalid date or no searches value are dropped rather than guessed
+func (p *SEOKeyParser) convertMonthlySearches(raw []SEOKeyMonthlySearch) []MonthlySearch {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	series := make([]MonthlySearch, 0, len(raw))
+	for _, entry := range raw {
+		if entry.Searches == nil || entry.Year <= 0 || entry.Month < 1 || entry.Month > 12 {
+			continue
+		}
+		series = append(series, MonthlySearch{
+			Year:     int(entry.Year),
+			Month:    int(entry.Month),
+			Searches: *entry.Searches,
+		})
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].Year != series[j].Year {
+			return series[i].Year < series[j].Year
+		}
+		return series[i].Month < series[j].Month
+	})
+
+	return series
+}
+
 // min returns the minimum of two integers (helper function)
 func min(a, b int) int {
 	if a < b {
@@ -141,3 +141,74 @@ func TestSEOKeyParser_MapCompetitionValue(t *testing.T) {
 		}
 	}
 }
+
+func TestSEOKeyParser_ParseResponse_MonthlySearches(t *testing.T) {
+	parser := NewSEOKeyParser()
+
+	responseBody := `{
+		"status": "success",
+		"data": [
+			{
+				"keyword": "full data",
+				"metrics": {
+					"avg_monthly_searches": 1200,
+					"competition": "HIGH",
+					"competition_index": 87,
+					"latest_searches": 1500,
+					"low_top_of_page_bid_micro": 200000,
+					"high_top_of_page_bid_micro": 600000,
+					"monthly_searches": [
+						{"year": "2024", "month": "FEBRUARY", "searches": 1100},
+						{"year": 2024, "month": 1, "searches": 900},
+						{"year": 2024, "month": "3", "searches": 1500}
+					]
+				}
+			},
+			{
+				"keyword": "sparse data",
+				"metrics": {
+					"avg_monthly_searches": 50,
+					"competition": "LOW"
+				}
+			}
+		]
+	}`
+
+	result, err := parser.ParseResponse([]byte(responseBody))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Keywords) != 2 {
+		t.Fatalf("Expected 2 keywords, got: %d", len(result.Keywords))
+	}
+
+	full := result.Keywords[0]
+	if len(full.MonthlySearches) != 3 {
+		t.Fatalf("Expected 3 monthly entries, got: %d", len(full.MonthlySearches))
+	}
+	if full.MonthlySearches[0].Month != 1 || full.MonthlySearches[2].Month != 3 {
+		t.Errorf("Expected series sorted Jan..Mar, got: %+v", full.MonthlySearches)
+	}
+	if full.CompetitionIndex != 87 {
+		t.Errorf("Expected competition index 87, got: %d", full.CompetitionIndex)
+	}
+	if full.LowTopOfPageBidMicro != 200000 || full.HighTopOfPageBidMicro != 600000 {
+		t.Errorf("Unexpected bids: %d-%d", full.LowTopOfPageBidMicro, full.HighTopOfPageBidMicro)
+	}
+	if full.CPC != 0.4 {
+		t.Errorf("Expected CPC 0.4, got: %f", full.CPC)
+	}
+	if len(full.MissingFields) != 0 {
+		t.Errorf("Expected no missing fields, got: %v", full.MissingFields)
+	}
+
+	sparse := result.Keywords[1]
+	for _, field := range []string{MissingMonthlySearches, MissingLatestSearches, MissingCompetitionIndex, MissingTopOfPageBids} {
+		if !sparse.HasMissing(field) {
+			t.Errorf("Expected %s to be marked missing, got: %v", field, sparse.MissingFields)
+		}
+	}
+	if sparse.LatestSearches != 0 {
+		t.Errorf("Expected omitted latest searches to stay 0, got: %d", sparse.LatestSearches)
+	}
+}
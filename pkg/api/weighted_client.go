@@ -0,0 +1,454 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sitemap-go/pkg/logger"
+)
+
+// Load balancing strategies for WeightedAPIClient
+const (
+	StrategyWeighted    = "weighted"     // Smooth weighted round-robin
+	StrategyLeastLoaded = "least-loaded" // Fewest in-flight requests relative to weight
+)
+
+// EndpointConfig describes one keyword API endpoint and its quota
+// Zero values mean "use the monitor defaults" for rate and concurrency
+type EndpointConfig struct {
+	URL               string  `json:"url"`
+	Weight            int     `json:"weight"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	MaxConcurrent     int     `json:"max_concurrent"`
+	DailyQuota        int64   `json:"daily_quota"`   // Requests per UTC day, 0 = monitor default
+	MonthlyQuota      int64   `json:"monthly_quota"` // Requests per UTC month, 0 = monitor default
+}
+
+// RequestRateLimiter paces calls to a single endpoint
+// Satisfied by monitor.RateLimitedExecutor
+type RequestRateLimiter interface {
+	Execute(ctx context.Context, fn func() error) error
+}
+
+// ParseEndpointList parses a comma-separated endpoint list with optional per-endpoint settings:
+//
+//	https://a.example.com/api;weight=3;rps=2.5;concurrency=10;daily=5000,https://b.example.com/api
+func ParseEndpointList(raw string) ([]EndpointConfig, error) {
+	var endpoints []EndpointConfig
+	for i, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ";")
+		endpoint := EndpointConfig{URL: strings.TrimSpace(parts[0]), Weight: 1}
+		if endpoint.URL == "" {
+			return nil, fmt.Errorf("endpoint #%d has no URL", i+1)
+		}
+
+		for _, option := range parts[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(option), "=")
+			if !found {
+				return nil, fmt.Errorf("endpoint #%d: option %q must be key=value", i+1, option)
+			}
+			var err error
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "weight", "w":
+				endpoint.Weight, err = strconv.Atoi(value)
+				if err == nil && endpoint.Weight <= 0 {
+					err = fmt.Errorf("must be positive")
+				}
+			case "rps", "rate":
+				endpoint.RequestsPerSecond, err = strconv.ParseFloat(value, 64)
+				if err == nil && endpoint.RequestsPerSecond <= 0 {
+					err = fmt.Errorf("must be positive")
+				}
+			case "concurrency", "max_concurrent":
+				endpoint.MaxConcurrent, err = strconv.Atoi(value)
+				if err == nil && endpoint.MaxConcurrent <= 0 {
+					err = fmt.Errorf("must be positive")
+				}
+			case "daily":
+				endpoint.DailyQuota, err = strconv.ParseInt(value, 10, 64)
+				if err == nil && endpoint.DailyQuota <= 0 {
+					err = fmt.Errorf("must be positive")
+				}
+			case "monthly":
+				endpoint.MonthlyQuota, err = strconv.ParseInt(value, 10, 64)
+				if err == nil && endpoint.MonthlyQuota <= 0 {
+					err = fmt.Errorf("must be positive")
+				}
+			default:
+				err = fmt.Errorf("unknown option")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("endpoint #%d: invalid %s=%s: %v", i+1, key, value, err)
+			}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no API endpoints configured")
+	}
+	return endpoints, nil
+}
+
+// WeightedClientOptions tunes selection and health handling
+type WeightedClientOptions struct {
+	Strategy           string        // StrategyWeighted or StrategyLeastLoaded
+	FailureThreshold   int           // Consecutive failures before an endpoint is excluded
+	Cooldown           time.Duration // How long an excluded endpoint sits out
+	RetriesPerEndpoint int           // In-place retries before failing over
+	Hedge              HedgeOptions  // Optional request hedging across endpoints
+}
+
+// DefaultWeightedClientOptions returns options matching DualAPIClient's health behaviour
+func DefaultWeightedClientOptions() WeightedClientOptions {
+	return WeightedClientOptions{
+		Strategy:           StrategyWeighted,
+		FailureThreshold:   3,
+		Cooldown:           30 * time.Second,
+		RetriesPerEndpoint: 1,
+		Hedge:              DefaultHedgeOptions(),
+	}
+}
+
+// weightedEndpoint is the runtime state of one endpoint
+type weightedEndpoint struct {
+	config      EndpointConfig
+	client      APIClient
+	rateLimiter RequestRateLimiter
+
+	inFlight      int64
+	totalRequests uint64
+	failures      uint64
+	totalLatency  uint64 // milliseconds
+
+	// Guarded by WeightedAPIClient.mu
+	currentWeight       int
+	consecutiveFailures int
+	excludedUntil       time.Time
+}
+
+// EndpointStats is a snapshot of one endpoint's load and health
+type EndpointStats struct {
+	URL           string        `json:"url"`
+	Weight        int           `json:"weight"`
+	InFlight      int64         `json:"in_flight"`
+	TotalRequests uint64        `json:"total_requests"`
+	Failures      uint64        `json:"failures"`
+	AvgLatency    time.Duration `json:"avg_latency"`
+	Healthy       bool          `json:"healthy"`
+}
+
+// WeightedAPIClient load balances keyword queries across N endpoints
+// Each endpoint has its own weight, rate limiter and concurrency limiter; endpoints
+// that keep failing are excluded for a cooldown and traffic fails over to the rest
+type WeightedAPIClient struct {
+	endpoints []*weightedEndpoint
+	options   WeightedClientOptions
+	hedger    *hedger    // nil when hedging is disabled
+	quota     QuotaGuard // nil when quota accounting is disabled
+	mu        sync.Mutex
+	log       *logger.Logger
+	secureLog *logger.SecurityLogger
+}
+
+// NewWeightedAPIClient creates a client over the given endpoints
+func NewWeightedAPIClient(endpoints []EndpointConfig, options WeightedClientOptions) (*WeightedAPIClient, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("at least one API endpoint is required")
+	}
+	if options.Strategy == "" {
+		options.Strategy = StrategyWeighted
+	}
+	if options.Strategy != StrategyWeighted && options.Strategy != StrategyLeastLoaded {
+		return nil, fmt.Errorf("unknown load balancing strategy: %s", options.Strategy)
+	}
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = 3
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = 30 * time.Second
+	}
+
+	client := &WeightedAPIClient{
+		options:   options,
+		log:       logger.GetLogger().WithField("component", "weighted_api_client"),
+		secureLog: logger.GetSecurityLogger(),
+	}
+	if options.Hedge.Enabled && len(endpoints) > 1 {
+		client.hedger = newHedger(options.Hedge)
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Weight <= 0 {
+			endpoint.Weight = 1
+		}
+		client.endpoints = append(client.endpoints, &weightedEndpoint{
+			config: endpoint,
+			client: NewHTTPAPIClientWithRetry(endpoint.URL, "", HighThroughputConnectionConfig(), options.RetriesPerEndpoint, 1*time.Second),
+		})
+	}
+	return client, nil
+}
+
+// Endpoints returns the configured endpoints
+func (w *WeightedAPIClient) Endpoints() []EndpointConfig {
+	configs := make([]EndpointConfig, len(w.endpoints))
+	for i, endpoint := range w.endpoints {
+		configs[i] = endpoint.config
+	}
+	return configs
+}
+
+// SetEndpointLimiters attaches rate and concurrency limiters to the endpoint with the given URL
+// Either limiter may be nil
+func (w *WeightedAPIClient) SetEndpointLimiters(url string, rateLimiter RequestRateLimiter, concurrencyLimiter ConcurrencyLimiter) {
+	for _, endpoint := range w.endpoints {
+		if endpoint.config.URL != url {
+			continue
+		}
+		endpoint.rateLimiter = rateLimiter
+		if concurrencyLimiter != nil {
+			if configurable, ok := endpoint.client.(ConcurrencyConfigurable); ok {
+				configurable.SetConcurrencyLimiter(concurrencyLimiter)
+			}
+		}
+	}
+}
+
+// SetQuotaGuard charges every endpoint request to guard and skips endpoints whose budget is spent
+func (w *WeightedAPIClient) SetQuotaGuard(guard QuotaGuard) {
+	w.quota = guard
+	for _, endpoint := range w.endpoints {
+		endpoint.client = NewQuotaLimitedClient(endpoint.client, guard, endpoint.config.URL)
+	}
+}
+
+// HandlesRateLimiting reports that pacing happens per endpoint inside the client
+func (w *WeightedAPIClient) HandlesRateLimiting() bool {
+	return true
+}
+
+// Query sends the batch to the best available endpoint, failing over on rate limit or server errors
+func (w *WeightedAPIClient) Query(ctx context.Context, keywords []string) (*APIResponse, error) {
+	tried := make(map[*weightedEndpoint]bool, len(w.endpoints))
+	var lastErr error
+
+	for len(tried) < len(w.endpoints) {
+		endpoint := w.selectEndpoint(tried)
+		if endpoint == nil {
+			break
+		}
+		tried[endpoint] = true
+
+		var resp *APIResponse
+		var err error
+		if w.hedger != nil {
+			resp, err = w.hedgedQuery(ctx, endpoint, keywords, tried)
+		} else {
+			resp, err = w.queryEndpoint(ctx, endpoint, keywords)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		// Budget is reserved atomically by the endpoint's quota guard; out of budget, try the next endpoint
+		if errors.Is(err, ErrQuotaExhausted) && ctx.Err() == nil {
+			continue
+		}
+		lastErr = err
+
+		if ctx.Err() != nil || !isFailoverError(err) {
+			return nil, err
+		}
+		w.secureLog.SafeWarn("API endpoint failed, failing over", map[string]interface{}{
+			"endpoint": w.secureLog.MaskAPIEndpoint(endpoint.config.URL),
+			"error":    err.Error(),
+		})
+	}
+
+	if lastErr == nil {
+		if w.quotaExhaustedEverywhere(ctx) {
+			return nil, fmt.Errorf("%w on all %d endpoints", ErrQuotaExhausted, len(w.endpoints))
+		}
+		return nil, fmt.Errorf("no healthy API endpoints available")
+	}
+	return nil, lastErr
+}
+
+// quotaExhaustedEverywhere reports whether every endpoint is out of budget
+func (w *WeightedAPIClient) quotaExhaustedEverywhere(ctx context.Context) bool {
+	if w.quota == nil {
+		return false
+	}
+	for _, endpoint := range w.endpoints {
+		if w.quota.Available(ctx, endpoint.config.URL) {
+			return false
+		}
+	}
+	return true
+}
+
+// queryEndpoint runs one query against an endpoint under its limiters and records the outcome
+func (w *WeightedAPIClient) queryEndpoint(ctx context.Context, endpoint *weightedEndpoint, keywords []string) (*APIResponse, error) {
+	atomic.AddInt64(&endpoint.inFlight, 1)
+	defer atomic.AddInt64(&endpoint.inFlight, -1)
+
+	start := time.Now()
+	var resp *APIResponse
+	query := func() error {
+		var err error
+		resp, err = endpoint.client.Query(ctx, keywords)
+		return err
+	}
+
+	var err error
+	if endpoint.rateLimiter != nil {
+		err = endpoint.rateLimiter.Execute(ctx, query)
+	} else {
+		err = query()
+	}
+
+	atomic.AddUint64(&endpoint.totalRequests, 1)
+	atomic.AddUint64(&endpoint.totalLatency, uint64(time.Since(start).Milliseconds()))
+	if err != nil && (ctx.Err() != nil || errors.Is(err, ErrQuotaExhausted)) {
+		// Cancelled by the caller (e.g. a hedge won) or out of budget - not the endpoint's fault
+		return nil, err
+	}
+	w.recordResult(endpoint, err)
+	return resp, err
+}
+
+// selectEndpoint picks a healthy endpoint that has not been tried for this query
+func (w *WeightedAPIClient) selectEndpoint(exclude map[*weightedEndpoint]bool) *weightedEndpoint {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	var candidates []*weightedEndpoint
+	for _, endpoint := range w.endpoints {
+		if exclude[endpoint] || now.Before(endpoint.excludedUntil) {
+			continue
+		}
+		candidates = append(candidates, endpoint)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if w.options.Strategy == StrategyLeastLoaded {
+		return leastLoaded(candidates)
+	}
+	return smoothWeighted(candidates)
+}
+
+// smoothWeighted implements nginx-style smooth weighted round-robin (caller holds mu)
+func smoothWeighted(candidates []*weightedEndpoint) *weightedEndpoint {
+	total := 0
+	var best *weightedEndpoint
+	for _, endpoint := range candidates {
+		endpoint.currentWeight += endpoint.config.Weight
+		total += endpoint.config.Weight
+		if best == nil || endpoint.currentWeight > best.currentWeight {
+			best = endpoint
+		}
+	}
+	best.currentWeight -= total
+	return best
+}
+
+// leastLoaded picks the endpoint with the lowest in-flight count per unit of weight
+func leastLoaded(candidates []*weightedEndpoint) *weightedEndpoint {
+	var best *weightedEndpoint
+	var bestLoad float64
+	for _, endpoint := range candidates {
+		load := float64(atomic.LoadInt64(&endpoint.inFlight)) / float64(endpoint.config.Weight)
+		if best == nil || load < bestLoad || (load == bestLoad && endpoint.config.Weight > best.config.Weight) {
+			best = endpoint
+			bestLoad = load
+		}
+	}
+	return best
+}
+
+// recordResult updates health state; repeated failures exclude the endpoint for the cooldown
+func (w *WeightedAPIClient) recordResult(endpoint *weightedEndpoint, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err == nil {
+		endpoint.consecutiveFailures = 0
+		return
+	}
+	atomic.AddUint64(&endpoint.failures, 1)
+	endpoint.consecutiveFailures++
+	if endpoint.consecutiveFailures >= w.options.FailureThreshold {
+		endpoint.excludedUntil = time.Now().Add(w.options.Cooldown)
+		endpoint.consecutiveFailures = 0
+		w.log.WithFields(map[string]interface{}{
+			"endpoint": w.secureLog.MaskAPIEndpoint(endpoint.config.URL),
+			"cooldown": w.options.Cooldown.String(),
+		}).Warn("Excluding unhealthy API endpoint")
+	}
+}
+
+// GetEndpointStats returns a snapshot of every endpoint
+func (w *WeightedAPIClient) GetEndpointStats() []EndpointStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	stats := make([]EndpointStats, 0, len(w.endpoints))
+	for _, endpoint := range w.endpoints {
+		requests := atomic.LoadUint64(&endpoint.totalRequests)
+		var avgLatency time.Duration
+		if requests > 0 {
+			avgLatency = time.Duration(atomic.LoadUint64(&endpoint.totalLatency)/requests) * time.Millisecond
+		}
+		stats = append(stats, EndpointStats{
+			URL:           endpoint.config.URL,
+			Weight:        endpoint.config.Weight,
+			InFlight:      atomic.LoadInt64(&endpoint.inFlight),
+			TotalRequests: requests,
+			Failures:      atomic.LoadUint64(&endpoint.failures),
+			AvgLatency:    avgLatency,
+			Healthy:       !now.Before(endpoint.excludedUntil),
+		})
+	}
+	return stats
+}
+
+// GetHedgeStats returns hedging counters (zero when hedging is disabled)
+func (w *WeightedAPIClient) GetHedgeStats() HedgeStats {
+	if w.hedger == nil {
+		return HedgeStats{}
+	}
+	return w.hedger.snapshot()
+}
+
+// Close is a no-op kept for symmetry with DualAPIClient
+func (w *WeightedAPIClient) Close() error {
+	return nil
+}
+
+// isFailoverError reports whether an error should be retried on another endpoint
+func isFailoverError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrQuotaExhausted) {
+		return true
+	}
+	return containsAny(err.Error(), []string{
+		"429", "500", "502", "503", "504", "rate limit", "too many requests",
+		"timeout", "connection refused", "request failed", "failed to parse",
+	})
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newSEOKeyTestServer(status int, hits *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(hits, 1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":[{"keyword":"test","metrics":{"avg_monthly_searches":10,"competition":"LOW"}}]}`))
+	}))
+}
+
+func TestParseEndpointList(t *testing.T) {
+	endpoints, err := ParseEndpointList("https://a.example.com/api;weight=3;rps=2.5;concurrency=4, https://b.example.com/api")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(endpoints))
+	}
+	if endpoints[0].Weight != 3 || endpoints[0].RequestsPerSecond != 2.5 || endpoints[0].MaxConcurrent != 4 {
+		t.Errorf("Options not parsed: %+v", endpoints[0])
+	}
+	if endpoints[1].Weight != 1 || endpoints[1].URL != "https://b.example.com/api" {
+		t.Errorf("Defaults not applied: %+v", endpoints[1])
+	}
+
+	for _, invalid := range []string{"", "https://a.example.com;weight=0", "https://a.example.com;bogus=1", "https://a.example.com;weight"} {
+		if _, err := ParseEndpointList(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestWeightedAPIClient_WeightedDistribution(t *testing.T) {
+	var hitsA, hitsB int64
+	serverA := newSEOKeyTestServer(http.StatusOK, &hitsA)
+	defer serverA.Close()
+	serverB := newSEOKeyTestServer(http.StatusOK, &hitsB)
+	defer serverB.Close()
+
+	client, err := NewWeightedAPIClient([]EndpointConfig{
+		{URL: serverA.URL, Weight: 3},
+		{URL: serverB.URL, Weight: 1},
+	}, DefaultWeightedClientOptions())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 8; i++ {
+		if _, err := client.Query(context.Background(), []string{"test"}); err != nil {
+			t.Fatalf("Query %d failed: %v", i, err)
+		}
+	}
+
+	if hitsA != 6 || hitsB != 2 {
+		t.Errorf("Expected 6/2 split for weights 3/1, got %d/%d", hitsA, hitsB)
+	}
+}
+
+func TestWeightedAPIClient_FailoverAndExclusion(t *testing.T) {
+	var hitsBad, hitsGood int64
+	bad := newSEOKeyTestServer(http.StatusServiceUnavailable, &hitsBad)
+	defer bad.Close()
+	good := newSEOKeyTestServer(http.StatusOK, &hitsGood)
+	defer good.Close()
+
+	options := DefaultWeightedClientOptions()
+	options.RetriesPerEndpoint = 0
+	options.FailureThreshold = 1
+	options.Cooldown = time.Minute
+	client, err := NewWeightedAPIClient([]EndpointConfig{
+		{URL: bad.URL, Weight: 10},
+		{URL: good.URL, Weight: 1},
+	}, options)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Query(context.Background(), []string{"test"}); err != nil {
+			t.Fatalf("Query %d should have failed over, got: %v", i, err)
+		}
+	}
+
+	if hitsBad != 1 {
+		t.Errorf("Expected unhealthy endpoint to be excluded after one failure, got %d hits", hitsBad)
+	}
+	if hitsGood != 3 {
+		t.Errorf("Expected 3 hits on healthy endpoint, got %d", hitsGood)
+	}
+
+	stats := client.GetEndpointStats()
+	if stats[0].Healthy || !stats[1].Healthy {
+		t.Errorf("Unexpected health snapshot: %+v", stats)
+	}
+}
+
+// countingQuota allows a fixed number of requests per endpoint
+type countingQuota struct {
+	limit int
+	used  map[string]int
+}
+
+func (q *countingQuota) Available(ctx context.Context, endpoint string) bool {
+	return q.used[endpoint] < q.limit
+}
+
+func (q *countingQuota) Reserve(ctx context.Context, endpoint string) error {
+	if !q.Available(ctx, endpoint) {
+		return fmt.Errorf("%w: %s", ErrQuotaExhausted, endpoint)
+	}
+	q.used[endpoint]++
+	return nil
+}
+
+func TestWeightedAPIClient_QuotaFailover(t *testing.T) {
+	var hitsA, hitsB int64
+	serverA := newSEOKeyTestServer(http.StatusOK, &hitsA)
+	defer serverA.Close()
+	serverB := newSEOKeyTestServer(http.StatusOK, &hitsB)
+	defer serverB.Close()
+
+	client, err := NewWeightedAPIClient([]EndpointConfig{
+		{URL: serverA.URL, Weight: 10},
+		{URL: serverB.URL, Weight: 1},
+	}, DefaultWeightedClientOptions())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.SetQuotaGuard(&countingQuota{limit: 2, used: make(map[string]int)})
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.Query(context.Background(), []string{"test"}); err != nil {
+			t.Fatalf("Query %d failed: %v", i, err)
+		}
+	}
+	if hitsA != 2 || hitsB != 2 {
+		t.Errorf("Expected budget to spill over to the second endpoint, got %d/%d", hitsA, hitsB)
+	}
+
+	_, err = client.Query(context.Background(), []string{"test"})
+	if !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("Expected ErrQuotaExhausted once every endpoint is spent, got %v", err)
+	}
+	for _, stats := range client.GetEndpointStats() {
+		if !stats.Healthy {
+			t.Errorf("Quota exhaustion must not mark endpoints unhealthy: %+v", stats)
+		}
+	}
+}
+
+func TestWeightedAPIClient_HedgesSlowEndpoint(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.Write([]byte(`{"status":"success","data":[{"keyword":"test","metrics":{"avg_monthly_searches":1,"competition":"LOW"}}]}`))
+	}))
+	defer slow.Close()
+	var fastHits int64
+	fast := newSEOKeyTestServer(http.StatusOK, &fastHits)
+	defer fast.Close()
+
+	options := DefaultWeightedClientOptions()
+	options.Hedge = HedgeOptions{Enabled: true, Percentile: 0.9, MaxDelay: 50 * time.Millisecond, MinSamples: 100, BudgetRatio: 0.5, BudgetBurst: 1}
+	client, err := NewWeightedAPIClient([]EndpointConfig{
+		{URL: slow.URL, Weight: 10},
+		{URL: fast.URL, Weight: 1},
+	}, options)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	start := time.Now()
+	resp, err := client.Query(context.Background(), []string{"test"})
+	if err != nil {
+		t.Fatalf("Hedged query failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("Expected hedge to answer quickly, took %v", elapsed)
+	}
+	if len(resp.Keywords) != 1 {
+		t.Errorf("Expected 1 keyword, got %d", len(resp.Keywords))
+	}
+
+	stats := client.GetHedgeStats()
+	if stats.HedgesSent != 1 || stats.HedgesWon != 1 {
+		t.Errorf("Unexpected hedge stats: %+v", stats)
+	}
+	if endpointStats := client.GetEndpointStats(); endpointStats[0].Failures != 0 {
+		t.Errorf("Cancelled loser must not count as a failure: %+v", endpointStats[0])
+	}
+	if latency, samples := client.hedger.latencies.percentile(0.5); samples != 1 || latency < 50*time.Millisecond {
+		t.Errorf("Expected the primary's elapsed time sampled when the hedge won, got %v over %d samples", latency, samples)
+	}
+}
+
+func TestHedger_Budget(t *testing.T) {
+	h := newHedger(HedgeOptions{Enabled: true, BudgetRatio: 0.1, BudgetBurst: 0})
+	for i := 0; i < 10; i++ {
+		h.recordPrimary()
+	}
+	if !h.tryAcquire() {
+		t.Fatal("Expected one hedge allowed after 10 primaries at 10% budget")
+	}
+	if h.tryAcquire() {
+		t.Fatal("Expected second hedge to be denied")
+	}
+	if stats := h.snapshot(); stats.HedgesDenied != 1 {
+		t.Errorf("Expected 1 denied hedge, got %+v", stats)
+	}
+}
+
+// countingLimiter is a ConcurrencyLimiter that tracks how many permits are held
+type countingLimiter struct {
+	held int64
+}
+
+func (l *countingLimiter) Acquire(ctx context.Context) error {
+	atomic.AddInt64(&l.held, 1)
+	return nil
+}
+
+func (l *countingLimiter) Release() {
+	atomic.AddInt64(&l.held, -1)
+}
+
+func TestHTTPAPIClient_CancelledRequestReleasesPermit(t *testing.T) {
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	defer server.Close()
+
+	limiter := &countingLimiter{}
+	client := NewHTTPAPIClientWithConcurrency(server.URL, "", DefaultConnectionConfig(), limiter)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.Query(ctx, []string{"test"}); err == nil {
+		t.Fatal("Expected the query to fail when its context ended")
+	}
+	if held := atomic.LoadInt64(&limiter.held); held != 0 {
+		t.Errorf("Expected the permit released as soon as the request was cancelled, %d held", held)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the cancelled request to close its connection")
+	}
+}
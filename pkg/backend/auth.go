@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Backend authentication schemes
+const (
+	AuthAPIKey = "api-key" // X-API-Key header (default)
+	AuthBearer = "bearer"  // Authorization: Bearer <token>
+	AuthHMAC   = "hmac"    // Body signed like webhooks, see SignWebhookPayload
+)
+
+// AuthKeyIDHeader names the HMAC secret in use so the backend can rotate secrets
+const AuthKeyIDHeader = "X-Key-Id"
+
+// AuthConfig selects how requests prove their origin
+// The credential is BackendConfig.APIKey, or the contents of TokenFile when set
+type AuthConfig struct {
+	Scheme    string `json:"scheme,omitempty"`     // api-key, bearer or hmac
+	TokenFile string `json:"token_file,omitempty"` // Re-read when it changes, for rotated tokens
+	KeyID     string `json:"key_id,omitempty"`     // Sent as X-Key-Id with HMAC signatures
+}
+
+// Validate checks the scheme name
+func (c AuthConfig) Validate() error {
+	switch c.Scheme {
+	case "", AuthAPIKey, AuthBearer, AuthHMAC:
+		return nil
+	default:
+		return fmt.Errorf("unknown backend auth scheme %q, use %s, %s or %s", c.Scheme, AuthAPIKey, AuthBearer, AuthHMAC)
+	}
+}
+
+// requestAuth adds credentials to backend requests
+type requestAuth struct {
+	config AuthConfig
+	static string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+func newRequestAuth(config BackendConfig) (*requestAuth, error) {
+	if err := config.Auth.Validate(); err != nil {
+		return nil, err
+	}
+	if config.APIKey == "" && config.Auth.TokenFile == "" {
+		return nil, fmt.Errorf("backend API key is required - set BACKEND_API_KEY environment variable")
+	}
+	auth := &requestAuth{config: config.Auth, static: config.APIKey}
+	if config.Auth.TokenFile != "" {
+		if _, err := auth.credential(); err != nil {
+			return nil, err
+		}
+	}
+	return auth, nil
+}
+
+// signsBody reports whether the scheme needs the complete body before sending
+func (a *requestAuth) signsBody() bool {
+	return a.config.Scheme == AuthHMAC
+}
+
+// apply sets auth headers; with HMAC it must run after the body is set
+func (a *requestAuth) apply(req *fasthttp.Request) error {
+	credential, err := a.credential()
+	if err != nil {
+		return err
+	}
+	switch a.config.Scheme {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+credential)
+	case AuthHMAC:
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload([]byte(credential), timestamp, req.Body()))
+		if a.config.KeyID != "" {
+			req.Header.Set(AuthKeyIDHeader, a.config.KeyID)
+		}
+	default:
+		req.Header.Set("X-API-Key", credential)
+	}
+	return nil
+}
+
+// credential returns the token file contents, re-reading the file only after it changed
+func (a *requestAuth) credential() (string, error) {
+	if a.config.TokenFile == "" {
+		return a.static, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, err := os.Stat(a.config.TokenFile)
+	if err != nil {
+		if a.token != "" {
+			return a.token, nil // Keep the last token while a rotation replaces the file
+		}
+		return "", fmt.Errorf("failed to read backend token file: %w", err)
+	}
+	if a.token != "" && info.ModTime().Equal(a.modTime) {
+		return a.token, nil
+	}
+	data, err := os.ReadFile(a.config.TokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backend token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("backend token file %s is empty", a.config.TokenFile)
+	}
+	a.token = token
+	a.modTime = info.ModTime()
+	return token, nil
+}
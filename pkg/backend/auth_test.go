@@ -0,0 +1,78 @@
+package backend_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/mock"
+)
+
+func TestBackendClient_AuthSchemesAgainstMock(t *testing.T) {
+	server, err := mock.NewBackendServer("", "", mock.Behavior{Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+	batch := backend.KeywordMetricsBatch{{Keyword: "one", URL: "https://example.com/one"}}
+	submit := func(config backend.BackendConfig) error {
+		t.Helper()
+		config.BaseURL = server.URL()
+		client, err := backend.NewBackendClient(config)
+		if err != nil {
+			t.Fatalf("Failed to create backend client: %v", err)
+		}
+		_, err = client.SubmitBatch(batch)
+		return err
+	}
+
+	// Bearer tokens read from a file, rotated while the client runs
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("first-token\n"), 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	if err := server.SetAuth(mock.Auth{Scheme: backend.AuthBearer, TokenFile: tokenFile}); err != nil {
+		t.Fatalf("SetAuth failed: %v", err)
+	}
+	bearer := backend.AuthConfig{Scheme: backend.AuthBearer, TokenFile: tokenFile}
+	if err := submit(backend.BackendConfig{Auth: bearer}); err != nil {
+		t.Errorf("Expected the bearer token to be accepted, got %v", err)
+	}
+	if err := submit(backend.BackendConfig{APIKey: "first-token"}); err == nil {
+		t.Error("Expected the token as X-API-Key to be refused under bearer auth")
+	}
+	rotated := time.Now().Add(time.Minute)
+	if err := os.WriteFile(tokenFile, []byte("second-token\n"), 0600); err != nil {
+		t.Fatalf("Failed to rotate token file: %v", err)
+	}
+	os.Chtimes(tokenFile, rotated, rotated)
+	if err := submit(backend.BackendConfig{Auth: bearer}); err != nil {
+		t.Errorf("Expected the rotated token to be accepted, got %v", err)
+	}
+	if err := submit(backend.BackendConfig{APIKey: "first-token", Auth: backend.AuthConfig{Scheme: backend.AuthBearer}}); err == nil {
+		t.Error("Expected the token replaced by the rotation to be refused")
+	}
+
+	// HMAC signatures over the compressed body, with a key ID
+	if err := server.SetAuth(mock.Auth{Scheme: backend.AuthHMAC, Secret: "secret", KeyID: "k1"}); err != nil {
+		t.Fatalf("SetAuth failed: %v", err)
+	}
+	if err := submit(backend.BackendConfig{APIKey: "secret", EnableGzip: true, Auth: backend.AuthConfig{Scheme: backend.AuthHMAC, KeyID: "k1"}}); err != nil {
+		t.Errorf("Expected the HMAC signature to be accepted, got %v", err)
+	}
+	if err := submit(backend.BackendConfig{APIKey: "wrong", Auth: backend.AuthConfig{Scheme: backend.AuthHMAC, KeyID: "k1"}}); err == nil {
+		t.Error("Expected a signature with the wrong secret to be refused")
+	}
+	if err := submit(backend.BackendConfig{APIKey: "secret", Auth: backend.AuthConfig{Scheme: backend.AuthHMAC, KeyID: "k2"}}); err == nil {
+		t.Error("Expected an unknown key ID to be refused")
+	}
+
+	if got := len(server.Stored()); got != 1 {
+		t.Errorf("Expected only authorized submissions stored once, got %d", got)
+	}
+	if err := server.SetAuth(mock.Auth{Scheme: "basic"}); err == nil {
+		t.Error("Expected an unknown scheme to be rejected")
+	}
+}
@@ -0,0 +1,232 @@
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ChangeEventsEndpoint receives batches of sitemap change events
+const ChangeEventsEndpoint = "/api/v1/sitemap-changes/batch"
+
+// Change types carried by ChangeEvent, matching detector.ChangeType
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+	ChangeDelisted = "delisted" // Removal confirmed after the grace period
+	ChangeRelisted = "relisted" // A delisted URL is back in its sitemap
+)
+
+const changeEventBatchSize = 100
+
+// ChangeEventBatch is the body of a change-event submission
+type ChangeEventBatch []ChangeEvent
+
+// ChangeEvent reports one URL that appeared in, disappeared from or changed in a sitemap
+type ChangeEvent struct {
+	URL                string     `json:"url"`
+	Domain             string     `json:"domain"`
+	SitemapURL         string     `json:"sitemap_url,omitempty"`
+	ChangeType         string     `json:"change_type"`
+	Keyword            string     `json:"keyword,omitempty"`              // Extracted from the URL like query keywords
+	LastModified       string     `json:"last_modified,omitempty"`        // Sitemap <lastmod> as published
+	DetectedAt         time.Time  `json:"detected_at"`                    // When this run saw the change
+	PreviousSnapshotAt *time.Time `json:"previous_snapshot_at,omitempty"` // Snapshot the sitemap was compared with
+	FirstSeen          *time.Time `json:"first_seen,omitempty"`           // Delisting notices: first run that listed the URL
+	LastSeen           *time.Time `json:"last_seen,omitempty"`            // Delisting notices: last run that listed the URL
+	RunID              string     `json:"run_id,omitempty"`
+	IdempotencyKey     string     `json:"idempotency_key,omitempty"`
+}
+
+// ChangeEventClient is implemented by clients that can deliver change events
+type ChangeEventClient interface {
+	SubmitChangeEvents(events []ChangeEvent) error
+}
+
+// ChangeEventKey derives an event's key from URL, change type and the run that detected it
+func ChangeEventKey(event ChangeEvent) string {
+	return hashKey("change", event.URL, event.ChangeType, event.RunID)
+}
+
+// ChangeEventBatchKey derives a batch key from the keys of its events
+func ChangeEventBatchKey(events []ChangeEvent) string {
+	keys := make([]string, len(events))
+	for i, event := range events {
+		keys[i] = event.IdempotencyKey
+	}
+	return hashKey(keys...)
+}
+
+// AssignChangeEventKeys stamps the run ID and key on events that do not carry them yet
+func AssignChangeEventKeys(events []ChangeEvent, runID string) {
+	for i := range events {
+		if events[i].IdempotencyKey != "" {
+			continue
+		}
+		if events[i].RunID == "" {
+			events[i].RunID = runID
+		}
+		events[i].IdempotencyKey = ChangeEventKey(events[i])
+	}
+}
+
+// SubmitChangeEvents sends events in batches, stopping at the first failed batch
+// Failures are not retried here: the submission pool keeps the events in its outbox and replays
+// them, and batches sent before the failure are deduplicated by their keys on the replay
+func (c *httpBackendClient) SubmitChangeEvents(events []ChangeEvent) error {
+	AssignChangeEventKeys(events, "")
+	for start := 0; start < len(events); start += changeEventBatchSize {
+		end := start + changeEventBatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+		if err := c.sendChangeEvents(ChangeEventBatch(events[start:end])); err != nil {
+			return fmt.Errorf("failed to submit change events %d-%d of %d: %w", start+1, end, len(events), err)
+		}
+	}
+	return nil
+}
+
+// sendChangeEvents posts one batch
+func (c *httpBackendClient) sendChangeEvents(batch ChangeEventBatch) error {
+	batchKey := ChangeEventBatchKey(batch)
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal change events: %w", err)
+	}
+	if c.config.EnableGzip {
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		if _, err := gzipWriter.Write(body); err != nil {
+			return fmt.Errorf("failed to write to gzip: %w", err)
+		}
+		if err := gzipWriter.Close(); err != nil {
+			return fmt.Errorf("failed to close gzip writer: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(c.config.BaseURL + ChangeEventsEndpoint)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	req.Header.Set(IdempotencyHeader, batchKey)
+	if c.config.EnableGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	req.SetBody(body)
+	if err := c.auth.apply(req); err != nil {
+		return err
+	}
+
+	if err := c.client.DoTimeout(req, resp, c.config.Timeout); err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	status := resp.StatusCode()
+	switch {
+	case status == fasthttp.StatusOK || status == fasthttp.StatusAccepted:
+	case status == fasthttp.StatusConflict || status == fasthttp.StatusAlreadyReported:
+		c.log.WithField("batch_id", batchKey).Info("Backend already has change event batch, treating resend as success")
+	default:
+		return fmt.Errorf("Backend API returned status %d (response body hidden for security)", status)
+	}
+
+	c.log.WithFields(map[string]interface{}{
+		"batch_id": batchKey,
+		"events":   len(batch),
+	}).Info("Change events submitted")
+	return nil
+}
+
+// DelistingSink is implemented by sinks that take delisting notices besides keyword metrics
+type DelistingSink interface {
+	WriteDelistings(events []ChangeEvent) error
+}
+
+// DelistingEvents returns the delisted and relisted notices among events
+func DelistingEvents(events []ChangeEvent) []ChangeEvent {
+	var notices []ChangeEvent
+	for _, event := range events {
+		if event.ChangeType == ChangeDelisted || event.ChangeType == ChangeRelisted {
+			notices = append(notices, event)
+		}
+	}
+	return notices
+}
+
+// SubmitChangeEvents forwards events to the backend sink and delisting notices to every sink
+// that takes them; other sinks take keyword metrics only
+func (f *FanOutClient) SubmitChangeEvents(events []ChangeEvent) error {
+	AssignChangeEventKeys(events, "")
+	delistings := DelistingEvents(events)
+
+	sentToBackend := false
+	var failed []string
+	for _, configured := range f.sinks {
+		var err error
+		switch sink := configured.sink.(type) {
+		case *backendSink:
+			eventClient, ok := sink.client.(ChangeEventClient)
+			if !ok || sentToBackend {
+				continue
+			}
+			sentToBackend = true
+			err = eventClient.SubmitChangeEvents(events)
+		case DelistingSink:
+			if len(delistings) == 0 {
+				continue
+			}
+			err = sink.WriteDelistings(delistings)
+		default:
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		entry := f.log.WithError(err).WithFields(map[string]interface{}{
+			"sink":   configured.sink.Name(),
+			"events": len(events),
+		})
+		if configured.spec.OnFailure == FailureDrop {
+			entry.Warn("Change event delivery failed, dropping events")
+			continue
+		}
+		failed = append(failed, fmt.Sprintf("%s: %v", configured.sink.Name(), err))
+	}
+	if !sentToBackend {
+		f.log.WithField("events", len(events)).Debug("No backend sink configured, change events not sent")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("change event delivery failed (%s)", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// SubmitChangeEvents records events
+func (c *RecordingClient) SubmitChangeEvents(events []ChangeEvent) error {
+	batch := append([]ChangeEvent(nil), events...)
+	AssignChangeEventKeys(batch, "")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, batch...)
+	return nil
+}
+
+// ChangeEvents returns every change event submitted so far
+func (c *RecordingClient) ChangeEvents() []ChangeEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ChangeEvent(nil), c.events...)
+}
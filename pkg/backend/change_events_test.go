@@ -0,0 +1,75 @@
+package backend_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/mock"
+	"sitemap-go/pkg/storage"
+)
+
+func TestSubmissionPool_ReplaysChangeEventsFromOutbox(t *testing.T) {
+	server, err := mock.NewBackendServer("", "", mock.Behavior{ErrorRate: 1, Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+
+	client, err := backend.NewBackendClient(backend.BackendConfig{BaseURL: server.URL(), APIKey: "any", BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create backend client: %v", err)
+	}
+	outbox := storage.NewOutbox(storage.NewMemoryStorage())
+	pool := backend.NewSubmissionPool(client, 1)
+	pool.SetOutbox(outbox)
+	pool.SetRunID("run-1")
+	ctx := context.Background()
+
+	detectedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := []backend.ChangeEvent{
+		{URL: "https://example.com/new-game", Domain: "example.com", ChangeType: backend.ChangeAdded, Keyword: "new game", DetectedAt: detectedAt},
+		{URL: "https://example.com/old-game", Domain: "example.com", ChangeType: backend.ChangeRemoved, Keyword: "old game", DetectedAt: detectedAt},
+	}
+	if err := pool.SubmitChangeEvents(ctx, events, nil); err != nil {
+		t.Fatalf("SubmitChangeEvents failed: %v", err)
+	}
+	entries, err := outbox.List(ctx)
+	if err != nil || len(entries) != 1 || entries[0].Kind != "change_events" || entries[0].Items != 2 {
+		t.Fatalf("Expected one change_events outbox entry with 2 items, got %+v (err %v)", entries, err)
+	}
+
+	// The client does not retry server errors itself: the entry stays in the outbox for the next flush
+	if sent, pending, err := pool.FlushOutbox(ctx); err != nil || sent != 0 || pending != 1 {
+		t.Fatalf("Expected the failing flush to leave 1 pending, got sent=%d pending=%d err=%v", sent, pending, err)
+	}
+	if got := len(server.Requests()); got != 1 {
+		t.Errorf("Expected a single attempt against the failing backend, got %d", got)
+	}
+
+	server.SetBehavior(mock.Behavior{})
+	if sent, pending, err := pool.FlushOutbox(ctx); err != nil || sent != 1 || pending != 0 {
+		t.Fatalf("Expected the replay to succeed, got sent=%d pending=%d err=%v", sent, pending, err)
+	}
+	stored := server.ChangeEvents()
+	if len(stored) != 2 {
+		t.Fatalf("Expected 2 stored change events, got %d", len(stored))
+	}
+	for _, event := range stored {
+		if event.RunID != "run-1" || event.IdempotencyKey != backend.ChangeEventKey(event) || !event.DetectedAt.Equal(detectedAt) {
+			t.Errorf("Change event lost its run, key or timestamp: %+v", event)
+		}
+	}
+	if stats := pool.GetStats(); stats.ChangeEvents != 2 {
+		t.Errorf("Expected 2 change events in the pool stats, got %d", stats.ChangeEvents)
+	}
+
+	// A resend under the same keys is not stored twice
+	if err := client.(backend.ChangeEventClient).SubmitChangeEvents(stored); err != nil {
+		t.Fatalf("Resend failed: %v", err)
+	}
+	if got := len(server.ChangeEvents()); got != 2 {
+		t.Errorf("Expected the resend to be deduplicated, got %d stored events", got)
+	}
+}
@@ -15,6 +15,7 @@ type httpBackendClient struct {
 	config              BackendConfig
 	client              *fasthttp.Client
 	log                 *logger.Logger
+	auth                *requestAuth
 	concurrentSubmitter *ConcurrentSubmitter
 }
 
@@ -26,8 +27,13 @@ func NewBackendClient(config BackendConfig) (BackendClient, error) {
 	if config.Timeout == 0 {
 		config.Timeout = 60 * time.Second // Default timeout
 	}
-	if config.APIKey == "" {
-		return nil, fmt.Errorf("backend API key is required - set BACKEND_API_KEY environment variable")
+	auth, err := newRequestAuth(config)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := config.TLS.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid backend TLS configuration: %w", err)
 	}
 
 	// Create reusable client with production-optimized settings
@@ -37,12 +43,14 @@ func NewBackendClient(config BackendConfig) (BackendClient, error) {
 		MaxConnsPerHost:     20,  // Reduced from 100 to prevent overwhelming backend
 		MaxIdleConnDuration: 30 * time.Second,  // Reduced from 90s for better resource management
 		MaxConnDuration:     5 * time.Minute,   // Add max connection duration
+		TLSConfig:           tlsConfig,
 	}
 
 	backendClient := &httpBackendClient{
 		config: config,
 		client: client,
 		log:    logger.GetLogger().WithField("component", "backend_client"),
+		auth:   auth,
 	}
 
 	// Initialize concurrent submitter
@@ -55,6 +63,14 @@ func NewBackendClient(config BackendConfig) (BackendClient, error) {
 func (c *httpBackendClient) SubmitBatch(batch KeywordMetricsBatch) (*BackendResponse, error) {
 	c.log.WithField("batch_size", len(batch)).Debug("Submitting keyword metrics batch")
 
+	// Key the batch on a copy so resends of the caller's data derive the same keys
+	batch = append(KeywordMetricsBatch(nil), batch...)
+	AssignIdempotencyKeys(batch, "")
+	batchKey := BatchIdempotencyKey(batch)
+	for i := range batch {
+		batch[i].BatchID = batchKey
+	}
+
 	// Marshal JSON
 	jsonData, err := json.Marshal(batch)
 	if err != nil {
@@ -111,13 +127,17 @@ func (c *httpBackendClient) SubmitBatch(batch KeywordMetricsBatch) (*BackendResp
 	req.SetRequestURI(url)
 	req.Header.SetMethod(fasthttp.MethodPost)
 	req.Header.SetContentType("application/json")
-	req.Header.Set("X-API-Key", c.config.APIKey)
+	req.Header.Set(IdempotencyHeader, batchKey)
 	
 	if contentEncoding != "" {
 		req.Header.Set("Content-Encoding", contentEncoding)
 	}
 	
 	req.SetBody(requestBody)
+	// Signing schemes cover the body as sent, so credentials go on last
+	if err := c.auth.apply(req); err != nil {
+		return nil, err
+	}
 
 	c.log.WithFields(map[string]interface{}{
 		"url":              url,
@@ -133,6 +153,14 @@ func (c *httpBackendClient) SubmitBatch(batch KeywordMetricsBatch) (*BackendResp
 
 	// Check status code - accept both 200 OK and 202 Accepted
 	statusCode := resp.StatusCode()
+	if statusCode == fasthttp.StatusConflict || statusCode == fasthttp.StatusAlreadyReported {
+		// The backend already has this batch from an earlier send: the goal is reached
+		c.log.WithFields(map[string]interface{}{
+			"batch_id":   batchKey,
+			"batch_size": len(batch),
+		}).Info("Backend already accepted batch, treating resend as success")
+		return &BackendResponse{Code: statusCode, Message: "already accepted"}, nil
+	}
 	if statusCode != fasthttp.StatusOK && statusCode != fasthttp.StatusAccepted {
 		// Don't expose full response body in error - potential backend info leak
 		return nil, fmt.Errorf("Backend API returned status %d (response body hidden for security)", statusCode)
@@ -149,12 +177,21 @@ func (c *httpBackendClient) SubmitBatch(batch KeywordMetricsBatch) (*BackendResp
 		"response_message": backendResp.Message,
 		"batch_size":       len(batch),
 	}).Info("Backend submission completed")
+	if backendResp.Data != nil {
+		if rejected := backendResp.Data.Count(ItemRejected); rejected > 0 {
+			c.log.WithFields(map[string]interface{}{
+				"batch_id":   batchKey,
+				"rejected":   rejected,
+				"batch_size": len(batch),
+			}).Warn("Backend rejected records in batch")
+		}
+	}
 
 	return &backendResp, nil
 }
 
 // SubmitBatches splits data into batches and submits them with controlled concurrency
-func (c *httpBackendClient) SubmitBatches(data []KeywordMetricsData) error {
+func (c *httpBackendClient) SubmitBatches(data []KeywordMetricsData) (SubmissionResult, error) {
 	return c.concurrentSubmitter.SubmitBatchesConcurrently(data, c.config.BatchSize)
 }
 
@@ -19,6 +19,7 @@ type batchWork struct {
 // batchResult represents the result of a batch submission
 type batchResult struct {
 	batchNum int
+	items    SubmissionResult
 	err      error
 }
 
@@ -37,10 +38,12 @@ func NewConcurrentSubmitter(client BackendClient) *ConcurrentSubmitter {
 }
 
 // SubmitBatchesConcurrently submits batches with controlled concurrency
-func (cs *ConcurrentSubmitter) SubmitBatchesConcurrently(data []KeywordMetricsData, batchSize int) error {
+// Per-item results of every answered batch are merged, also when other batches failed
+func (cs *ConcurrentSubmitter) SubmitBatchesConcurrently(data []KeywordMetricsData, batchSize int) (SubmissionResult, error) {
+	var items SubmissionResult
 	if len(data) == 0 {
 		cs.log.Debug("No data to submit")
-		return nil
+		return items, nil
 	}
 
 	totalBatches := (len(data) + batchSize - 1) / batchSize
@@ -104,6 +107,7 @@ func (cs *ConcurrentSubmitter) SubmitBatchesConcurrently(data []KeywordMetricsDa
 	var firstError error
 
 	for result := range resultChan {
+		items.Merge(result.items)
 		if result.err != nil {
 			failureCount++
 			if firstError == nil {
@@ -132,13 +136,16 @@ func (cs *ConcurrentSubmitter) SubmitBatchesConcurrently(data []KeywordMetricsDa
 		"failed_batches":     failureCount,
 		"success_rate":       fmt.Sprintf("%.1f%%", float64(successCount)/float64(totalBatches)*100),
 		"concurrency":        maxConcurrency,
+		"accepted_items":     items.Accepted,
+		"rejected_items":     len(items.Rejected),
+		"duplicate_items":    items.Duplicates,
 	}).Info("Concurrent batch submission completed")
 
 	if failureCount > 0 {
-		return fmt.Errorf("failed to submit %d out of %d batches (first error: %v)", failureCount, totalBatches, firstError)
+		return items, fmt.Errorf("failed to submit %d out of %d batches (first error: %v)", failureCount, totalBatches, firstError)
 	}
 
-	return nil
+	return items, nil
 }
 
 // batchWorker processes batch submission tasks concurrently
@@ -153,12 +160,18 @@ func (cs *ConcurrentSubmitter) batchWorker(ctx context.Context, wg *sync.WaitGro
 			}
 
 			// Submit the batch
-			_, err := cs.client.SubmitBatch(KeywordMetricsBatch(work.data))
+			batch := KeywordMetricsBatch(work.data)
+			resp, err := cs.client.SubmitBatch(batch)
+			var items SubmissionResult
+			if err == nil {
+				items = ResolveItemResults(batch, resp)
+			}
 			
 			// Send result
 			select {
 			case resultChan <- batchResult{
 				batchNum: work.batchNum,
+				items:    items,
 				err:      err,
 			}:
 			case <-ctx.Done():
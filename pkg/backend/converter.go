@@ -2,7 +2,6 @@ package backend
 
 import (
 	"fmt"
-	"math/rand"
 	"strconv"
 	"time"
 
@@ -60,8 +59,11 @@ func (dc *DataConverter) convertAPIResponse(apiResp *api.APIResponse, keywordURL
 	var metrics []KeywordMetricsData
 
 	for _, keyword := range apiResp.Keywords {
-		// Get associated URL for this keyword
-		sourceURL := keywordURLMap[keyword.Word]
+		// Get associated URL for this keyword (locale-qualified keys first)
+		sourceURL := keywordURLMap[keyword.LocaleKey()]
+		if sourceURL == "" {
+			sourceURL = keywordURLMap[keyword.Word]
+		}
 		if sourceURL == "" {
 			// Fallback to sitemap URL if no specific URL mapping
 			sourceURL = sitemapURL
@@ -73,6 +75,7 @@ func (dc *DataConverter) convertAPIResponse(apiResp *api.APIResponse, keywordURL
 		metrics = append(metrics, KeywordMetricsData{
 			Keyword: keyword.Word,
 			URL:     sourceURL,
+			Locale:  keyword.Locale,
 			Metrics: backendMetrics,
 		})
 	}
@@ -86,34 +89,30 @@ func (dc *DataConverter) ConvertKeywordMetrics(keyword api.Keyword) MetricsData
 }
 
 // convertKeywordMetrics converts a single keyword's data to backend metrics format
+// Only provider-reported values are used; omitted data is flagged in DataQuality
 func (dc *DataConverter) convertKeywordMetrics(keyword api.Keyword) MetricsData {
-	// Generate realistic monthly search data based on search volume
-	monthlySearches := dc.generateMonthlySearchData(keyword.SearchVolume)
-	
+	monthlySearches := dc.convertMonthlySearchData(keyword.MonthlySearches)
+
 	// Calculate derived metrics
 	avgSearches := int64(keyword.SearchVolume)
-	latestSearches := avgSearches
+	latestSearches := int64(keyword.LatestSearches)
 	maxSearches := avgSearches
-	
-	if len(monthlySearches) > 0 {
-		latestSearches = monthlySearches[len(monthlySearches)-1].Searches
-		for _, monthly := range monthlySearches {
-			if monthly.Searches > maxSearches {
-				maxSearches = monthly.Searches
-			}
+
+	for _, monthly := range monthlySearches {
+		if monthly.Searches > maxSearches {
+			maxSearches = monthly.Searches
 		}
 	}
 
-	// Convert competition level
-	competition := dc.mapCompetitionLevel(keyword.Competition)
-	competitionIndex := dc.convertCompetitionToIndex(keyword.Competition)
-	
-	// Convert CPC to micro units (multiply by 1,000,000)
-	lowBidMicro := int64(keyword.CPC * 0.8 * 1000000)   // 80% of CPC
-	highBidMicro := int64(keyword.CPC * 1.2 * 1000000)  // 120% of CPC
-
-	// Generate data quality info
-	dataQuality := dc.generateDataQuality(monthlySearches)
+	// Prefer the provider's competition level and index over derived values
+	competition := keyword.CompetitionLevel
+	if competition == "" || competition == "UNSPECIFIED" || competition == "UNKNOWN" {
+		competition = dc.mapCompetitionLevel(keyword.Competition)
+	}
+	competitionIndex := keyword.CompetitionIndex
+	if keyword.HasMissing(api.MissingCompetitionIndex) {
+		competitionIndex = dc.convertCompetitionToIndex(keyword.Competition)
+	}
 
 	return MetricsData{
 		AvgMonthlySearches:        avgSearches,
@@ -121,35 +120,23 @@ func (dc *DataConverter) convertKeywordMetrics(keyword api.Keyword) MetricsData
 		MaxMonthlySearches:       maxSearches,
 		Competition:              competition,
 		CompetitionIndex:         competitionIndex,
-		LowTopOfPageBidMicro:     lowBidMicro,
-		HighTopOfPageBidMicro:    highBidMicro,
+		LowTopOfPageBidMicro:     keyword.LowTopOfPageBidMicro,
+		HighTopOfPageBidMicro:    keyword.HighTopOfPageBidMicro,
 		MonthlySearches:          monthlySearches,
-		DataQuality:              dataQuality,
+		DataQuality:              dc.generateDataQuality(keyword.MonthlySearches, keyword.MissingFields),
 	}
 }
 
-// generateMonthlySearchData generates realistic monthly search data
-func (dc *DataConverter) generateMonthlySearchData(baseVolume int) []MonthlySearchData {
-	var monthlyData []MonthlySearchData
-	now := time.Now()
-	
-	// Generate data for the past 12 months
-	for i := 11; i >= 0; i-- {
-		monthTime := now.AddDate(0, -i, 0)
-		year := strconv.Itoa(monthTime.Year())
-		month := strconv.Itoa(int(monthTime.Month()))
-		
-		// Add some realistic variation (±20%)
-		variation := 0.8 + rand.Float64()*0.4 // 0.8 to 1.2
-		searches := int64(float64(baseVolume) * variation)
-		
+// convertMonthlySearchData converts the provider's monthly series to backend format
+func (dc *DataConverter) convertMonthlySearchData(series []api.MonthlySearch) []MonthlySearchData {
+	monthlyData := make([]MonthlySearchData, 0, len(series))
+	for _, monthly := range series {
 		monthlyData = append(monthlyData, MonthlySearchData{
-			Year:     year,
-			Month:    month,
-			Searches: searches,
+			Year:     strconv.Itoa(monthly.Year),
+			Month:    strconv.Itoa(monthly.Month),
+			Searches: monthly.Searches,
 		})
 	}
-	
 	return monthlyData
 }
 
@@ -169,38 +156,69 @@ func (dc *DataConverter) convertCompetitionToIndex(competition float64) int {
 	return int(competition * 100)
 }
 
-// generateDataQuality generates data quality information
-func (dc *DataConverter) generateDataQuality(monthlySearches []MonthlySearchData) DataQualityInfo {
-	totalMonths := len(monthlySearches)
+// generateDataQuality reports on the provider's monthly series
+// Months inside the reported range that are absent or have zero searches count as missing
+func (dc *DataConverter) generateDataQuality(series []api.MonthlySearch, missingFields []string) DataQualityInfo {
+	warnings := []string{}
+	if missingFields == nil {
+		missingFields = []string{}
+	}
+	for _, field := range missingFields {
+		warnings = append(warnings, fmt.Sprintf("Provider omitted %s", field))
+	}
+
+	if len(series) == 0 {
+		return DataQualityInfo{
+			Status:        DataQualityMissing,
+			MissingMonths: []string{},
+			MissingFields: missingFields,
+			Warnings:      warnings,
+		}
+	}
+
+	// Index reported months so gaps in the calendar range can be detected
+	reported := make(map[string]int64, len(series))
+	for _, monthly := range series {
+		reported[monthKey(monthly.Year, monthly.Month)] = monthly.Searches
+	}
+
+	first := time.Date(series[0].Year, time.Month(series[0].Month), 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(series[len(series)-1].Year, time.Month(series[len(series)-1].Month), 1, 0, 0, 0, 0, time.UTC)
+
+	totalMonths := 0
 	availableMonths := 0
 	missingMonths := []string{}
 	hasZeroMonths := false
-	onlyLastHasData := false
-
-	// Count available months and find missing ones
-	for _, monthly := range monthlySearches {
-		if monthly.Searches > 0 {
-			availableMonths++
-		} else {
+	hasGaps := false
+	lastHasData := false
+
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		totalMonths++
+		key := monthKey(month.Year(), int(month.Month()))
+		searches, ok := reported[key]
+		switch {
+		case !ok:
+			hasGaps = true
+			missingMonths = append(missingMonths, key)
+		case searches <= 0:
 			hasZeroMonths = true
-			missingMonths = append(missingMonths, fmt.Sprintf("%v-%v", monthly.Year, monthly.Month))
+			missingMonths = append(missingMonths, key)
+		default:
+			availableMonths++
+			lastHasData = month.Equal(last)
 		}
 	}
 
-	// Check if only last month has data
-	if totalMonths > 0 && availableMonths == 1 {
-		if monthlySearches[totalMonths-1].Searches > 0 {
-			onlyLastHasData = true
-		}
-	}
+	onlyLastHasData := availableMonths == 1 && lastHasData
 
-	// Determine status
-	status := "complete"
+	status := DataQualityComplete
 	if availableMonths < totalMonths {
-		status = "incomplete"
+		status = DataQualityIncomplete
 	}
 
-	warnings := []string{}
+	if hasGaps {
+		warnings = append(warnings, "Provider series has gaps between reported months")
+	}
 	if hasZeroMonths {
 		warnings = append(warnings, "Some months have zero search volume")
 	}
@@ -217,6 +235,12 @@ func (dc *DataConverter) generateDataQuality(monthlySearches []MonthlySearchData
 		AvailableMonths:         availableMonths,
 		MissingMonthsCount:      len(missingMonths),
 		MissingMonths:           missingMonths,
+		MissingFields:           missingFields,
 		Warnings:                warnings,
 	}
-}
\ No newline at end of file
+}
+
+// monthKey formats a year/month pair the way MissingMonths reports it
+func monthKey(year, month int) string {
+	return fmt.Sprintf("%d-%d", year, month)
+}
@@ -0,0 +1,278 @@
+package backend
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// appendFile opens path for appending, creating parent directories as needed
+// It reports whether the file was empty, so writers know to emit a header
+func appendFile(path string) (*os.File, bool, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, false, fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, false, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return file, info.Size() == 0, nil
+}
+
+// JSONLSink appends one JSON object per record to a local file
+// Each Write is flushed before it returns, so a crash loses at most the batch in flight.
+// Delisting notices go to a sibling file, see DelistingPath
+type JSONLSink struct {
+	path          string
+	file          *os.File
+	delistingFile *os.File
+	mu            sync.Mutex
+}
+
+// NewJSONLSink creates a sink appending to path; the file is opened on first write
+func NewJSONLSink(path string) *JSONLSink {
+	return &JSONLSink{path: path}
+}
+
+// Name identifies the sink in logs
+func (s *JSONLSink) Name() string {
+	return SinkJSONL + ":" + s.path
+}
+
+// Write appends the records
+func (s *JSONLSink) Write(data []KeywordMetricsData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		file, _, err := appendFile(s.path)
+		if err != nil {
+			return err
+		}
+		s.file = file
+	}
+	writer := bufio.NewWriter(s.file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range data {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+	}
+	return writer.Flush()
+}
+
+// WriteDelistings appends delisting notices to the file DelistingPath names
+func (s *JSONLSink) WriteDelistings(events []ChangeEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.delistingFile == nil {
+		file, _, err := appendFile(DelistingPath(s.path))
+		if err != nil {
+			return err
+		}
+		s.delistingFile = file
+	}
+	writer := bufio.NewWriter(s.delistingFile)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode delisting notice: %w", err)
+		}
+	}
+	return writer.Flush()
+}
+
+// Close closes the files
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	if s.file != nil {
+		err = s.file.Close()
+		s.file = nil
+	}
+	if s.delistingFile != nil {
+		if closeErr := s.delistingFile.Close(); err == nil {
+			err = closeErr
+		}
+		s.delistingFile = nil
+	}
+	return err
+}
+
+// DelistingPath returns the file a file sink writing to path keeps delisting notices in,
+// e.g. out/metrics.delistings.jsonl for out/metrics.jsonl
+func DelistingPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".delistings" + ext
+}
+
+// csvHeader lists the flattened columns written by CSVSink
+var csvHeader = []string{
+	"keyword", "url", "locale", "metrics_date",
+	"avg_monthly_searches", "latest_searches", "max_monthly_searches",
+	"competition", "competition_index", "low_top_of_page_bid_micro", "high_top_of_page_bid_micro",
+	"data_quality", "run_id", "idempotency_key",
+}
+
+// csvDelistingHeader lists the columns of the delisting notices written by CSVSink
+var csvDelistingHeader = []string{
+	"url", "domain", "sitemap_url", "change_type", "keyword",
+	"detected_at", "first_seen", "last_seen", "run_id", "idempotency_key",
+}
+
+// CSVSink appends flattened records to a local CSV file, writing a header to new files
+// Monthly series are left out; use the JSONL sink when the full history is needed.
+// Delisting notices go to a sibling file, see DelistingPath
+type CSVSink struct {
+	path            string
+	file            *os.File
+	writer          *csv.Writer
+	delistingFile   *os.File
+	delistingWriter *csv.Writer
+	mu              sync.Mutex
+}
+
+// NewCSVSink creates a sink appending to path; the file is opened on first write
+func NewCSVSink(path string) *CSVSink {
+	return &CSVSink{path: path}
+}
+
+// Name identifies the sink in logs
+func (s *CSVSink) Name() string {
+	return SinkCSV + ":" + s.path
+}
+
+// Write appends the records
+func (s *CSVSink) Write(data []KeywordMetricsData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		file, empty, err := appendFile(s.path)
+		if err != nil {
+			return err
+		}
+		s.file = file
+		s.writer = csv.NewWriter(file)
+		if empty {
+			if err := s.writer.Write(csvHeader); err != nil {
+				return err
+			}
+		}
+	}
+	for _, record := range data {
+		metrics := record.Metrics
+		row := []string{
+			sanitizeCSVCell(record.Keyword), sanitizeCSVCell(record.URL), record.Locale, metrics.MetricsDate(),
+			strconv.FormatInt(metrics.AvgMonthlySearches, 10),
+			strconv.FormatInt(metrics.LatestSearches, 10),
+			strconv.FormatInt(metrics.MaxMonthlySearches, 10),
+			metrics.Competition,
+			strconv.Itoa(metrics.CompetitionIndex),
+			strconv.FormatInt(metrics.LowTopOfPageBidMicro, 10),
+			strconv.FormatInt(metrics.HighTopOfPageBidMicro, 10),
+			metrics.DataQuality.Status,
+			record.RunID,
+			record.IdempotencyKey,
+		}
+		if err := s.writer.Write(row); err != nil {
+			return err
+		}
+	}
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// WriteDelistings appends delisting notices to the file DelistingPath names
+func (s *CSVSink) WriteDelistings(events []ChangeEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.delistingFile == nil {
+		file, empty, err := appendFile(DelistingPath(s.path))
+		if err != nil {
+			return err
+		}
+		s.delistingFile = file
+		s.delistingWriter = csv.NewWriter(file)
+		if empty {
+			if err := s.delistingWriter.Write(csvDelistingHeader); err != nil {
+				return err
+			}
+		}
+	}
+	for _, event := range events {
+		row := []string{
+			sanitizeCSVCell(event.URL), event.Domain, sanitizeCSVCell(event.SitemapURL), event.ChangeType,
+			sanitizeCSVCell(event.Keyword),
+			event.DetectedAt.Format(time.RFC3339),
+			formatCSVTime(event.FirstSeen),
+			formatCSVTime(event.LastSeen),
+			event.RunID,
+			event.IdempotencyKey,
+		}
+		if err := s.delistingWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	s.delistingWriter.Flush()
+	return s.delistingWriter.Error()
+}
+
+// Close flushes and closes the files
+func (s *CSVSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	if s.file != nil {
+		s.writer.Flush()
+		err = s.writer.Error()
+		if closeErr := s.file.Close(); err == nil {
+			err = closeErr
+		}
+		s.file = nil
+	}
+	if s.delistingFile != nil {
+		s.delistingWriter.Flush()
+		if flushErr := s.delistingWriter.Error(); err == nil {
+			err = flushErr
+		}
+		if closeErr := s.delistingFile.Close(); err == nil {
+			err = closeErr
+		}
+		s.delistingFile = nil
+	}
+	return err
+}
+
+// formatCSVTime writes an optional time as RFC 3339, empty when unset
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// sanitizeCSVCell stops spreadsheet apps from evaluating text scraped from third-party sites
+func sanitizeCSVCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
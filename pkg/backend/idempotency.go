@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IdempotencyHeader carries the batch key so the backend can drop resent batches
+const IdempotencyHeader = "Idempotency-Key"
+
+// NewRunID returns a sortable, unique identifier for one monitoring run
+func NewRunID() string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000Z")
+	}
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// MetricsDate returns the latest month covered by the metrics ("2024-05"), or "" without monthly data
+func (m MetricsData) MetricsDate() string {
+	latest := ""
+	for _, month := range m.MonthlySearches {
+		year, err1 := toInt(month.Year)
+		monthNum, err2 := toInt(month.Month)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if date := fmt.Sprintf("%04d-%02d", year, monthNum); date > latest {
+			latest = date
+		}
+	}
+	return latest
+}
+
+// RecordIdempotencyKey derives a record's key from keyword, URL, locale, metrics date and run ID
+// The same data from the same run always maps to the same key, however often it is sent
+func RecordIdempotencyKey(record KeywordMetricsData) string {
+	return hashKey(
+		strings.ToLower(strings.TrimSpace(record.Keyword)),
+		record.URL,
+		record.Locale,
+		record.Metrics.MetricsDate(),
+		record.RunID,
+	)
+}
+
+// BatchIdempotencyKey derives a batch's key from its record keys, independent of record order
+func BatchIdempotencyKey(batch KeywordMetricsBatch) string {
+	keys := make([]string, len(batch))
+	for i, record := range batch {
+		keys[i] = record.IdempotencyKey
+		if keys[i] == "" {
+			keys[i] = RecordIdempotencyKey(record)
+		}
+	}
+	sort.Strings(keys)
+	return hashKey(keys...)
+}
+
+// AssignIdempotencyKeys stamps the run ID and record key on records that do not carry them yet
+// Records keep keys assigned earlier, so persisted or retried data is resent under its original key
+func AssignIdempotencyKeys(data []KeywordMetricsData, runID string) {
+	for i := range data {
+		if data[i].IdempotencyKey != "" {
+			continue
+		}
+		if data[i].RunID == "" {
+			data[i].RunID = runID
+		}
+		data[i].IdempotencyKey = RecordIdempotencyKey(data[i])
+	}
+}
+
+// toInt reads a year or month that may arrive as a string or a JSON number
+func toInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(strings.TrimSpace(v))
+	default:
+		return 0, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+func hashKey(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(hash[:16])
+}
@@ -0,0 +1,48 @@
+package backend_test
+
+import (
+	"testing"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/mock"
+)
+
+func TestSubmitBatches_ResendIsIdempotent(t *testing.T) {
+	server, err := mock.NewBackendServer("", "", mock.Behavior{Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+
+	client, err := backend.NewBackendClient(backend.BackendConfig{
+		BaseURL:   server.URL(),
+		APIKey:    "any",
+		BatchSize: 3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create backend client: %v", err)
+	}
+
+	data := []backend.KeywordMetricsData{
+		{Keyword: "one", URL: "https://example.com/one"},
+		{Keyword: "two", URL: "https://example.com/two"},
+		{Keyword: "three", URL: "https://example.com/three"},
+	}
+	backend.AssignIdempotencyKeys(data, "run-1")
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err := client.SubmitBatches(data); err != nil {
+			t.Fatalf("Submission %d failed: %v", attempt+1, err)
+		}
+	}
+
+	if got := len(server.Stored()); got != 3 {
+		t.Errorf("Expected resent records to be stored once, got %d", got)
+	}
+	requests := server.Requests()
+	if len(requests) != 2 || requests[1].StatusCode != 409 || requests[0].Header.Get(backend.IdempotencyHeader) != requests[1].Header.Get(backend.IdempotencyHeader) {
+		t.Errorf("Expected the resend to reuse the batch key and be answered 409, got %+v", requests)
+	}
+	if stored := server.Stored()[0]; stored.RunID != "run-1" || stored.IdempotencyKey == "" || stored.BatchID == "" {
+		t.Errorf("Expected idempotency fields on stored record, got %+v", stored)
+	}
+}
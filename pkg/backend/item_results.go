@@ -0,0 +1,90 @@
+package backend
+
+import "net/http"
+
+// Per-item outcomes a backend reports in BatchResult.Items
+const (
+	ItemAccepted  = "accepted"
+	ItemRejected  = "rejected"  // Failed validation; resending the same record will not help
+	ItemDuplicate = "duplicate" // Already stored under the same idempotency key
+)
+
+// ItemResult is the backend's verdict on one record, matched on its idempotency key
+type ItemResult struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	Status         string `json:"status"`
+	Reason         string `json:"reason,omitempty"` // Why a record was rejected
+}
+
+// BatchResult is the data of a batch response
+// Backends that list no items are taken to have accepted every record they did not mention
+type BatchResult struct {
+	Received int          `json:"received"`
+	Accepted int          `json:"accepted"`
+	Items    []ItemResult `json:"items,omitempty"`
+}
+
+// Count returns how many items carry the given status
+func (r *BatchResult) Count(status string) int {
+	count := 0
+	for _, item := range r.Items {
+		if item.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// RejectedRecord is a record the backend refused, with its reason
+type RejectedRecord struct {
+	Record KeywordMetricsData
+	Reason string
+}
+
+// SubmissionResult tallies per-item outcomes of one or more batches
+type SubmissionResult struct {
+	Accepted   int
+	Duplicates int
+	Rejected   []RejectedRecord
+}
+
+// Merge adds the outcomes of other to r
+func (r *SubmissionResult) Merge(other SubmissionResult) {
+	r.Accepted += other.Accepted
+	r.Duplicates += other.Duplicates
+	r.Rejected = append(r.Rejected, other.Rejected...)
+}
+
+// ResolveItemResults maps a successful batch response back onto the records sent
+// A 409 or 208 means the whole batch was already stored; records the response does not
+// mention, or mentions with an unknown status, count as accepted
+func ResolveItemResults(batch KeywordMetricsBatch, resp *BackendResponse) SubmissionResult {
+	var result SubmissionResult
+	if resp != nil && (resp.Code == http.StatusConflict || resp.Code == http.StatusAlreadyReported) {
+		result.Duplicates = len(batch)
+		return result
+	}
+
+	items := make(map[string]ItemResult)
+	if resp != nil && resp.Data != nil {
+		for _, item := range resp.Data.Items {
+			items[item.IdempotencyKey] = item
+		}
+	}
+	for _, record := range batch {
+		key := record.IdempotencyKey
+		if key == "" {
+			key = RecordIdempotencyKey(record)
+		}
+		item := items[key]
+		switch item.Status {
+		case ItemRejected:
+			result.Rejected = append(result.Rejected, RejectedRecord{Record: record, Reason: item.Reason})
+		case ItemDuplicate:
+			result.Duplicates++
+		default:
+			result.Accepted++
+		}
+	}
+	return result
+}
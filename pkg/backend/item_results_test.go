@@ -0,0 +1,53 @@
+package backend_test
+
+import (
+	"context"
+	"testing"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/mock"
+	"sitemap-go/pkg/storage"
+)
+
+func TestSubmissionPool_DeadLettersRejectedRecords(t *testing.T) {
+	server, err := mock.NewBackendServer("", "", mock.Behavior{Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+
+	client, err := backend.NewBackendClient(backend.BackendConfig{BaseURL: server.URL(), APIKey: "any", BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create backend client: %v", err)
+	}
+	deadLetters := storage.NewDeadLetterStore(storage.NewMemoryStorage())
+	pool := backend.NewSubmissionPool(client, 1)
+	pool.SetDeadLetters(deadLetters)
+	ctx := context.Background()
+	pool.Start(ctx)
+	defer pool.Stop()
+
+	data := []backend.KeywordMetricsData{
+		{Keyword: "one", URL: "https://example.com/one"},
+		{Keyword: "no url"},
+		{Keyword: "three", URL: "https://example.com/three"},
+	}
+	if !pool.Submit(data, nil) || !pool.WaitIdle(ctx) {
+		t.Fatal("Expected the submission to be queued and finished")
+	}
+	if !pool.Submit(data[:1], nil) || !pool.WaitIdle(ctx) {
+		t.Fatal("Expected the resubmission to be queued and finished")
+	}
+
+	stats := pool.GetStats()
+	if stats.AcceptedItems != 2 || stats.RejectedItems != 1 || stats.DuplicateItems != 1 || stats.CompletedTasks != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	letters, err := deadLetters.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(letters) != 1 || letters[0].Keyword != "no url" || letters[0].Reason == "" || letters[0].Key == "" {
+		t.Errorf("Expected the rejected record as a dead letter, got %+v", letters)
+	}
+}
@@ -0,0 +1,40 @@
+package backend
+
+import "sync"
+
+// RecordingClient accepts every submission without sending it anywhere
+// Dry runs use it to report what would have reached the backend
+type RecordingClient struct {
+	mu      sync.Mutex
+	records []KeywordMetricsData
+	events  []ChangeEvent
+}
+
+// NewRecordingClient creates an empty recording client
+func NewRecordingClient() *RecordingClient {
+	return &RecordingClient{}
+}
+
+// SubmitBatch records one batch
+func (c *RecordingClient) SubmitBatch(batch KeywordMetricsBatch) (*BackendResponse, error) {
+	result, _ := c.SubmitBatches(batch)
+	return &BackendResponse{Code: 200, Message: "recorded", Data: &BatchResult{Received: len(batch), Accepted: result.Accepted}}, nil
+}
+
+// SubmitBatches records data, keyed the way a real submission would be
+func (c *RecordingClient) SubmitBatches(data []KeywordMetricsData) (SubmissionResult, error) {
+	batch := append([]KeywordMetricsData(nil), data...)
+	AssignIdempotencyKeys(batch, "")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, batch...)
+	return SubmissionResult{Accepted: len(batch)}, nil
+}
+
+// Records returns everything submitted so far
+func (c *RecordingClient) Records() []KeywordMetricsData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]KeywordMetricsData(nil), c.records...)
+}
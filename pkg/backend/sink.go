@@ -0,0 +1,311 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sitemap-go/pkg/logger"
+)
+
+// Sink receives keyword metrics; implementations must be safe for concurrent use
+type Sink interface {
+	Name() string
+	Write(data []KeywordMetricsData) error
+	Close() error
+}
+
+// Sink types accepted by ParseSinkList
+const (
+	SinkBackend = "backend"
+	SinkJSONL   = "jsonl"
+	SinkCSV     = "csv"
+	SinkWebhook = "webhook"
+)
+
+// Failure policies decide what a sink error means for the whole submission
+const (
+	FailureRetry = "retry" // Fail the submission so the outbox keeps it for a later replay
+	FailureDrop  = "drop"  // Log the error and let the submission succeed without this sink
+)
+
+// SinkSpec configures one output
+type SinkSpec struct {
+	Type      string `json:"type"`
+	Target    string `json:"target,omitempty"`     // File path or webhook URL; unused by the backend sink
+	BatchSize int    `json:"batch_size,omitempty"` // Records per Write, 0 = whole submission
+	OnFailure string `json:"on_failure"`
+}
+
+// ParseSinkList parses a comma-separated sink list, each entry optionally followed by options:
+//
+//	backend,jsonl:out/metrics.jsonl;on-failure=drop,webhook:https://hooks.example.com/x;batch=50
+//
+// Remote sinks default to the retry policy, local files to drop
+func ParseSinkList(raw string) ([]SinkSpec, error) {
+	var specs []SinkSpec
+	for i, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ";")
+		kind, target, _ := strings.Cut(strings.TrimSpace(parts[0]), ":")
+		spec := SinkSpec{Type: strings.ToLower(strings.TrimSpace(kind)), Target: strings.TrimSpace(target)}
+
+		switch spec.Type {
+		case SinkBackend:
+			spec.OnFailure = FailureRetry
+		case SinkWebhook:
+			spec.OnFailure = FailureRetry
+			if !strings.HasPrefix(spec.Target, "http://") && !strings.HasPrefix(spec.Target, "https://") {
+				return nil, fmt.Errorf("sink #%d: webhook needs an http(s) URL, e.g. webhook:https://hooks.example.com/x", i+1)
+			}
+		case SinkJSONL, SinkCSV:
+			spec.OnFailure = FailureDrop
+			if spec.Target == "" {
+				return nil, fmt.Errorf("sink #%d: %s needs a file path, e.g. %s:out/metrics.%s", i+1, spec.Type, spec.Type, spec.Type)
+			}
+		default:
+			return nil, fmt.Errorf("sink #%d: unknown type %q (use backend, jsonl, csv or webhook)", i+1, kind)
+		}
+
+		for _, option := range parts[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(option), "=")
+			if !found {
+				return nil, fmt.Errorf("sink #%d: option %q must be key=value", i+1, option)
+			}
+			value = strings.TrimSpace(value)
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "batch", "batch_size":
+				size, err := strconv.Atoi(value)
+				if err != nil || size <= 0 {
+					return nil, fmt.Errorf("sink #%d: batch must be a positive integer, got %q", i+1, value)
+				}
+				spec.BatchSize = size
+			case "on-failure", "on_failure":
+				if value != FailureRetry && value != FailureDrop {
+					return nil, fmt.Errorf("sink #%d: on-failure must be %s or %s, got %q", i+1, FailureRetry, FailureDrop, value)
+				}
+				spec.OnFailure = value
+			default:
+				return nil, fmt.Errorf("sink #%d: unknown option %q", i+1, key)
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// HasSink reports whether specs include a sink of the given type
+func HasSink(specs []SinkSpec, sinkType string) bool {
+	for _, spec := range specs {
+		if spec.Type == sinkType {
+			return true
+		}
+	}
+	return false
+}
+
+// SinkOptions holds settings shared by sinks of one kind
+type SinkOptions struct {
+	Backend       BackendConfig // Used by the backend sink
+	WebhookSecret string        // HMAC key for webhook signatures; empty sends unsigned requests
+}
+
+// NewSink creates the sink described by spec
+func NewSink(spec SinkSpec, options SinkOptions) (Sink, error) {
+	switch spec.Type {
+	case SinkBackend:
+		client, err := NewBackendClient(options.Backend)
+		if err != nil {
+			return nil, err
+		}
+		return &backendSink{client: client}, nil
+	case SinkJSONL:
+		return NewJSONLSink(spec.Target), nil
+	case SinkCSV:
+		return NewCSVSink(spec.Target), nil
+	case SinkWebhook:
+		return NewWebhookSink(spec.Target, options.WebhookSecret, options.Backend.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", spec.Type)
+	}
+}
+
+// resultSink is implemented by sinks that learn per-item outcomes from their receiver
+type resultSink interface {
+	writeWithResult(data []KeywordMetricsData) (SubmissionResult, error)
+}
+
+// backendSink adapts the backend client, which batches and keys requests itself
+type backendSink struct {
+	client BackendClient
+}
+
+func (s *backendSink) Name() string { return SinkBackend }
+func (s *backendSink) Close() error { return nil }
+
+func (s *backendSink) Write(data []KeywordMetricsData) error {
+	_, err := s.client.SubmitBatches(data)
+	return err
+}
+
+func (s *backendSink) writeWithResult(data []KeywordMetricsData) (SubmissionResult, error) {
+	return s.client.SubmitBatches(data)
+}
+
+// configuredSink pairs a sink with its batching and failure policy
+type configuredSink struct {
+	sink Sink
+	spec SinkSpec
+}
+
+// FanOutClient delivers every submission to several sinks concurrently
+// It implements BackendClient so the submission pool and outbox work unchanged. A submission
+// fails only when a retry-policy sink fails; its outbox replay goes to every sink again, so
+// file sinks can see a record twice and should be deduplicated on idempotency_key.
+// Per-item results come from the backend sink; without one every record counts as accepted
+type FanOutClient struct {
+	sinks []configuredSink
+	log   *logger.Logger
+}
+
+// NewFanOutClient creates the sinks described by specs
+func NewFanOutClient(specs []SinkSpec, options SinkOptions) (*FanOutClient, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("at least one output sink is required")
+	}
+	client := &FanOutClient{log: logger.GetLogger().WithField("component", "sink_fanout")}
+	for _, spec := range specs {
+		sink, err := NewSink(spec, options)
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to create %s sink: %w", spec.Type, err)
+		}
+		client.sinks = append(client.sinks, configuredSink{sink: sink, spec: spec})
+	}
+	return client, nil
+}
+
+// SubmitBatch delivers one batch to every sink
+func (f *FanOutClient) SubmitBatch(batch KeywordMetricsBatch) (*BackendResponse, error) {
+	result, err := f.SubmitBatches(batch)
+	if err != nil {
+		return nil, err
+	}
+	data := &BatchResult{Received: len(batch), Accepted: result.Accepted}
+	for _, rejected := range result.Rejected {
+		data.Items = append(data.Items, ItemResult{IdempotencyKey: rejected.Record.IdempotencyKey, Status: ItemRejected, Reason: rejected.Reason})
+	}
+	return &BackendResponse{Code: 200, Message: "ok", Data: data}, nil
+}
+
+// SubmitBatches delivers data to every sink in parallel, each in its own batch size
+func (f *FanOutClient) SubmitBatches(data []KeywordMetricsData) (SubmissionResult, error) {
+	if len(data) == 0 {
+		return SubmissionResult{}, nil
+	}
+	// Key a copy so the caller's records are left as they were handed in
+	data = append([]KeywordMetricsData(nil), data...)
+	AssignIdempotencyKeys(data, "")
+
+	errs := make([]error, len(f.sinks))
+	results := make([]*SubmissionResult, len(f.sinks))
+	var wg sync.WaitGroup
+	for i, configured := range f.sinks {
+		wg.Add(1)
+		go func(i int, configured configuredSink) {
+			defer wg.Done()
+			results[i], errs[i] = writeInBatches(configured, data)
+		}(i, configured)
+	}
+	wg.Wait()
+
+	result := SubmissionResult{Accepted: len(data)}
+	for _, reported := range results {
+		if reported != nil {
+			result = *reported
+			break
+		}
+	}
+
+	var failed []string
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		configured := f.sinks[i]
+		entry := f.log.WithError(err).WithFields(map[string]interface{}{
+			"sink":       configured.sink.Name(),
+			"on_failure": configured.spec.OnFailure,
+			"records":    len(data),
+		})
+		if configured.spec.OnFailure == FailureDrop {
+			entry.Warn("Sink write failed, dropping records for this sink")
+			continue
+		}
+		entry.Error("Sink write failed")
+		failed = append(failed, fmt.Sprintf("%s: %v", configured.sink.Name(), err))
+	}
+	if len(failed) > 0 {
+		return result, fmt.Errorf("sink delivery failed (%s)", strings.Join(failed, "; "))
+	}
+	return result, nil
+}
+
+// Close flushes and closes every sink
+func (f *FanOutClient) Close() error {
+	var failed []string
+	for _, configured := range f.sinks {
+		if err := configured.sink.Close(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", configured.sink.Name(), err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to close sinks (%s)", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Names returns the configured sink names in order
+func (f *FanOutClient) Names() []string {
+	names := make([]string, len(f.sinks))
+	for i, configured := range f.sinks {
+		names[i] = configured.sink.Name()
+	}
+	return names
+}
+
+// writeInBatches writes data in the sink's batch size; the result is nil unless the sink
+// reports per-item outcomes
+func writeInBatches(configured configuredSink, data []KeywordMetricsData) (*SubmissionResult, error) {
+	size := configured.spec.BatchSize
+	if size <= 0 {
+		size = len(data)
+	}
+	reporter, reports := configured.sink.(resultSink)
+	var result *SubmissionResult
+	if reports {
+		result = &SubmissionResult{}
+	}
+	for start := 0; start < len(data); start += size {
+		end := start + size
+		if end > len(data) {
+			end = len(data)
+		}
+		if !reports {
+			if err := configured.sink.Write(data[start:end]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		batchResult, err := reporter.writeWithResult(data[start:end])
+		result.Merge(batchResult)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package backend_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/mock"
+)
+
+func TestFanOutClient_WritesEverySink(t *testing.T) {
+	server, err := mock.NewBackendServer("", "", mock.Behavior{Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+
+	var signatureOK bool
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := "sha256=" + backend.SignWebhookPayload([]byte("secret"), r.Header.Get(backend.WebhookTimestampHeader), body)
+		signatureOK = r.Header.Get(backend.WebhookSignatureHeader) == expected
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer webhook.Close()
+
+	dir := t.TempDir()
+	specs, err := backend.ParseSinkList("backend,jsonl:" + filepath.Join(dir, "out.jsonl") + ",csv:" + filepath.Join(dir, "out.csv") +
+		",webhook:" + webhook.URL + ";batch=10,webhook:http://127.0.0.1:1/unreachable;on-failure=drop")
+	if err != nil {
+		t.Fatalf("ParseSinkList failed: %v", err)
+	}
+	client, err := backend.NewFanOutClient(specs, backend.SinkOptions{
+		Backend:       backend.BackendConfig{BaseURL: server.URL(), APIKey: "any"},
+		WebhookSecret: "secret",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create fan-out client: %v", err)
+	}
+
+	data := []backend.KeywordMetricsData{
+		{Keyword: "one", URL: "https://example.com/one"},
+		{Keyword: "=cmd", URL: "https://example.com/two"},
+	}
+	if _, err := client.SubmitBatches(data); err != nil {
+		t.Fatalf("Expected the failing drop-policy sink to be ignored, got %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if data[0].IdempotencyKey != "" {
+		t.Errorf("Expected the caller's records to be left unkeyed, got %+v", data[0])
+	}
+	if got := len(server.Stored()); got != 2 {
+		t.Errorf("Expected 2 records at the backend, got %d", got)
+	}
+	if !signatureOK {
+		t.Error("Expected a valid webhook signature")
+	}
+	if lines := readLines(t, filepath.Join(dir, "out.jsonl")); len(lines) != 2 {
+		t.Errorf("Expected 2 JSONL lines, got %d", len(lines))
+	}
+	lines := readLines(t, filepath.Join(dir, "out.csv"))
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "keyword,url,") || !strings.HasPrefix(lines[2], "'=cmd,") {
+		t.Errorf("Expected a header and 2 sanitized CSV rows, got %q", lines)
+	}
+}
+
+func TestFanOutClient_WritesDelistingsToFileSinks(t *testing.T) {
+	dir := t.TempDir()
+	specs, err := backend.ParseSinkList("jsonl:" + filepath.Join(dir, "out.jsonl") + ",csv:" + filepath.Join(dir, "out.csv"))
+	if err != nil {
+		t.Fatalf("ParseSinkList failed: %v", err)
+	}
+	client, err := backend.NewFanOutClient(specs, backend.SinkOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create fan-out client: %v", err)
+	}
+
+	lastSeen := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	events := []backend.ChangeEvent{
+		{URL: "https://poki.com/g/moto-x3m", ChangeType: backend.ChangeAdded, RunID: "run-1"},
+		{URL: "https://poki.com/g/temple-run", Keyword: "=temple run", ChangeType: backend.ChangeDelisted, LastSeen: &lastSeen, RunID: "run-1"},
+		{URL: "https://poki.com/g/subway-surfers", ChangeType: backend.ChangeRelisted, RunID: "run-1"},
+	}
+	if err := client.SubmitChangeEvents(events); err != nil {
+		t.Fatalf("SubmitChangeEvents failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if lines := readLines(t, filepath.Join(dir, "out.delistings.jsonl")); len(lines) != 2 {
+		t.Errorf("Expected 2 JSONL delisting notices, got %d", len(lines))
+	}
+	lines := readLines(t, filepath.Join(dir, "out.delistings.csv"))
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "url,domain,") {
+		t.Fatalf("Expected a header and 2 CSV delisting notices, got %q", lines)
+	}
+	if !strings.Contains(lines[1], ",delisted,'=temple run,") || !strings.Contains(lines[1], ",2026-10-17T08:00:00Z,run-1,") {
+		t.Errorf("Unexpected delisting row %q", lines[1])
+	}
+	if !strings.Contains(lines[2], ",relisted,") {
+		t.Errorf("Unexpected relisting row %q", lines[2])
+	}
+}
+
+func TestParseSinkList_RejectsBadEntries(t *testing.T) {
+	for _, raw := range []string{"s3:bucket", "jsonl", "webhook:ftp://x", "csv:a.csv;batch=0", "backend;on-failure=maybe"} {
+		if _, err := backend.ParseSinkList(raw); err == nil {
+			t.Errorf("Expected %q to be rejected", raw)
+		}
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
@@ -0,0 +1,365 @@
+package backend
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"sitemap-go/pkg/logger"
+)
+
+// NDJSONContentType is the body type of streamed submissions, one JSON record per line
+const NDJSONContentType = "application/x-ndjson"
+
+const (
+	defaultStreamMaxBytes      = 5 << 20 // 5 MiB request body
+	defaultStreamFlushInterval = 30 * time.Second
+	gzipOverheadBytes          = 64 // Header, trailer and final block written by Close
+)
+
+// StreamConfig tunes NDJSON streaming
+type StreamConfig struct {
+	MaxBytes      int           `json:"max_bytes"`      // Request body limit after compression (default 5 MiB)
+	FlushInterval time.Duration `json:"flush_interval"` // Longest a request stays open waiting for records (default 30s)
+}
+
+// StreamSubmitter writes records into an open NDJSON request as they arrive, instead of
+// collecting them and marshaling one array per batch. A request ends when the next record
+// would push its body over MaxBytes, when FlushInterval passes, or on Flush
+//
+// Only the records of in-flight requests are kept in memory. If a request fails they are
+// handed to the fallback pool, which persists them in its outbox and resends them as
+// regular batches; per-item results are counted in the fallback pool's stats
+type StreamSubmitter struct {
+	config   BackendConfig
+	stream   StreamConfig
+	client   *fasthttp.Client
+	fallback *SubmissionPool
+	auth     *requestAuth
+	streamID string // Random per submitter, so request keys never collide across runs
+	log      *logger.Logger
+
+	mu      sync.Mutex
+	current *streamRequest
+	runID   string
+	seq     int
+	wg      sync.WaitGroup
+}
+
+// streamRequest is one open request; the writer side is guarded by StreamSubmitter.mu
+type streamRequest struct {
+	id      int
+	key     string // Idempotency key of the request, unique per submitter and request
+	pipe    *io.PipeWriter
+	counter *countingWriter
+	gz      *gzip.Writer // nil without compression
+	records []KeywordMetricsData
+	pending int // Bytes written to gz since its last flush
+	timer   *time.Timer
+	closed  chan struct{} // Closed once no more records will be written
+}
+
+// NewStreamSubmitter creates a streaming submitter; fallback may be nil, in which case
+// records of failed requests are only logged
+func NewStreamSubmitter(config BackendConfig, stream StreamConfig, fallback *SubmissionPool) (*StreamSubmitter, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("backend URL is required for stream submission")
+	}
+	auth, err := newRequestAuth(config)
+	if err != nil {
+		return nil, err
+	}
+	if auth.signsBody() {
+		return nil, fmt.Errorf("%s auth signs the complete body and cannot be used with stream submission", AuthHMAC)
+	}
+	tlsConfig, err := config.TLS.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid backend TLS configuration: %w", err)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 60 * time.Second
+	}
+	if stream.MaxBytes <= 0 {
+		stream.MaxBytes = defaultStreamMaxBytes
+	}
+	if stream.FlushInterval <= 0 {
+		stream.FlushInterval = defaultStreamFlushInterval
+	}
+
+	return &StreamSubmitter{
+		config: config,
+		stream: stream,
+		client: &fasthttp.Client{
+			ReadTimeout: config.Timeout,
+			// The write deadline covers the whole body, which stays open up to FlushInterval
+			WriteTimeout:        stream.FlushInterval + config.Timeout,
+			MaxConnsPerHost:     4,
+			MaxIdleConnDuration: 30 * time.Second,
+			// Wait for a connection rather than fail: a waiting request blocks Add, which
+			// slows the producer down to what the backend can take
+			MaxConnWaitTimeout: stream.FlushInterval + config.Timeout,
+			TLSConfig:          tlsConfig,
+		},
+		fallback: fallback,
+		auth:     auth,
+		streamID: NewRunID(),
+		log:      logger.GetLogger().WithField("component", "stream_submitter"),
+	}, nil
+}
+
+// SetRunID sets the run ID used for idempotency keys of records added from now on
+func (s *StreamSubmitter) SetRunID(runID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runID = runID
+}
+
+// Add streams records into the open request, starting new requests as limits are reached
+// It blocks while the backend reads slower than records arrive
+func (s *StreamSubmitter) Add(records ...KeywordMetricsData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	AssignIdempotencyKeys(records, s.runID)
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			s.log.WithError(err).WithField("keyword", record.Keyword).Warn("Skipping record that cannot be encoded")
+			continue
+		}
+		line = append(line, '\n')
+		s.writeLocked(record, line)
+	}
+}
+
+// writeLocked appends one encoded record, moving to a new request when the body limit is near
+// A record that does not fit an empty request is sent alone
+func (s *StreamSubmitter) writeLocked(record KeywordMetricsData, line []byte) {
+	for attempt := 0; attempt < 2; attempt++ {
+		req := s.current
+		if req != nil && s.wouldExceed(req, len(line)) {
+			s.closeLocked()
+			req = nil
+		}
+		if req == nil {
+			req = s.openLocked()
+		}
+		if len(req.records) == 0 && s.wouldExceed(req, len(line)) {
+			s.log.WithField("record_bytes", len(line)).Warn("Record exceeds the stream request limit, sending it alone")
+		}
+
+		var err error
+		if req.gz != nil {
+			_, err = req.gz.Write(line)
+			req.pending += len(line)
+		} else {
+			_, err = req.counter.Write(line)
+		}
+		if err == nil {
+			req.records = append(req.records, record)
+			return
+		}
+		// The request already failed and its records go to the fallback; retry in a fresh one
+		s.log.WithError(err).WithField("request", req.id).Debug("Stream request closed early")
+		s.closeLocked()
+	}
+	if s.fallback != nil {
+		s.fallback.Submit([]KeywordMetricsData{record}, nil)
+	}
+}
+
+// wouldExceed reports whether adding n bytes may take the body past MaxBytes
+// Compressed output is measured exactly after a sync flush, which is only done near the limit
+func (s *StreamSubmitter) wouldExceed(req *streamRequest, n int) bool {
+	if req.gz == nil {
+		return req.counter.n+int64(n) > int64(s.stream.MaxBytes)
+	}
+	limit := int64(s.stream.MaxBytes - gzipOverheadBytes)
+	if req.counter.n+int64(req.pending+n) <= limit {
+		return false
+	}
+	if req.pending > 0 {
+		if err := req.gz.Flush(); err != nil {
+			return true
+		}
+		req.pending = 0
+	}
+	return req.counter.n+int64(n) > limit
+}
+
+// openLocked starts a request whose body is fed through a pipe
+func (s *StreamSubmitter) openLocked() *streamRequest {
+	s.seq++
+	reader, writer := io.Pipe()
+	req := &streamRequest{
+		id:      s.seq,
+		key:     hashKey(s.streamID, strconv.Itoa(s.seq)),
+		pipe:    writer,
+		counter: &countingWriter{w: writer},
+		closed:  make(chan struct{}),
+	}
+	if s.config.EnableGzip {
+		req.gz = gzip.NewWriter(req.counter)
+	}
+	req.timer = time.AfterFunc(s.stream.FlushInterval, func() { s.closeIfCurrent(req) })
+	s.current = req
+
+	s.wg.Add(1)
+	go s.send(req, reader)
+	return req
+}
+
+// closeLocked ends the body of the current request
+func (s *StreamSubmitter) closeLocked() {
+	req := s.current
+	if req == nil {
+		return
+	}
+	s.current = nil
+	req.timer.Stop()
+	var err error
+	if req.gz != nil {
+		err = req.gz.Close()
+	}
+	req.pipe.CloseWithError(err)
+	close(req.closed)
+}
+
+func (s *StreamSubmitter) closeIfCurrent(req *streamRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == req {
+		s.closeLocked()
+	}
+}
+
+// send runs one request and settles its records once the body is complete
+func (s *StreamSubmitter) send(req *streamRequest, body *io.PipeReader) {
+	defer s.wg.Done()
+
+	httpReq := fasthttp.AcquireRequest()
+	httpResp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(httpReq)
+	defer fasthttp.ReleaseResponse(httpResp)
+
+	httpReq.SetRequestURI(s.config.BaseURL + "/api/v1/keyword-metrics/batch")
+	httpReq.Header.SetMethod(fasthttp.MethodPost)
+	httpReq.Header.SetContentType(NDJSONContentType)
+	httpReq.Header.Set(IdempotencyHeader, req.key)
+	if s.config.EnableGzip {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	httpReq.SetBodyStream(body, -1)
+
+	err := s.auth.apply(httpReq)
+	if err == nil {
+		err = s.client.Do(httpReq, httpResp)
+	}
+	// Unblock a writer still feeding a request the client gave up on
+	body.CloseWithError(io.ErrClosedPipe)
+	<-req.closed
+
+	var backendResp BackendResponse
+	if err == nil {
+		err = parseStreamResponse(httpResp, &backendResp)
+	}
+	if err != nil {
+		s.handleFailure(req, err)
+		return
+	}
+
+	result := ResolveItemResults(req.records, &backendResp)
+	if s.fallback != nil {
+		s.fallback.recordItemResults(result)
+	}
+	s.log.WithFields(map[string]interface{}{
+		"request":    req.id,
+		"records":    len(req.records),
+		"body_bytes": req.counter.n,
+		"rejected":   len(result.Rejected),
+	}).Info("Stream submission completed")
+}
+
+func parseStreamResponse(resp *fasthttp.Response, backendResp *BackendResponse) error {
+	status := resp.StatusCode()
+	if status == fasthttp.StatusConflict || status == fasthttp.StatusAlreadyReported {
+		// The backend already has this request from an earlier send: the goal is reached
+		*backendResp = BackendResponse{Code: status, Message: "already accepted"}
+		return nil
+	}
+	if status != fasthttp.StatusOK && status != fasthttp.StatusAccepted {
+		return fmt.Errorf("Backend API returned status %d (response body hidden for security)", status)
+	}
+	if err := json.Unmarshal(resp.Body(), backendResp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// handleFailure passes the records of a failed request to the fallback pool
+func (s *StreamSubmitter) handleFailure(req *streamRequest, err error) {
+	entry := s.log.WithError(err).WithFields(map[string]interface{}{
+		"request": req.id,
+		"records": len(req.records),
+	})
+	if len(req.records) == 0 {
+		return
+	}
+	if s.fallback == nil {
+		entry.Error("Stream submission failed, records not resent")
+		return
+	}
+	entry.Warn("Stream submission failed, resending records as batches")
+	if !s.fallback.Submit(req.records, nil) {
+		entry.Error("Fallback submission rejected records")
+	}
+}
+
+// Flush ends the open request so its records are sent now
+func (s *StreamSubmitter) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+}
+
+// Wait flushes and blocks until every request finished or ctx ends; it reports whether all finished
+func (s *StreamSubmitter) Wait(ctx context.Context) bool {
+	s.Flush()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close flushes and waits for in-flight requests
+func (s *StreamSubmitter) Close() error {
+	s.Flush()
+	s.wg.Wait()
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// countingWriter counts bytes that reach the request body
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
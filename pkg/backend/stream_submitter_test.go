@@ -0,0 +1,98 @@
+package backend_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/mock"
+)
+
+func TestStreamSubmitter_SplitsRequestsByBytes(t *testing.T) {
+	server, err := mock.NewBackendServer("", "", mock.Behavior{Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+
+	config := backend.BackendConfig{BaseURL: server.URL(), APIKey: "any", EnableGzip: true}
+	streamer, err := backend.NewStreamSubmitter(config, backend.StreamConfig{MaxBytes: 1024}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create stream submitter: %v", err)
+	}
+	streamer.SetRunID("run-1")
+	for i := 0; i < 200; i++ {
+		keyword := fmt.Sprintf("keyword %d %s", i, strings.Repeat("x", i%17))
+		streamer.Add(backend.KeywordMetricsData{Keyword: keyword, URL: "https://example.com/" + keyword})
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if got := len(server.Stored()); got != 200 {
+		t.Errorf("Expected 200 stored records, got %d", got)
+	}
+	requests := server.Requests()
+	if len(requests) < 2 {
+		t.Fatalf("Expected the byte limit to split the stream, got %d requests", len(requests))
+	}
+	keys := make(map[string]bool)
+	for _, request := range requests {
+		if request.ContentType != backend.NDJSONContentType || request.ContentEncoding != "gzip" {
+			t.Errorf("Expected gzipped NDJSON, got %q/%q", request.ContentType, request.ContentEncoding)
+		}
+		keys[request.Header.Get(backend.IdempotencyHeader)] = true
+	}
+	if len(keys) != len(requests) || keys[""] {
+		t.Errorf("Expected a distinct idempotency key per request, got %v", keys)
+	}
+}
+
+func TestStreamSubmitter_AlreadyAcceptedIsSuccess(t *testing.T) {
+	var mu sync.Mutex
+	var idempotencyKey string
+	conflict := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		idempotencyKey = r.Header.Get(backend.IdempotencyHeader)
+		mu.Unlock()
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer conflict.Close()
+
+	// Records resent through the fallback would reach the mock backend
+	fallbackServer, err := mock.NewBackendServer("", "", mock.Behavior{Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer fallbackServer.Close()
+	fallbackClient, err := backend.NewBackendClient(backend.BackendConfig{BaseURL: fallbackServer.URL(), APIKey: "any", BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create backend client: %v", err)
+	}
+	fallback := backend.NewSubmissionPool(fallbackClient, 1)
+	streamer, err := backend.NewStreamSubmitter(backend.BackendConfig{BaseURL: conflict.URL, APIKey: "any"}, backend.StreamConfig{}, fallback)
+	if err != nil {
+		t.Fatalf("Failed to create stream submitter: %v", err)
+	}
+	for _, keyword := range []string{"one", "two", "three"} {
+		streamer.Add(backend.KeywordMetricsData{Keyword: keyword, URL: "https://example.com/" + keyword})
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if idempotencyKey == "" {
+		t.Error("Expected the request to carry an idempotency key")
+	}
+	if stats := fallback.GetStats(); stats.DuplicateItems != 3 || stats.QueueDepth != 0 {
+		t.Errorf("Expected the records counted as duplicates and not resent, got %+v", stats)
+	}
+}
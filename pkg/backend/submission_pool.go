@@ -2,16 +2,54 @@ package backend
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"sitemap-go/pkg/logger"
+	"sitemap-go/pkg/storage"
 )
 
 // SubmissionTask represents a single submission task
 type SubmissionTask struct {
 	Data     []KeywordMetricsData
-	Callback func(error) // Optional callback for completion notification
+	Events   []ChangeEvent // Sitemap change events, sent instead of Data
+	Callback func(error)   // Optional callback for completion notification
+	OutboxID string        // Persisted copy to remove once the backend accepts the data
+	kind     string        // Outbox kind of the payload, kept while a spilled task has none in memory
+	queuedAt time.Time
+}
+
+// outboxKindChangeEvents tags outbox entries holding change events; keyword metrics use ""
+const outboxKindChangeEvents = "change_events"
+
+func (task SubmissionTask) size() int {
+	return len(task.Data) + len(task.Events)
+}
+
+var (
+	// ErrSubmissionQueueFull is returned when a task can neither be queued nor spilled
+	ErrSubmissionQueueFull = errors.New("submission queue is full")
+	// ErrSubmissionPoolStopped is returned for tasks submitted while the pool stops
+	ErrSubmissionPoolStopped = errors.New("submission pool is stopping")
+)
+
+const (
+	defaultAutoscaleInterval = 2 * time.Second
+	defaultLatencyTarget     = 10 * time.Second
+	autoscaleIdleTicks       = 3 // Empty-queue checks before an idle worker is retired
+)
+
+// AutoscaleConfig bounds how far the pool grows its workers when the queue backs up
+// Workers are only added while backend calls stay under LatencyTarget: a slow backend gets
+// fewer concurrent requests, not more. Beyond twice the target a worker is retired
+type AutoscaleConfig struct {
+	MinWorkers    int           `json:"min_workers"`
+	MaxWorkers    int           `json:"max_workers"`
+	LatencyTarget time.Duration `json:"latency_target"` // Default 10s
+	Interval      time.Duration `json:"interval"`       // Default 2s
 }
 
 // SubmissionPool manages non-blocking background submission
@@ -22,14 +60,58 @@ type SubmissionPool struct {
 	stopChannel  chan struct{}
 	wg           sync.WaitGroup
 	log          *logger.Logger
+	outbox       *storage.Outbox // Optional durable copy of every task
+	deadLetters  *storage.DeadLetterStore // Optional home for records the backend rejects
+	runID        string          // Stamped on submitted records for idempotency keys
+	startOnce    sync.Once
+	inFlight     map[string]bool // Outbox entries queued, spilled or being sent, skipped by replays
+	
+	// Overflow: with an outbox, tasks that find the queue full are spilled to disk and their
+	// data dropped from memory; a feeder reloads them as the queue drains
+	spilled     []SubmissionTask
+	spillSignal chan struct{}
+	
+	// Auto-scaling
+	autoscale  AutoscaleConfig
+	workers    int           // Running workers
+	nextWorker int           // ID of the next worker started
+	latency    time.Duration // Moving average of backend call duration
+	retire     chan struct{} // An idle worker receiving from it exits
 	
 	// Statistics
 	totalTasks     int64
 	completedTasks int64
 	failedTasks    int64
+	acceptedItems  int64
+	rejectedItems  int64
+	duplicateItems int64
+	changeEvents   int64 // Change events the backend accepted
+	pendingTasks   int64 // Queued or in progress
+	spilledTasks   int64
+	peakDepth      int
+	submitWait     time.Duration // Submitters blocked on a full queue
+	queueWait      time.Duration // Tasks queued before a worker picked them up
 	mu             sync.RWMutex
 }
 
+// SubmissionStats counts tasks and the backend's per-item verdicts
+type SubmissionStats struct {
+	TotalTasks       int64 `json:"total_tasks"`
+	CompletedTasks   int64 `json:"completed_tasks"`
+	FailedTasks      int64 `json:"failed_tasks"`
+	AcceptedItems    int64 `json:"accepted_items"`
+	RejectedItems    int64 `json:"rejected_items"` // Sent to the dead-letter store, not retried
+	DuplicateItems   int64 `json:"duplicate_items"`
+	ChangeEvents     int64 `json:"change_events"` // Sitemap change events delivered
+	QueueDepth       int   `json:"queue_depth"`   // Tasks waiting, in memory or spilled
+	PeakQueueDepth   int   `json:"peak_queue_depth"`
+	SpilledTasks     int64 `json:"spilled_tasks"` // Tasks that overflowed to the outbox
+	Workers          int   `json:"workers"`
+	SubmitWaitMs     int64 `json:"submit_wait_ms"`     // Total time submitters blocked on a full queue
+	QueueWaitMs      int64 `json:"queue_wait_ms"`      // Total time tasks waited for a worker
+	BackendLatencyMs int64 `json:"backend_latency_ms"` // Moving average of backend call duration
+}
+
 // NewSubmissionPool creates a new submission pool
 func NewSubmissionPool(client BackendClient, workerCount int) *SubmissionPool {
 	if workerCount <= 0 {
@@ -41,18 +123,84 @@ func NewSubmissionPool(client BackendClient, workerCount int) *SubmissionPool {
 		taskChannel: make(chan SubmissionTask, 100), // Buffer for 100 tasks
 		workerCount: workerCount,
 		stopChannel: make(chan struct{}),
+		spillSignal: make(chan struct{}, 1),
+		retire:      make(chan struct{}),
+		autoscale:   AutoscaleConfig{MinWorkers: workerCount, MaxWorkers: workerCount},
 		log:         logger.GetLogger().WithField("component", "submission_pool"),
 	}
 }
 
-// Start starts the submission workers
+// SetAutoscale lets the pool run between MinWorkers and MaxWorkers; call it before Start
+// Start begins with the worker count given to NewSubmissionPool, clamped to these bounds
+func (sp *SubmissionPool) SetAutoscale(config AutoscaleConfig) {
+	if config.MinWorkers <= 0 {
+		config.MinWorkers = 1
+	}
+	if config.MaxWorkers < config.MinWorkers {
+		config.MaxWorkers = config.MinWorkers
+	}
+	if config.LatencyTarget <= 0 {
+		config.LatencyTarget = defaultLatencyTarget
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultAutoscaleInterval
+	}
+	if sp.workerCount < config.MinWorkers {
+		sp.workerCount = config.MinWorkers
+	}
+	if sp.workerCount > config.MaxWorkers {
+		sp.workerCount = config.MaxWorkers
+	}
+	sp.autoscale = config
+}
+
+// SetOutbox makes every submission durable: data is persisted before it is queued and
+// removed only after the backend accepts it; leftovers are replayed by the next Start
+func (sp *SubmissionPool) SetOutbox(outbox *storage.Outbox) {
+	sp.outbox = outbox
+}
+
+// SetDeadLetters stores records the backend rejects so they can be inspected and fixed
+func (sp *SubmissionPool) SetDeadLetters(deadLetters *storage.DeadLetterStore) {
+	sp.deadLetters = deadLetters
+}
+
+// SetRunID sets the run ID used for idempotency keys of records submitted from now on
+func (sp *SubmissionPool) SetRunID(runID string) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.runID = runID
+}
+
+// Start starts the submission workers; later calls are no-ops, so the first ctx governs them
 func (sp *SubmissionPool) Start(ctx context.Context) {
-	sp.log.WithField("worker_count", sp.workerCount).Info("Starting submission pool")
-	
-	for i := 0; i < sp.workerCount; i++ {
+	sp.startOnce.Do(func() {
+		sp.log.WithField("worker_count", sp.workerCount).Info("Starting submission pool")
+		
+		for i := 0; i < sp.workerCount; i++ {
+			sp.startWorker(ctx)
+		}
+		
 		sp.wg.Add(1)
-		go sp.worker(ctx, i)
-	}
+		go sp.feedSpilled(ctx)
+		if sp.autoscale.MaxWorkers > sp.autoscale.MinWorkers {
+			sp.wg.Add(1)
+			go sp.runAutoscaler(ctx)
+		}
+		
+		sp.ReplayOutbox(ctx)
+	})
+}
+
+func (sp *SubmissionPool) startWorker(ctx context.Context) {
+	sp.mu.Lock()
+	id := sp.nextWorker
+	sp.nextWorker++
+	sp.workers++
+	sp.mu.Unlock()
+	
+	sp.wg.Add(1)
+	go sp.worker(ctx, id)
 }
 
 // Stop gracefully stops the submission pool
@@ -60,31 +208,206 @@ func (sp *SubmissionPool) Stop() {
 	sp.log.Info("Stopping submission pool")
 	close(sp.stopChannel)
 	sp.wg.Wait()
+	// Sinks such as files buffer output and must be flushed once the workers are gone
+	if closer, ok := sp.client.(interface{ Close() error }); ok {
+		if err := closer.Close(); err != nil {
+			sp.log.WithError(err).Warn("Failed to close submission client")
+		}
+	}
 	sp.log.Info("Submission pool stopped")
 }
 
 // Submit submits data for background processing (non-blocking)
+// When the queue is full the task is spilled to the outbox; without one it is rejected
 func (sp *SubmissionPool) Submit(data []KeywordMetricsData, callback func(error)) bool {
-	task := SubmissionTask{
-		Data:     data,
-		Callback: callback,
+	return sp.enqueue(nil, sp.newTask(SubmissionTask{Data: data, Callback: callback})) == nil
+}
+
+// SubmitContext submits data, blocking while the queue is full until there is room or ctx ends
+// A task that still does not fit is spilled to the outbox; without one, ctx's error is returned
+// Time spent blocked is reported as SubmitWaitMs
+func (sp *SubmissionPool) SubmitContext(ctx context.Context, data []KeywordMetricsData, callback func(error)) error {
+	return sp.enqueue(ctx, sp.newTask(SubmissionTask{Data: data, Callback: callback}))
+}
+
+// SubmitChangeEvents queues sitemap change events like SubmitContext queues metrics: they are
+// persisted in the outbox, keyed per run and replayed until the backend accepts them
+// The client must implement ChangeEventClient, otherwise the task fails
+func (sp *SubmissionPool) SubmitChangeEvents(ctx context.Context, events []ChangeEvent, callback func(error)) error {
+	return sp.enqueue(ctx, sp.newTask(SubmissionTask{Events: events, Callback: callback}))
+}
+
+// newTask assigns idempotency keys and persists the task's outbox copy
+func (sp *SubmissionPool) newTask(task SubmissionTask) SubmissionTask {
+	if len(task.Events) > 0 {
+		task.kind = outboxKindChangeEvents
 	}
 	
 	sp.mu.Lock()
 	sp.totalTasks++
+	runID := sp.runID
 	sp.mu.Unlock()
 	
+	// Keys are fixed before the outbox copy, so replays resend under the same keys
+	AssignIdempotencyKeys(task.Data, runID)
+	AssignChangeEventKeys(task.Events, runID)
+	
+	if sp.outbox != nil && task.size() > 0 {
+		var payload interface{} = task.Data
+		if task.kind == outboxKindChangeEvents {
+			payload = task.Events
+		}
+		id, err := sp.outbox.PutKind(context.Background(), task.kind, payload, task.size())
+		if err != nil {
+			sp.log.WithError(err).Warn("Failed to persist submission to outbox, sending without a durable copy")
+		}
+		task.OutboxID = id
+		sp.claimOutbox(id)
+	}
+	return task
+}
+
+// enqueue queues a task, blocking on a full queue only when ctx is set
+func (sp *SubmissionPool) enqueue(ctx context.Context, task SubmissionTask) error {
+	sp.addPending(1)
+	task.queuedAt = time.Now()
+	
+	// Tasks already spilled go first; a durable task joins them to keep submission order
+	if task.OutboxID != "" && sp.spilledCount() > 0 {
+		sp.spill(task)
+		return nil
+	}
+	
+	// A stopped pool must not take tasks its workers will never send, even with room in the queue
 	select {
-	case sp.taskChannel <- task:
-		sp.log.WithField("data_count", len(data)).Debug("Task queued for submission")
-		return true
 	case <-sp.stopChannel:
-		sp.log.Warn("Submission pool is stopping, task rejected")
-		return false
+		sp.addPending(-1)
+		sp.logRejected(task, "Submission pool is stopping, task rejected")
+		return ErrSubmissionPoolStopped
 	default:
-		sp.log.Warn("Submission queue is full, task rejected")
-		return false
 	}
+	
+	select {
+	case sp.taskChannel <- task:
+		sp.noteQueued()
+		sp.log.WithField("data_count", task.size()).Debug("Task queued for submission")
+		return nil
+	default:
+	}
+	
+	var ctxErr error
+	if ctx != nil {
+		waitStart := time.Now()
+		select {
+		case sp.taskChannel <- task:
+			sp.addSubmitWait(time.Since(waitStart))
+			sp.noteQueued()
+			return nil
+		case <-sp.stopChannel:
+			sp.addSubmitWait(time.Since(waitStart))
+			sp.addPending(-1)
+			sp.logRejected(task, "Submission pool is stopping, task rejected")
+			return ErrSubmissionPoolStopped
+		case <-ctx.Done():
+			sp.addSubmitWait(time.Since(waitStart))
+			ctxErr = ctx.Err()
+		}
+	}
+	
+	if task.OutboxID != "" {
+		sp.spill(task)
+		return nil
+	}
+	sp.addPending(-1)
+	sp.logRejected(task, "Submission queue is full, task rejected")
+	if ctxErr != nil {
+		return fmt.Errorf("%w: %v", ErrSubmissionQueueFull, ctxErr)
+	}
+	return ErrSubmissionQueueFull
+}
+
+// spill keeps a durable task only in the outbox until the feeder requeues it
+func (sp *SubmissionPool) spill(task SubmissionTask) {
+	dataCount := task.size()
+	task.Data, task.Events = nil, nil
+	
+	sp.mu.Lock()
+	sp.spilled = append(sp.spilled, task)
+	sp.spilledTasks++
+	sp.notePeakLocked()
+	spilled := len(sp.spilled)
+	sp.mu.Unlock()
+	
+	select {
+	case sp.spillSignal <- struct{}{}:
+	default:
+	}
+	sp.log.WithFields(map[string]interface{}{
+		"data_count": dataCount,
+		"outbox_id":  task.OutboxID,
+		"spilled":    spilled,
+	}).Debug("Submission queue full, task spilled to outbox")
+}
+
+func (sp *SubmissionPool) spilledCount() int {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return len(sp.spilled)
+}
+
+// feedSpilled reloads spilled tasks from the outbox as queue space frees up
+// Tasks left when the pool stops stay in the outbox and are replayed by the next Start
+func (sp *SubmissionPool) feedSpilled(ctx context.Context) {
+	defer sp.wg.Done()
+	
+	for {
+		select {
+		case <-sp.spillSignal:
+		case <-sp.stopChannel:
+			return
+		case <-ctx.Done():
+			return
+		}
+		
+		for {
+			sp.mu.Lock()
+			if len(sp.spilled) == 0 {
+				sp.mu.Unlock()
+				break
+			}
+			task := sp.spilled[0]
+			sp.spilled = sp.spilled[1:]
+			sp.mu.Unlock()
+			
+			if err := sp.loadPayload(ctx, &task); err != nil {
+				sp.log.WithError(err).WithField("outbox_id", task.OutboxID).Warn("Skipping unreadable spilled task")
+				sp.releaseOutbox(task.OutboxID)
+				sp.addPending(-1)
+				continue
+			}
+			select {
+			case sp.taskChannel <- task:
+				sp.noteQueued()
+			case <-sp.stopChannel:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// logRejected reports a task that could not be queued; outbox copies survive for replay
+func (sp *SubmissionPool) logRejected(task SubmissionTask, message string) {
+	sp.releaseOutbox(task.OutboxID)
+	if task.OutboxID != "" {
+		sp.log.WithFields(map[string]interface{}{
+			"data_count": task.size(),
+			"outbox_id":  task.OutboxID,
+		}).Warn(message + ", kept in outbox for replay")
+		return
+	}
+	sp.log.Warn(message)
 }
 
 // worker processes submission tasks
@@ -97,9 +420,13 @@ func (sp *SubmissionPool) worker(ctx context.Context, workerID int) {
 	for {
 		select {
 		case task := <-sp.taskChannel:
-			workerLog.WithField("data_count", len(task.Data)).Debug("Processing submission task")
+			if !task.queuedAt.IsZero() {
+				sp.addQueueWait(time.Since(task.queuedAt))
+			}
+			workerLog.WithField("data_count", task.size()).Debug("Processing submission task")
 			
 			err := sp.processTask(ctx, task)
+			sp.settleOutbox(task, err)
 			
 			sp.mu.Lock()
 			if err != nil {
@@ -115,7 +442,14 @@ func (sp *SubmissionPool) worker(ctx context.Context, workerID int) {
 			if task.Callback != nil {
 				task.Callback(err)
 			}
+			sp.addPending(-1)
 			
+		case <-sp.retire:
+			sp.mu.Lock()
+			sp.workers--
+			sp.mu.Unlock()
+			workerLog.Debug("Worker retired by autoscaler")
+			return
 		case <-sp.stopChannel:
 			workerLog.Debug("Worker stopping")
 			return
@@ -128,13 +462,18 @@ func (sp *SubmissionPool) worker(ctx context.Context, workerID int) {
 
 // processTask processes a single submission task
 func (sp *SubmissionPool) processTask(ctx context.Context, task SubmissionTask) error {
+	if len(task.Events) > 0 {
+		return sp.processChangeEvents(task)
+	}
 	if len(task.Data) == 0 {
 		return nil
 	}
 	
 	startTime := time.Now()
-	err := sp.client.SubmitBatches(task.Data)
+	result, err := sp.client.SubmitBatches(task.Data)
 	duration := time.Since(startTime)
+	sp.observeLatency(duration)
+	sp.recordItemResults(result)
 	
 	// Only log significant events to reduce log noise
 	if err != nil || len(task.Data) > 100 {
@@ -154,11 +493,342 @@ func (sp *SubmissionPool) processTask(ctx context.Context, task SubmissionTask)
 	return err
 }
 
+// processChangeEvents delivers a change-event task through the client's ChangeEventClient
+func (sp *SubmissionPool) processChangeEvents(task SubmissionTask) error {
+	eventClient, ok := sp.client.(ChangeEventClient)
+	if !ok {
+		return fmt.Errorf("submission client cannot deliver change events")
+	}
+	startTime := time.Now()
+	err := eventClient.SubmitChangeEvents(task.Events)
+	sp.observeLatency(time.Since(startTime))
+	if err == nil {
+		sp.mu.Lock()
+		sp.changeEvents += int64(len(task.Events))
+		sp.mu.Unlock()
+	}
+	return err
+}
+
+// loadPayload reads a task's data back from its outbox entry
+func (sp *SubmissionPool) loadPayload(ctx context.Context, task *SubmissionTask) error {
+	if task.kind == outboxKindChangeEvents {
+		return sp.outbox.Load(ctx, task.OutboxID, &task.Events)
+	}
+	return sp.outbox.Load(ctx, task.OutboxID, &task.Data)
+}
+
+// recordItemResults counts per-item verdicts and dead-letters rejected records
+// Rejections are final even when other batches of the task failed: a replay would be rejected again
+func (sp *SubmissionPool) recordItemResults(result SubmissionResult) {
+	sp.mu.Lock()
+	sp.acceptedItems += int64(result.Accepted)
+	sp.rejectedItems += int64(len(result.Rejected))
+	sp.duplicateItems += int64(result.Duplicates)
+	sp.mu.Unlock()
+	
+	if len(result.Rejected) == 0 {
+		return
+	}
+	if sp.deadLetters == nil {
+		sp.log.WithField("rejected", len(result.Rejected)).Warn("Backend rejected records, no dead-letter store configured")
+		return
+	}
+	letters := make([]storage.DeadLetter, 0, len(result.Rejected))
+	for _, rejected := range result.Rejected {
+		record, err := json.Marshal(rejected.Record)
+		if err != nil {
+			sp.log.WithError(err).Warn("Failed to encode rejected record")
+			continue
+		}
+		letters = append(letters, storage.DeadLetter{
+			Key:     rejected.Record.IdempotencyKey,
+			Keyword: rejected.Record.Keyword,
+			URL:     rejected.Record.URL,
+			RunID:   rejected.Record.RunID,
+			Reason:  rejected.Reason,
+			Record:  record,
+		})
+	}
+	if err := sp.deadLetters.Add(context.Background(), letters); err != nil {
+		sp.log.WithError(err).WithField("rejected", len(letters)).Error("Failed to store rejected records")
+	}
+}
+
+// settleOutbox removes an accepted task's durable copy or records the failed attempt
+func (sp *SubmissionPool) settleOutbox(task SubmissionTask, err error) {
+	if sp.outbox == nil || task.OutboxID == "" {
+		return
+	}
+	defer sp.releaseOutbox(task.OutboxID)
+	var settleErr error
+	if err != nil {
+		settleErr = sp.outbox.MarkFailed(context.Background(), task.OutboxID, err)
+	} else {
+		settleErr = sp.outbox.Delete(context.Background(), task.OutboxID)
+	}
+	if settleErr != nil {
+		sp.log.WithError(settleErr).WithField("outbox_id", task.OutboxID).Warn("Failed to update outbox entry")
+	}
+}
+
+// ReplayOutbox queues outbox entries the pool is not already sending: submissions a previous
+// process left behind and tasks that failed earlier in this one. Start replays once; long-lived
+// callers such as the daemon call it again so failed submissions are retried
+// Entries queued, spilled or in progress are skipped, so nothing is sent twice by one process
+func (sp *SubmissionPool) ReplayOutbox(ctx context.Context) {
+	if sp.outbox == nil {
+		return
+	}
+	listed, err := sp.outbox.List(ctx)
+	if err != nil {
+		sp.log.WithError(err).Warn("Failed to read outbox, leftover submissions not replayed")
+		return
+	}
+	var entries []storage.OutboxEntry
+	var ids []string
+	for _, entry := range listed {
+		if sp.claimOutbox(entry.ID) {
+			entries = append(entries, entry)
+			ids = append(ids, entry.ID)
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+	sp.log.WithField("entries", len(entries)).Info("Replaying submissions left in outbox")
+	
+	// Count every entry as pending up front so WaitIdle cannot report idle between entries
+	sp.addPending(int64(len(entries)))
+	go func() {
+		for i, entry := range entries {
+			task := SubmissionTask{OutboxID: entry.ID, kind: entry.Kind}
+			if err := sp.loadPayload(ctx, &task); err != nil {
+				sp.log.WithError(err).WithField("outbox_id", entry.ID).Warn("Skipping unreadable outbox entry")
+				sp.releaseOutbox(entry.ID)
+				sp.addPending(-1)
+				continue
+			}
+			
+			sp.mu.Lock()
+			sp.totalTasks++
+			sp.mu.Unlock()
+			
+			// Unlike Submit, wait for queue space: the entry is already durable
+			select {
+			case sp.taskChannel <- task:
+				sp.noteQueued()
+			case <-sp.stopChannel:
+				sp.releaseOutbox(ids[i:]...)
+				sp.addPending(-int64(len(entries) - i))
+				return
+			case <-ctx.Done():
+				sp.releaseOutbox(ids[i:]...)
+				sp.addPending(-int64(len(entries) - i))
+				return
+			}
+		}
+	}()
+}
+
+// claimOutbox marks an outbox entry as held by the pool; false when it already is
+func (sp *SubmissionPool) claimOutbox(id string) bool {
+	if id == "" {
+		return false
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if sp.inFlight[id] {
+		return false
+	}
+	if sp.inFlight == nil {
+		sp.inFlight = make(map[string]bool)
+	}
+	sp.inFlight[id] = true
+	return true
+}
+
+// releaseOutbox lets later replays pick the entries up again
+func (sp *SubmissionPool) releaseOutbox(ids ...string) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	for _, id := range ids {
+		delete(sp.inFlight, id)
+	}
+}
+
+// FlushOutbox sends every outbox entry synchronously, without starting workers
+// Returns how many entries the backend accepted and how many are still pending
+func (sp *SubmissionPool) FlushOutbox(ctx context.Context) (sent, pending int, err error) {
+	if sp.outbox == nil {
+		return 0, 0, nil
+	}
+	entries, err := sp.outbox.List(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return sent, len(entries) - sent, ctx.Err()
+		}
+		task := SubmissionTask{OutboxID: entry.ID, kind: entry.Kind}
+		if loadErr := sp.loadPayload(ctx, &task); loadErr != nil {
+			sp.log.WithError(loadErr).WithField("outbox_id", entry.ID).Warn("Skipping unreadable outbox entry")
+			pending++
+			continue
+		}
+		submitErr := sp.processTask(ctx, task)
+		sp.settleOutbox(task, submitErr)
+		if submitErr != nil {
+			pending++
+			continue
+		}
+		sent++
+	}
+	return sent, pending, nil
+}
+
+// runAutoscaler adds a worker while tasks outnumber workers and the backend keeps up, and
+// retires one when the backend slows down or the queue stays empty
+func (sp *SubmissionPool) runAutoscaler(ctx context.Context) {
+	defer sp.wg.Done()
+	
+	ticker := time.NewTicker(sp.autoscale.Interval)
+	defer ticker.Stop()
+	idleTicks := 0
+	for {
+		select {
+		case <-ticker.C:
+		case <-sp.stopChannel:
+			return
+		case <-ctx.Done():
+			return
+		}
+		
+		sp.mu.RLock()
+		depth := len(sp.taskChannel) + len(sp.spilled)
+		workers := sp.workers
+		latency := sp.latency
+		sp.mu.RUnlock()
+		
+		if depth == 0 {
+			idleTicks++
+		} else {
+			idleTicks = 0
+		}
+		
+		scaleLog := sp.log.WithFields(map[string]interface{}{
+			"queue_depth": depth,
+			"workers":     workers,
+			"latency":     latency.String(),
+		})
+		switch {
+		case latency > 2*sp.autoscale.LatencyTarget && workers > sp.autoscale.MinWorkers:
+			if sp.retireWorker() {
+				scaleLog.Info("Backend is slow, retired a submission worker")
+			}
+		case depth > workers && latency <= sp.autoscale.LatencyTarget && workers < sp.autoscale.MaxWorkers:
+			sp.startWorker(ctx)
+			scaleLog.Info("Submission queue backing up, added a worker")
+		case idleTicks >= autoscaleIdleTicks && workers > sp.autoscale.MinWorkers:
+			if sp.retireWorker() {
+				idleTicks = 0
+				scaleLog.Debug("Submission queue idle, retired a worker")
+			}
+		}
+	}
+}
+
+// retireWorker stops one idle worker; busy workers are left alone until the next check
+func (sp *SubmissionPool) retireWorker() bool {
+	select {
+	case sp.retire <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// observeLatency folds one backend call into the moving average
+func (sp *SubmissionPool) observeLatency(duration time.Duration) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if sp.latency == 0 {
+		sp.latency = duration
+		return
+	}
+	sp.latency = (sp.latency*4 + duration) / 5
+}
+
+func (sp *SubmissionPool) noteQueued() {
+	sp.mu.Lock()
+	sp.notePeakLocked()
+	sp.mu.Unlock()
+}
+
+func (sp *SubmissionPool) notePeakLocked() {
+	if depth := len(sp.taskChannel) + len(sp.spilled); depth > sp.peakDepth {
+		sp.peakDepth = depth
+	}
+}
+
+func (sp *SubmissionPool) addSubmitWait(d time.Duration) {
+	sp.mu.Lock()
+	sp.submitWait += d
+	sp.mu.Unlock()
+}
+
+func (sp *SubmissionPool) addQueueWait(d time.Duration) {
+	sp.mu.Lock()
+	sp.queueWait += d
+	sp.mu.Unlock()
+}
+
+func (sp *SubmissionPool) addPending(delta int64) {
+	sp.mu.Lock()
+	sp.pendingTasks += delta
+	sp.mu.Unlock()
+}
+
+// WaitIdle blocks until no task is queued or in progress, or ctx ends; it reports whether the pool drained
+func (sp *SubmissionPool) WaitIdle(ctx context.Context) bool {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		sp.mu.RLock()
+		pending := sp.pendingTasks
+		sp.mu.RUnlock()
+		if pending == 0 {
+			return true
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
 // GetStats returns submission statistics
-func (sp *SubmissionPool) GetStats() (total, completed, failed int64) {
+func (sp *SubmissionPool) GetStats() SubmissionStats {
 	sp.mu.RLock()
 	defer sp.mu.RUnlock()
-	return sp.totalTasks, sp.completedTasks, sp.failedTasks
+	return SubmissionStats{
+		TotalTasks:       sp.totalTasks,
+		CompletedTasks:   sp.completedTasks,
+		FailedTasks:      sp.failedTasks,
+		AcceptedItems:    sp.acceptedItems,
+		RejectedItems:    sp.rejectedItems,
+		DuplicateItems:   sp.duplicateItems,
+		ChangeEvents:     sp.changeEvents,
+		QueueDepth:       len(sp.taskChannel) + len(sp.spilled),
+		PeakQueueDepth:   sp.peakDepth,
+		SpilledTasks:     sp.spilledTasks,
+		Workers:          sp.workers,
+		SubmitWaitMs:     sp.submitWait.Milliseconds(),
+		QueueWaitMs:      sp.queueWait.Milliseconds(),
+		BackendLatencyMs: sp.latency.Milliseconds(),
+	}
 }
 
 // GetSuccessRate returns the success rate as a percentage
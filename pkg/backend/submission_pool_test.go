@@ -0,0 +1,124 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/mock"
+	"sitemap-go/pkg/storage"
+)
+
+func TestSubmissionPool_SpillsAndScalesUnderBackpressure(t *testing.T) {
+	server, err := mock.NewBackendServer("", "", mock.Behavior{Latency: 5 * time.Millisecond, Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+
+	client, err := backend.NewBackendClient(backend.BackendConfig{BaseURL: server.URL(), APIKey: "any", BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create backend client: %v", err)
+	}
+	ctx := context.Background()
+
+	// Without an outbox a full queue blocks until the deadline, then rejects
+	bare := backend.NewSubmissionPool(client, 1)
+	for i := 0; i < 100; i++ {
+		bare.Submit([]backend.KeywordMetricsData{{Keyword: "fill", URL: "https://example.com/fill"}}, nil)
+	}
+	deadline, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	err = bare.SubmitContext(deadline, []backend.KeywordMetricsData{{Keyword: "late", URL: "https://example.com/late"}}, nil)
+	cancel()
+	if !errors.Is(err, backend.ErrSubmissionQueueFull) {
+		t.Errorf("Expected ErrSubmissionQueueFull, got %v", err)
+	}
+	if wait := bare.GetStats().SubmitWaitMs; wait < 20 {
+		t.Errorf("Expected the blocked time in SubmitWaitMs, got %dms", wait)
+	}
+
+	// With an outbox nothing is dropped: overflow is spilled and fed back as workers free up
+	pool := backend.NewSubmissionPool(client, 1)
+	pool.SetOutbox(storage.NewOutbox(storage.NewMemoryStorage()))
+	pool.SetAutoscale(backend.AutoscaleConfig{MinWorkers: 1, MaxWorkers: 4, Interval: 10 * time.Millisecond})
+	for i := 0; i < 150; i++ {
+		keyword := fmt.Sprintf("keyword %d", i)
+		if !pool.Submit([]backend.KeywordMetricsData{{Keyword: keyword, URL: "https://example.com/" + keyword}}, nil) {
+			t.Fatalf("Submission %d was rejected", i)
+		}
+	}
+	if stats := pool.GetStats(); stats.SpilledTasks != 50 || stats.QueueDepth != 150 {
+		t.Errorf("Expected 50 of 150 tasks spilled, got %+v", stats)
+	}
+
+	pool.Start(ctx)
+	defer pool.Stop()
+	peakWorkers := 0
+	for !pool.WaitIdle(timeoutContext(t, 10*time.Millisecond)) {
+		if workers := pool.GetStats().Workers; workers > peakWorkers {
+			peakWorkers = workers
+		}
+	}
+	if got := len(server.Stored()); got != 150 {
+		t.Errorf("Expected 150 stored records, got %d", got)
+	}
+	if peakWorkers < 2 {
+		t.Errorf("Expected the autoscaler to add workers, peak was %d", peakWorkers)
+	}
+	if stats := pool.GetStats(); stats.QueueWaitMs == 0 || stats.PeakQueueDepth != 150 {
+		t.Errorf("Expected queue wait and depth metrics, got %+v", stats)
+	}
+}
+
+func TestSubmissionPool_ReplayOutboxRetriesFailedTasks(t *testing.T) {
+	server, err := mock.NewBackendServer("", "", mock.Behavior{ErrorRate: 1, Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+	client, err := backend.NewBackendClient(backend.BackendConfig{BaseURL: server.URL(), APIKey: "any", BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create backend client: %v", err)
+	}
+	outbox := storage.NewOutbox(storage.NewMemoryStorage())
+	pool := backend.NewSubmissionPool(client, 1)
+	pool.SetOutbox(outbox)
+	ctx := context.Background()
+	pool.Start(ctx)
+	defer pool.Stop()
+
+	pool.Submit([]backend.KeywordMetricsData{{Keyword: "retry me", URL: "https://example.com/retry-me"}}, nil)
+	if !pool.WaitIdle(timeoutContext(t, 5*time.Second)) {
+		t.Fatal("Pool did not drain")
+	}
+	if entries, _ := outbox.List(ctx); len(entries) != 1 || entries[0].Attempts != 1 {
+		t.Fatalf("Expected the failed task kept in the outbox, got %+v", entries)
+	}
+
+	// Start only replays once: a long-lived process retries by replaying again
+	server.SetBehavior(mock.Behavior{})
+	pool.ReplayOutbox(ctx)
+	pool.ReplayOutbox(ctx) // The entry is already queued and must not be sent twice
+	if !pool.WaitIdle(timeoutContext(t, 5*time.Second)) {
+		t.Fatal("Pool did not drain after the replay")
+	}
+	if entries, _ := outbox.List(ctx); len(entries) != 0 {
+		t.Errorf("Expected the replayed entry removed from the outbox, got %+v", entries)
+	}
+	if got := len(server.Requests()); got != 2 {
+		t.Errorf("Expected one failed and one replayed request, got %d", got)
+	}
+	if got := len(server.Stored()); got != 1 {
+		t.Errorf("Expected the record stored once, got %d", got)
+	}
+}
+
+func timeoutContext(t *testing.T, timeout time.Duration) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	t.Cleanup(cancel)
+	return ctx
+}
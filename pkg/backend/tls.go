@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSConfig controls how the backend client verifies the server and identifies itself
+// The zero value uses the system roots and TLS 1.2 or later
+type TLSConfig struct {
+	CertFile   string   `json:"cert_file,omitempty"`   // Client certificate (PEM) for mutual TLS
+	KeyFile    string   `json:"key_file,omitempty"`    // Private key (PEM) of CertFile
+	CAFile     string   `json:"ca_file,omitempty"`     // PEM bundle trusted instead of the system roots
+	PinnedSPKI []string `json:"pinned_spki,omitempty"` // "sha256/<base64>" of a public key the server chain must contain
+	MinVersion string   `json:"min_version,omitempty"` // "1.2" (default) or "1.3"
+}
+
+// IsZero reports whether no TLS option is set
+func (c TLSConfig) IsZero() bool {
+	return c.CertFile == "" && c.KeyFile == "" && c.CAFile == "" && len(c.PinnedSPKI) == 0 && c.MinVersion == ""
+}
+
+// Build loads certificates and returns the client TLS configuration
+// Pins are checked on top of normal chain verification, never instead of it
+func (c TLSConfig) Build() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	switch c.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		config.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported minimum TLS version %q, use 1.2 or 1.3", c.MinVersion)
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, fmt.Errorf("client certificate and key must be set together")
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if len(c.PinnedSPKI) > 0 {
+		pins := make(map[string]bool, len(c.PinnedSPKI))
+		for _, pin := range c.PinnedSPKI {
+			normalized, err := normalizeSPKIPin(pin)
+			if err != nil {
+				return nil, err
+			}
+			pins[normalized] = true
+		}
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			for _, cert := range state.PeerCertificates {
+				if pins[SPKIFingerprint(cert)] {
+					return nil
+				}
+			}
+			return fmt.Errorf("server certificate chain matches none of the %d pinned public keys", len(pins))
+		}
+	}
+	return config, nil
+}
+
+// SPKIFingerprint returns the pin of a certificate's public key as "sha256/<base64>"
+func SPKIFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// normalizeSPKIPin accepts pins with or without the "sha256/" prefix
+func normalizeSPKIPin(pin string) (string, error) {
+	encoded := strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != sha256.Size {
+		return "", fmt.Errorf("invalid SPKI pin %q, expected base64 of a SHA-256 digest", pin)
+	}
+	return "sha256/" + encoded, nil
+}
@@ -0,0 +1,148 @@
+package backend_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/backend"
+)
+
+func TestBackendClient_MutualTLSPinningAndAuth(t *testing.T) {
+	var mu sync.Mutex
+	var headers http.Header
+	var body []byte
+	var clientCerts int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		clientCerts = len(r.TLS.PeerCertificates)
+		mu.Unlock()
+		w.Write([]byte(`{"code":200,"message":"ok"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+	certFile, keyFile := writeClientCertificate(t, dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	tlsConfig := backend.TLSConfig{
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		CAFile:     caFile,
+		PinnedSPKI: []string{backend.SPKIFingerprint(server.Certificate())},
+		MinVersion: "1.2",
+	}
+	batch := backend.KeywordMetricsBatch{{Keyword: "one", URL: "https://example.com/one"}}
+
+	bearer, err := backend.NewBackendClient(backend.BackendConfig{
+		BaseURL: server.URL,
+		TLS:     tlsConfig,
+		Auth:    backend.AuthConfig{Scheme: backend.AuthBearer, TokenFile: tokenFile},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create bearer client: %v", err)
+	}
+	if _, err := bearer.SubmitBatch(batch); err != nil {
+		t.Fatalf("Bearer submission failed: %v", err)
+	}
+	mu.Lock()
+	if got := headers.Get("Authorization"); got != "Bearer file-token" {
+		t.Errorf("Expected the token file as bearer token, got %q", got)
+	}
+	if clientCerts != 1 {
+		t.Errorf("Expected the client certificate to be presented, got %d", clientCerts)
+	}
+	mu.Unlock()
+
+	hmacClient, err := backend.NewBackendClient(backend.BackendConfig{
+		BaseURL: server.URL,
+		APIKey:  "secret",
+		TLS:     tlsConfig,
+		Auth:    backend.AuthConfig{Scheme: backend.AuthHMAC, KeyID: "k1"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create HMAC client: %v", err)
+	}
+	if _, err := hmacClient.SubmitBatch(batch); err != nil {
+		t.Fatalf("HMAC submission failed: %v", err)
+	}
+	mu.Lock()
+	expected := "sha256=" + backend.SignWebhookPayload([]byte("secret"), headers.Get(backend.WebhookTimestampHeader), body)
+	if headers.Get(backend.WebhookSignatureHeader) != expected || headers.Get(backend.AuthKeyIDHeader) != "k1" {
+		t.Errorf("Expected a valid HMAC signature with key ID, got %v", headers)
+	}
+	if headers.Get("X-API-Key") != "" {
+		t.Error("Expected the HMAC secret not to be sent")
+	}
+	mu.Unlock()
+
+	wrongPin := tlsConfig
+	wrongPin.PinnedSPKI = []string{"sha256/" + base64.StdEncoding.EncodeToString(make([]byte, 32))}
+	pinned, err := backend.NewBackendClient(backend.BackendConfig{BaseURL: server.URL, APIKey: "any", TLS: wrongPin})
+	if err != nil {
+		t.Fatalf("Failed to create pinned client: %v", err)
+	}
+	if _, err := pinned.SubmitBatch(batch); err == nil {
+		t.Error("Expected a server outside the pins to be refused")
+	}
+
+	if _, err := backend.NewBackendClient(backend.BackendConfig{BaseURL: server.URL, APIKey: "any", TLS: backend.TLSConfig{MinVersion: "1.1"}}); err == nil {
+		t.Error("Expected TLS 1.1 to be rejected")
+	}
+}
+
+func writeClientCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sitemap-go test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
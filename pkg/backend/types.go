@@ -7,9 +7,13 @@ type KeywordMetricsBatch []KeywordMetricsData
 
 // KeywordMetricsData represents a single keyword's metrics data
 type KeywordMetricsData struct {
-	Keyword string       `json:"keyword"`
-	URL     string       `json:"url,omitempty"`
-	Metrics MetricsData  `json:"metrics"`
+	Keyword        string      `json:"keyword"`
+	URL            string      `json:"url,omitempty"`
+	Locale         string      `json:"locale,omitempty"` // Language/geo the metrics apply to (e.g. "de-DE"), empty = untargeted
+	Metrics        MetricsData `json:"metrics"`
+	RunID          string      `json:"run_id,omitempty"`          // Run that produced the metrics
+	IdempotencyKey string      `json:"idempotency_key,omitempty"` // Stable across resends, see RecordIdempotencyKey
+	BatchID        string      `json:"batch_id,omitempty"`        // Idempotency key of the batch the record was sent in
 }
 
 // MetricsData contains all metrics for a keyword
@@ -42,14 +46,22 @@ type DataQualityInfo struct {
 	AvailableMonths         int      `json:"available_months"`
 	MissingMonthsCount      int      `json:"missing_months_count"`
 	MissingMonths           []string `json:"missing_months"`
+	MissingFields           []string `json:"missing_fields"` // Provider fields absent from the response
 	Warnings                []string `json:"warnings"`
 }
 
+// Data quality status values
+const (
+	DataQualityComplete   = "complete"
+	DataQualityIncomplete = "incomplete"
+	DataQualityMissing    = "missing" // Provider returned no monthly series at all
+)
+
 // BackendResponse represents the API response from backend
 type BackendResponse struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    *BatchResult `json:"data,omitempty"`
 }
 
 // BackendConfig holds backend API configuration
@@ -59,10 +71,12 @@ type BackendConfig struct {
 	BatchSize  int    `json:"batch_size"`   // Keywords per batch (default: 4)
 	EnableGzip bool   `json:"enable_gzip"`
 	Timeout    time.Duration `json:"timeout"`
+	TLS        TLSConfig     `json:"tls"`
+	Auth       AuthConfig    `json:"auth"`
 }
 
 // BackendClient interface for submitting metrics data
 type BackendClient interface {
 	SubmitBatch(batch KeywordMetricsBatch) (*BackendResponse, error)
-	SubmitBatches(data []KeywordMetricsData) error
+	SubmitBatches(data []KeywordMetricsData) (SubmissionResult, error)
 }
\ No newline at end of file
@@ -0,0 +1,125 @@
+package backend
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"sitemap-go/pkg/logger"
+)
+
+// Webhook signature headers; receivers recompute HMAC-SHA256(secret, timestamp + "." + body)
+const (
+	WebhookSignatureHeader = "X-Signature-256" // "sha256=<hex>"
+	WebhookTimestampHeader = "X-Signature-Timestamp"
+)
+
+// WebhookEventHeader tells receivers which payload a request carries
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookEventMetrics    = "keyword_metrics" // JSON array of KeywordMetricsData
+	WebhookEventDelistings = "delistings"      // JSON array of delisted and relisted ChangeEvents
+)
+
+// WebhookSink posts records as a JSON array to an arbitrary URL, signed with a shared secret
+type WebhookSink struct {
+	url     string
+	secret  []byte
+	timeout time.Duration
+	client  *fasthttp.Client
+	log     *logger.Logger
+}
+
+// NewWebhookSink creates a webhook sink; an empty secret sends unsigned requests
+func NewWebhookSink(url, secret string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	sink := &WebhookSink{
+		url:     url,
+		secret:  []byte(secret),
+		timeout: timeout,
+		client: &fasthttp.Client{
+			ReadTimeout:         timeout,
+			WriteTimeout:        timeout,
+			MaxConnsPerHost:     4,
+			MaxIdleConnDuration: 30 * time.Second,
+		},
+		log: logger.GetLogger().WithField("component", "webhook_sink"),
+	}
+	if secret == "" {
+		sink.log.Warn("Webhook secret not set, requests will be unsigned")
+	}
+	return sink
+}
+
+// Name identifies the sink in logs without exposing the URL path
+func (s *WebhookSink) Name() string {
+	return SinkWebhook + ":" + logger.GetSecurityLogger().MaskAPIEndpoint(s.url)
+}
+
+// Write posts one batch; 2xx and 409 (already accepted) count as delivered
+func (s *WebhookSink) Write(data []KeywordMetricsData) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	return s.post(body, BatchIdempotencyKey(data), WebhookEventMetrics)
+}
+
+// WriteDelistings posts delisting notices to the same URL, told apart by WebhookEventHeader
+func (s *WebhookSink) WriteDelistings(events []ChangeEvent) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	return s.post(body, ChangeEventBatchKey(events), WebhookEventDelistings)
+}
+
+func (s *WebhookSink) post(body []byte, idempotencyKey, event string) error {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(s.url)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	req.Header.Set(IdempotencyHeader, idempotencyKey)
+	req.Header.Set(WebhookEventHeader, event)
+	if len(s.secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(s.secret, timestamp, body))
+	}
+	req.SetBody(body)
+
+	if err := s.client.DoTimeout(req, resp, s.timeout); err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	status := resp.StatusCode()
+	if status == fasthttp.StatusConflict || (status >= 200 && status < 300) {
+		return nil
+	}
+	return fmt.Errorf("webhook returned status %d", status)
+}
+
+// Close releases idle connections
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of timestamp + "." + body
+func SignWebhookPayload(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
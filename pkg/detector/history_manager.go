@@ -12,17 +12,29 @@ import (
 	"sitemap-go/pkg/utils"
 )
 
+// DefaultSnapshotRetention is how many snapshots per domain are kept by default
+const DefaultSnapshotRetention = 100
+
 // URLHistoryManager implements HistoryManager for URL snapshots
 type URLHistoryManager struct {
-	storage storage.Storage
-	log     *logger.Logger
+	storage   storage.Storage
+	log       *logger.Logger
+	retention int // Snapshots kept per domain; older ones are deleted
 }
 
 // NewURLHistoryManager creates a new URL history manager
 func NewURLHistoryManager(storage storage.Storage) *URLHistoryManager {
 	return &URLHistoryManager{
-		storage: storage,
-		log:     logger.GetLogger().WithField("component", "url_history_manager"),
+		storage:   storage,
+		log:       logger.GetLogger().WithField("component", "url_history_manager"),
+		retention: DefaultSnapshotRetention,
+	}
+}
+
+// SetRetention sets how many snapshots per domain are kept
+func (h *URLHistoryManager) SetRetention(snapshots int) {
+	if snapshots > 0 {
+		h.retention = snapshots
 	}
 }
 
@@ -66,7 +78,7 @@ func (h *URLHistoryManager) SaveSnapshot(ctx context.Context, domain string, url
 		"domain":    domain,
 		"url_count": len(urls),
 		"checksum":  checksum,
-	}).Info("Snapshot saved successfully")
+	}).Debug("Snapshot saved successfully")
 
 	return nil
 }
@@ -128,13 +140,20 @@ func (h *URLHistoryManager) updateSnapshotIndex(ctx context.Context, domain stri
 	// Add new metadata
 	history = append(history, metadata)
 	
-	// Keep only recent snapshots (limit to 100 entries)
-	const maxHistorySize = 100
-	if len(history) > maxHistorySize {
+	// Keep only recent snapshots and delete the data of older ones
+	if len(history) > h.retention {
 		sort.Slice(history, func(i, j int) bool {
 			return history[i].Timestamp.After(history[j].Timestamp)
 		})
-		history = history[:maxHistorySize]
+		for _, expired := range history[h.retention:] {
+			// Snapshots saved within the same second share keys with the newest one
+			if expired.Timestamp.Unix() == metadata.Timestamp.Unix() {
+				continue
+			}
+			_ = h.storage.Delete(ctx, fmt.Sprintf("snapshot:%s:%d", domain, expired.Timestamp.Unix()))
+			_ = h.storage.Delete(ctx, fmt.Sprintf("snapshot_meta:%s:%d", domain, expired.Timestamp.Unix()))
+		}
+		history = history[:h.retention]
 	}
 	
 	// Save updated history
@@ -109,7 +109,7 @@ func (d *URLChangeDetector) DetectChanges(ctx context.Context, oldURLs, newURLs
 		"added":          changeSet.TotalAdded,
 		"removed":        changeSet.TotalRemoved,
 		"modified":       changeSet.TotalModified,
-	}).Info("Change detection completed")
+	}).Debug("Change detection completed")
 
 	return changeSet, nil
 }
@@ -139,6 +139,11 @@ func (d *URLChangeDetector) GetChangeHistory(ctx context.Context, domain string,
 
 // hasURLChanged checks if a URL has been modified
 func (d *URLChangeDetector) hasURLChanged(oldURL, newURL parser.URL) bool {
+	// A republished page gets a new <lastmod>
+	if oldURL.LastUpdated != newURL.LastUpdated {
+		return true
+	}
+
 	// Compare keywords
 	if !d.equalStringSlices(oldURL.Keywords, newURL.Keywords) {
 		return true
@@ -156,6 +161,13 @@ func (d *URLChangeDetector) hasURLChanged(oldURL, newURL parser.URL) bool {
 func (d *URLChangeDetector) getURLDifferences(oldURL, newURL parser.URL) map[string]interface{} {
 	differences := make(map[string]interface{})
 
+	if oldURL.LastUpdated != newURL.LastUpdated {
+		differences["last_updated"] = map[string]interface{}{
+			"old": oldURL.LastUpdated,
+			"new": newURL.LastUpdated,
+		}
+	}
+
 	// Check keyword changes
 	if !d.equalStringSlices(oldURL.Keywords, newURL.Keywords) {
 		differences["keywords"] = map[string]interface{}{
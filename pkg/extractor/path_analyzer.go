@@ -15,6 +15,18 @@ var (
 	datePattern      = regexp.MustCompile(`\d{4}[-/]\d{1,2}[-/]\d{1,2}`)
 	slugPattern      = regexp.MustCompile(`[a-z]+-[a-z-]+`)
 	camelCasePattern = regexp.MustCompile(`[a-z][A-Z]`)
+	localePattern    = regexp.MustCompile(`^([a-zA-Z]{2})(?:[-_]([a-zA-Z]{2}))?$`)
+	
+	// Languages recognised as locale path sections (/de/, /pt-br/)
+	// "id" (Indonesian) is left out because /id/ usually means an identifier path
+	localeLanguages = map[string]bool{
+		"en": true, "de": true, "fr": true, "es": true, "it": true, "pt": true, "nl": true,
+		"pl": true, "ru": true, "tr": true, "ja": true, "ko": true, "zh": true, "ar": true,
+		"sv": true, "da": true, "no": true, "nb": true, "fi": true, "cs": true, "hu": true,
+		"ro": true, "el": true, "uk": true, "vi": true, "th": true, "he": true, "hi": true,
+		"ms": true, "bg": true, "hr": true, "sk": true, "sl": true, "sr": true, "lt": true,
+		"lv": true, "et": true,
+	}
 	
 	// Industry-specific keywords
 	techKeywords = map[string]bool{
@@ -85,6 +97,11 @@ func (pa *PathAnalyzer) AnalyzePath(path string) *PathAnalysis {
 		analysis.Keywords = append(analysis.Keywords, pathSegment.Keywords...)
 	}
 	
+	// Locale sections such as /de/ or /pt-br/
+	if locale := pa.DetectLocale(path); locale != "" {
+		analysis.Metadata["locale"] = locale
+	}
+	
 	// Add hierarchical analysis
 	if pa.enableHierarchy {
 		pa.addHierarchicalAnalysis(analysis)
@@ -110,6 +127,19 @@ func (pa *PathAnalyzer) AnalyzePath(path string) *PathAnalysis {
 	return analysis
 }
 
+// DetectLocale returns the locale named by the first path segment ("de", "pt-br"), or ""
+func (pa *PathAnalyzer) DetectLocale(path string) string {
+	first := strings.SplitN(strings.Trim(path, "/"), "/", 2)[0]
+	match := localePattern.FindStringSubmatch(first)
+	if match == nil || !localeLanguages[strings.ToLower(match[1])] {
+		return ""
+	}
+	if match[2] == "" {
+		return strings.ToLower(match[1])
+	}
+	return strings.ToLower(match[1]) + "-" + strings.ToLower(match[2])
+}
+
 func (pa *PathAnalyzer) analyzeSegment(segment string, position int) PathSegment {
 	pathSegment := PathSegment{
 		Value:     segment,
@@ -0,0 +1,97 @@
+package mock
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"sitemap-go/pkg/backend"
+)
+
+// maxSignatureAge bounds how old an HMAC timestamp may be, as a real backend would to stop replays
+const maxSignatureAge = 5 * time.Minute
+
+// Auth is the credential the mock backend requires, in one of the client's auth schemes
+type Auth struct {
+	Scheme    string // backend.AuthAPIKey (default), backend.AuthBearer or backend.AuthHMAC
+	Secret    string // API key, bearer token or HMAC secret; empty accepts any request
+	TokenFile string // Read on every request instead of Secret, so rotated tokens apply at once
+	KeyID     string // Required X-Key-Id with HMAC; empty accepts any
+}
+
+// SetAuth changes the credential the mock backend requires
+func (b *BackendServer) SetAuth(auth Auth) error {
+	if err := (backend.AuthConfig{Scheme: auth.Scheme}).Validate(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.auth = auth
+	return nil
+}
+
+// authorize checks the request's credential; it returns the reason when the request is refused
+func (b *BackendServer) authorize(r *http.Request) (string, bool) {
+	b.mu.Lock()
+	auth := b.auth
+	b.mu.Unlock()
+
+	secret := auth.Secret
+	if auth.TokenFile != "" {
+		data, err := os.ReadFile(auth.TokenFile)
+		if err != nil {
+			return "token file unreadable", false
+		}
+		secret = strings.TrimSpace(string(data))
+	}
+	if secret == "" {
+		return "", true
+	}
+
+	switch auth.Scheme {
+	case backend.AuthBearer:
+		if !hmac.Equal([]byte(r.Header.Get("Authorization")), []byte("Bearer "+secret)) {
+			return "invalid bearer token", false
+		}
+	case backend.AuthHMAC:
+		return verifySignature(r, secret, auth.KeyID)
+	default:
+		if !hmac.Equal([]byte(r.Header.Get("X-API-Key")), []byte(secret)) {
+			return "invalid API key", false
+		}
+	}
+	return "", true
+}
+
+// verifySignature checks an HMAC-signed request and puts the body back for decoding
+func verifySignature(r *http.Request, secret, keyID string) (string, bool) {
+	if keyID != "" && r.Header.Get(backend.AuthKeyIDHeader) != keyID {
+		return "unknown key ID", false
+	}
+	timestamp := r.Header.Get(backend.WebhookTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "missing signature timestamp", false
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return fmt.Sprintf("signature timestamp outside %s", maxSignatureAge), false
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return "unreadable body", false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	expected := "sha256=" + backend.SignWebhookPayload([]byte(secret), timestamp, body)
+	if !hmac.Equal([]byte(r.Header.Get(backend.WebhookSignatureHeader)), []byte(expected)) {
+		return "invalid signature", false
+	}
+	return "", true
+}
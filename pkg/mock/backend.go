@@ -0,0 +1,362 @@
+package mock
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/backend"
+)
+
+// BatchEndpoint is the backend path served by BackendServer
+const BatchEndpoint = "/api/v1/keyword-metrics/batch"
+
+// BackendRequest records one batch submission received by the mock backend
+type BackendRequest struct {
+	Path            string                       `json:"path"`
+	Header          http.Header                  `json:"-"`
+	ContentType     string                       `json:"content_type"`
+	ContentEncoding string                       `json:"content_encoding"`
+	Records         []backend.KeywordMetricsData `json:"records"`
+	Accepted        int                          `json:"accepted"`   // Records the mock kept
+	Rejected        int                          `json:"rejected"`   // Records reported back as rejected
+	Duplicates      int                          `json:"duplicates"` // Records dropped because their idempotency key was already stored
+	StatusCode      int                          `json:"status_code"`
+	ReceivedAt      time.Time                    `json:"received_at"`
+}
+
+// BackendServer is an in-process server implementing the keyword-metrics batch contract
+type BackendServer struct {
+	server   *server
+	injector *injector
+
+	mu          sync.Mutex
+	auth        Auth
+	requests    []BackendRequest
+	stored      []backend.KeywordMetricsData
+	events      []backend.ChangeEvent
+	seenEvents  map[string]bool // idempotency_key values of stored change events
+	seenBatches map[string]bool // Idempotency-Key headers of fully accepted batches
+	seenRecords map[string]bool // idempotency_key values of stored records
+}
+
+// NewBackendServer starts a mock backend on addr ("" picks a free local port)
+// When apiKey is non-empty, requests without a matching X-API-Key header get 401; SetAuth
+// switches to bearer or HMAC verification
+func NewBackendServer(addr, apiKey string, behavior Behavior) (*BackendServer, error) {
+	b := &BackendServer{
+		injector:    newInjector(behavior),
+		auth:        Auth{Secret: apiKey},
+		seenEvents:  make(map[string]bool),
+		seenBatches: make(map[string]bool),
+		seenRecords: make(map[string]bool),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(BatchEndpoint, b.handleBatch)
+	mux.HandleFunc(backend.ChangeEventsEndpoint, b.handleChangeEvents)
+	s, err := startServer(addr, mux)
+	if err != nil {
+		return nil, err
+	}
+	b.server = s
+	return b, nil
+}
+
+// URL returns the base URL to use as BACKEND_URL
+func (b *BackendServer) URL() string {
+	return b.server.url()
+}
+
+// SetBehavior changes failure injection while the server is running
+func (b *BackendServer) SetBehavior(behavior Behavior) {
+	b.injector.setBehavior(behavior)
+}
+
+// Requests returns a copy of every submission received so far
+func (b *BackendServer) Requests() []BackendRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]BackendRequest(nil), b.requests...)
+}
+
+// Stored returns every record the mock accepted, in arrival order
+func (b *BackendServer) Stored() []backend.KeywordMetricsData {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]backend.KeywordMetricsData(nil), b.stored...)
+}
+
+// ChangeEvents returns every change event the mock accepted, in arrival order
+func (b *BackendServer) ChangeEvents() []backend.ChangeEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]backend.ChangeEvent(nil), b.events...)
+}
+
+// Reset clears recorded requests, stored records and change events
+func (b *BackendServer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests = nil
+	b.stored = nil
+	b.events = nil
+	b.seenEvents = make(map[string]bool)
+	b.seenBatches = make(map[string]bool)
+	b.seenRecords = make(map[string]bool)
+}
+
+// Close stops the server
+func (b *BackendServer) Close() error {
+	return b.server.close()
+}
+
+func (b *BackendServer) handleBatch(w http.ResponseWriter, r *http.Request) {
+	record := BackendRequest{
+		Path:            r.URL.Path,
+		Header:          r.Header.Clone(),
+		ContentType:     r.Header.Get("Content-Type"),
+		ContentEncoding: r.Header.Get("Content-Encoding"),
+		ReceivedAt:      time.Now(),
+	}
+	defer func() {
+		b.mu.Lock()
+		b.requests = append(b.requests, record)
+		b.mu.Unlock()
+	}()
+
+	if r.Method != http.MethodPost {
+		record.StatusCode = http.StatusMethodNotAllowed
+		b.writeResponse(w, record.StatusCode, "method not allowed", nil)
+		return
+	}
+	if message, ok := b.authorize(r); !ok {
+		record.StatusCode = http.StatusUnauthorized
+		b.writeResponse(w, record.StatusCode, message, nil)
+		return
+	}
+
+	records, err := decodeBatch(r)
+	if err != nil {
+		record.StatusCode = http.StatusBadRequest
+		b.writeResponse(w, record.StatusCode, err.Error(), nil)
+		return
+	}
+	record.Records = records
+
+	batchKey := r.Header.Get(backend.IdempotencyHeader)
+	b.mu.Lock()
+	duplicate := batchKey != "" && b.seenBatches[batchKey]
+	b.mu.Unlock()
+	if duplicate {
+		record.StatusCode = http.StatusConflict
+		record.Duplicates = len(records)
+		b.writeResponse(w, record.StatusCode, "already accepted", nil)
+		return
+	}
+
+	result, delay := b.injector.next()
+	if !sleep(r, delay) {
+		record.StatusCode = 499
+		return
+	}
+
+	switch result {
+	case outcomeError:
+		record.StatusCode = http.StatusInternalServerError
+		b.writeResponse(w, record.StatusCode, "injected server error", nil)
+		return
+	case outcomeRateLimited:
+		record.StatusCode = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", "1")
+		b.writeResponse(w, record.StatusCode, "too many requests", nil)
+		return
+	case outcomeMalformed:
+		record.StatusCode = http.StatusOK
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"code":200,"message":`))
+		return
+	}
+
+	// A partial outcome rejects the tail of the batch, as a backend failing validation would
+	valid := len(records)
+	if result == outcomePartial {
+		valid = b.injector.keep(len(records))
+	}
+	record.StatusCode = http.StatusOK
+	data := &backend.BatchResult{Received: len(records)}
+
+	b.mu.Lock()
+	for i, kept := range records {
+		item := backend.ItemResult{IdempotencyKey: kept.IdempotencyKey, Status: backend.ItemAccepted}
+		switch {
+		case kept.Keyword == "" || kept.URL == "":
+			item.Status, item.Reason = backend.ItemRejected, "keyword and url are required"
+		case i >= valid:
+			item.Status, item.Reason = backend.ItemRejected, "injected validation error"
+		case kept.IdempotencyKey != "" && b.seenRecords[kept.IdempotencyKey]:
+			item.Status = backend.ItemDuplicate
+		}
+		data.Items = append(data.Items, item)
+
+		switch item.Status {
+		case backend.ItemRejected:
+			record.Rejected++
+			continue
+		case backend.ItemDuplicate:
+			record.Duplicates++
+			continue
+		}
+		if kept.IdempotencyKey != "" {
+			b.seenRecords[kept.IdempotencyKey] = true
+		}
+		b.stored = append(b.stored, kept)
+		record.Accepted++
+	}
+	if batchKey != "" && record.Rejected == 0 {
+		b.seenBatches[batchKey] = true
+	}
+	b.mu.Unlock()
+
+	data.Accepted = record.Accepted
+	b.writeResponse(w, http.StatusOK, "ok", data)
+}
+
+// handleChangeEvents stores change events once per idempotency key; failure injection
+// applies as for keyword batches, except partial and malformed outcomes
+func (b *BackendServer) handleChangeEvents(w http.ResponseWriter, r *http.Request) {
+	record := BackendRequest{
+		Path:            r.URL.Path,
+		Header:          r.Header.Clone(),
+		ContentType:     r.Header.Get("Content-Type"),
+		ContentEncoding: r.Header.Get("Content-Encoding"),
+		ReceivedAt:      time.Now(),
+	}
+	defer func() {
+		b.mu.Lock()
+		b.requests = append(b.requests, record)
+		b.mu.Unlock()
+	}()
+
+	if r.Method != http.MethodPost {
+		record.StatusCode = http.StatusMethodNotAllowed
+		b.writeResponse(w, record.StatusCode, "method not allowed", nil)
+		return
+	}
+	if message, ok := b.authorize(r); !ok {
+		record.StatusCode = http.StatusUnauthorized
+		b.writeResponse(w, record.StatusCode, message, nil)
+		return
+	}
+
+	var events []backend.ChangeEvent
+	if err := decodeBody(r, &events); err != nil {
+		record.StatusCode = http.StatusBadRequest
+		b.writeResponse(w, record.StatusCode, err.Error(), nil)
+		return
+	}
+
+	result, delay := b.injector.next()
+	if !sleep(r, delay) {
+		record.StatusCode = 499
+		return
+	}
+	switch result {
+	case outcomeError:
+		record.StatusCode = http.StatusInternalServerError
+		b.writeResponse(w, record.StatusCode, "injected server error", nil)
+		return
+	case outcomeRateLimited:
+		record.StatusCode = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", "1")
+		b.writeResponse(w, record.StatusCode, "too many requests", nil)
+		return
+	}
+
+	record.StatusCode = http.StatusOK
+	b.mu.Lock()
+	for _, event := range events {
+		if event.IdempotencyKey != "" && b.seenEvents[event.IdempotencyKey] {
+			record.Duplicates++
+			continue
+		}
+		if event.IdempotencyKey != "" {
+			b.seenEvents[event.IdempotencyKey] = true
+		}
+		b.events = append(b.events, event)
+		record.Accepted++
+	}
+	b.mu.Unlock()
+
+	b.writeResponse(w, http.StatusOK, "ok", &backend.BatchResult{Received: len(events), Accepted: record.Accepted})
+}
+
+func (b *BackendServer) writeResponse(w http.ResponseWriter, status int, message string, data *backend.BatchResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(backend.BackendResponse{
+		Code:    status,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// decodeBody reads a JSON body into v, transparently handling gzip encoding
+func decodeBody(r *http.Request, v interface{}) error {
+	body, err := requestBody(r)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("invalid batch body: %w", err)
+	}
+	return nil
+}
+
+// decodeBatch reads a JSON array or NDJSON body, transparently handling gzip encoding
+func decodeBatch(r *http.Request) ([]backend.KeywordMetricsData, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), backend.NDJSONContentType) {
+		var records []backend.KeywordMetricsData
+		if err := decodeBody(r, &records); err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+
+	body, err := requestBody(r)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var records []backend.KeywordMetricsData
+	decoder := json.NewDecoder(body)
+	for {
+		var record backend.KeywordMetricsData
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid NDJSON line %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
+
+// requestBody returns the request body, decompressed when it is gzip encoded
+func requestBody(r *http.Request) (io.ReadCloser, error) {
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		return r.Body, nil
+	}
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip body: %w", err)
+	}
+	return gz, nil
+}
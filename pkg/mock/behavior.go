@@ -0,0 +1,139 @@
+package mock
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Behavior configures failure injection shared by the mock servers
+// Rates are probabilities in the range 0-1 and are evaluated per request
+type Behavior struct {
+	Latency       time.Duration `json:"latency"`         // Fixed delay before every response
+	LatencyJitter time.Duration `json:"latency_jitter"`  // Random extra delay in [0, jitter)
+	ErrorRate     float64       `json:"error_rate"`      // Respond with 500
+	RateLimitRate float64       `json:"rate_limit_rate"` // Respond with 429
+	MalformedRate float64       `json:"malformed_rate"`  // Respond 200 with invalid JSON
+	PartialRate   float64       `json:"partial_rate"`    // Answer only part of a batch
+	Seed          int64         `json:"seed"`            // 0 uses the current time
+}
+
+// outcome is the fault chosen for a single request
+type outcome int
+
+const (
+	outcomeOK outcome = iota
+	outcomeError
+	outcomeRateLimited
+	outcomeMalformed
+	outcomePartial
+)
+
+// injector draws outcomes from a Behavior with its own seeded source
+type injector struct {
+	behavior Behavior
+	rng      *rand.Rand
+	mu       sync.Mutex
+}
+
+func newInjector(behavior Behavior) *injector {
+	seed := behavior.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &injector{
+		behavior: behavior,
+		rng:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// setBehavior replaces the knobs at runtime, keeping the random source
+func (in *injector) setBehavior(behavior Behavior) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.behavior = behavior
+}
+
+// next picks the outcome and delay for one request
+func (in *injector) next() (outcome, time.Duration) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	delay := in.behavior.Latency
+	if in.behavior.LatencyJitter > 0 {
+		delay += time.Duration(in.rng.Int63n(int64(in.behavior.LatencyJitter)))
+	}
+
+	roll := in.rng.Float64()
+	thresholds := []struct {
+		rate    float64
+		outcome outcome
+	}{
+		{in.behavior.ErrorRate, outcomeError},
+		{in.behavior.RateLimitRate, outcomeRateLimited},
+		{in.behavior.MalformedRate, outcomeMalformed},
+		{in.behavior.PartialRate, outcomePartial},
+	}
+	for _, threshold := range thresholds {
+		if roll < threshold.rate {
+			return threshold.outcome, delay
+		}
+		roll -= threshold.rate
+	}
+	return outcomeOK, delay
+}
+
+// keep returns how many of n items a partial response should contain (at least one dropped)
+func (in *injector) keep(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.rng.Intn(n)
+}
+
+// sleep waits for the delay unless the client goes away first
+func sleep(r *http.Request, delay time.Duration) bool {
+	if delay <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(delay):
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// server wraps an http.Server bound to a listener so mocks can run on fixed or random ports
+type server struct {
+	httpServer *http.Server
+	listener   net.Listener
+}
+
+func startServer(addr string, handler http.Handler) (*server, error) {
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &server{
+		httpServer: &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second},
+		listener:   listener,
+	}
+	go s.httpServer.Serve(listener)
+	return s, nil
+}
+
+func (s *server) url() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+func (s *server) close() error {
+	return s.httpServer.Close()
+}
@@ -0,0 +1,199 @@
+package mock
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeywordRequest records one request received by the mock keyword API
+type KeywordRequest struct {
+	Keywords   []string    `json:"keywords"`
+	Language   string      `json:"language,omitempty"` // language= parameter, "" when untargeted
+	Country    string      `json:"country,omitempty"`  // country= parameter, "" when untargeted
+	Query      string      `json:"query"`
+	Header     http.Header `json:"-"`
+	StatusCode int         `json:"status_code"`
+	Answered   []string    `json:"answered"` // Keywords included in the response
+	ReceivedAt time.Time   `json:"received_at"`
+}
+
+// KeywordAPIServer is an in-process server speaking the SEOKey response format
+// Metrics are derived from a hash of the keyword, so repeated runs return identical data
+type KeywordAPIServer struct {
+	server   *server
+	injector *injector
+	months   int
+
+	mu       sync.Mutex
+	requests []KeywordRequest
+}
+
+// NewKeywordAPIServer starts a mock keyword API on addr ("" picks a free local port)
+func NewKeywordAPIServer(addr string, behavior Behavior) (*KeywordAPIServer, error) {
+	k := &KeywordAPIServer{
+		injector: newInjector(behavior),
+		months:   12,
+	}
+	s, err := startServer(addr, http.HandlerFunc(k.handle))
+	if err != nil {
+		return nil, err
+	}
+	k.server = s
+	return k, nil
+}
+
+// URL returns the base URL to use as TRENDS_API_URL
+func (k *KeywordAPIServer) URL() string {
+	return k.server.url()
+}
+
+// SetBehavior changes failure injection while the server is running
+func (k *KeywordAPIServer) SetBehavior(behavior Behavior) {
+	k.injector.setBehavior(behavior)
+}
+
+// Requests returns a copy of everything received so far
+func (k *KeywordAPIServer) Requests() []KeywordRequest {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]KeywordRequest(nil), k.requests...)
+}
+
+// QueriedKeywords returns every keyword requested, in arrival order
+func (k *KeywordAPIServer) QueriedKeywords() []string {
+	var keywords []string
+	for _, req := range k.Requests() {
+		keywords = append(keywords, req.Keywords...)
+	}
+	return keywords
+}
+
+// Reset clears recorded requests
+func (k *KeywordAPIServer) Reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.requests = nil
+}
+
+// Close stops the server
+func (k *KeywordAPIServer) Close() error {
+	return k.server.close()
+}
+
+func (k *KeywordAPIServer) handle(w http.ResponseWriter, r *http.Request) {
+	record := KeywordRequest{
+		Language:   r.URL.Query().Get("language"),
+		Country:    r.URL.Query().Get("country"),
+		Query:      r.URL.RawQuery,
+		Header:     r.Header.Clone(),
+		ReceivedAt: time.Now(),
+	}
+	for _, keyword := range strings.Split(r.URL.Query().Get("keyword"), ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			record.Keywords = append(record.Keywords, keyword)
+		}
+	}
+	defer func() {
+		k.mu.Lock()
+		k.requests = append(k.requests, record)
+		k.mu.Unlock()
+	}()
+
+	result, delay := k.injector.next()
+	if !sleep(r, delay) {
+		record.StatusCode = 499 // client went away
+		return
+	}
+
+	if len(record.Keywords) == 0 {
+		record.StatusCode = http.StatusBadRequest
+		http.Error(w, `{"status":"error","message":"keyword parameter is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	switch result {
+	case outcomeError:
+		record.StatusCode = http.StatusInternalServerError
+		http.Error(w, `{"status":"error","message":"injected server error"}`, http.StatusInternalServerError)
+		return
+	case outcomeRateLimited:
+		record.StatusCode = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, `{"status":"error","message":"too many requests"}`, http.StatusTooManyRequests)
+		return
+	case outcomeMalformed:
+		record.StatusCode = http.StatusOK
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":[{"keyword":`))
+		return
+	}
+
+	answered := record.Keywords
+	if result == outcomePartial {
+		answered = answered[:k.injector.keep(len(answered))]
+	}
+	record.Answered = append([]string(nil), answered...)
+	record.StatusCode = http.StatusOK
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(k.buildResponse(answered, record.Language+"-"+record.Country))
+}
+
+// buildResponse creates a SEOKey-shaped success payload for the keywords
+// The locale feeds the hash so each language/country gets its own stable volumes
+func (k *KeywordAPIServer) buildResponse(keywords []string, locale string) map[string]interface{} {
+	data := make([]map[string]interface{}, 0, len(keywords))
+	now := time.Now()
+	for _, keyword := range keywords {
+		seed := keywordSeed(keyword)
+		if locale != "-" {
+			seed = keywordSeed(keyword + "|" + locale)
+		}
+		base := int64(seed%50000) + 10
+
+		monthly := make([]map[string]interface{}, 0, k.months)
+		var total int64
+		var latest int64
+		for i := k.months; i >= 1; i-- {
+			month := now.AddDate(0, -i, 0)
+			searches := base + int64((seed>>uint(i%16))%uint32(base/2+1))
+			total += searches
+			latest = searches
+			monthly = append(monthly, map[string]interface{}{
+				"year":     month.Year(),
+				"month":    int(month.Month()),
+				"searches": searches,
+			})
+		}
+
+		competition := []string{"LOW", "MEDIUM", "HIGH"}[seed%3]
+		lowBid := int64(seed%900+100) * 1000
+		data = append(data, map[string]interface{}{
+			"keyword": keyword,
+			"metrics": map[string]interface{}{
+				"avg_monthly_searches":       total / int64(k.months),
+				"latest_searches":            latest,
+				"competition":                competition,
+				"competition_index":          int(seed % 101),
+				"low_top_of_page_bid_micro":  lowBid,
+				"high_top_of_page_bid_micro": lowBid * 3,
+				"monthly_searches":           monthly,
+			},
+		})
+	}
+	return map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	}
+}
+
+// keywordSeed hashes a keyword into a stable pseudo-random value
+func keywordSeed(keyword string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(keyword)))
+	return h.Sum32()
+}
@@ -0,0 +1,126 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/backend"
+)
+
+func TestKeywordAPIServer_ServesSEOKeyFormat(t *testing.T) {
+	server, err := NewKeywordAPIServer("", Behavior{Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock keyword API: %v", err)
+	}
+	defer server.Close()
+
+	client := api.NewHTTPAPIClient(server.URL(), "")
+	resp, err := client.Query(context.Background(), []string{"super mario", "tetris"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if len(resp.Keywords) != 2 {
+		t.Fatalf("Expected 2 keywords, got %d", len(resp.Keywords))
+	}
+	if len(resp.Keywords[0].MonthlySearches) != 12 {
+		t.Errorf("Expected 12 months of history, got %d", len(resp.Keywords[0].MonthlySearches))
+	}
+	if got := server.QueriedKeywords(); len(got) != 2 || got[0] != "super mario" {
+		t.Errorf("Unexpected recorded keywords: %v", got)
+	}
+
+	// Same keyword must produce the same metrics across requests
+	again, err := client.Query(context.Background(), []string{"super mario"})
+	if err != nil {
+		t.Fatalf("Second query failed: %v", err)
+	}
+	if again.Keywords[0].SearchVolume != resp.Keywords[0].SearchVolume {
+		t.Errorf("Expected deterministic volume, got %d and %d", resp.Keywords[0].SearchVolume, again.Keywords[0].SearchVolume)
+	}
+}
+
+func TestKeywordAPIServer_PartialBatch(t *testing.T) {
+	server, err := NewKeywordAPIServer("", Behavior{PartialRate: 1, Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock keyword API: %v", err)
+	}
+	defer server.Close()
+
+	client := api.NewHTTPAPIClient(server.URL(), "")
+	resp, err := client.Query(context.Background(), []string{"a", "b", "c", "d"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(resp.Keywords) >= 4 {
+		t.Errorf("Expected a partial response, got %d keywords", len(resp.Keywords))
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 || len(requests[0].Answered) != len(resp.Keywords) {
+		t.Errorf("Recorded answer does not match response: %+v", requests)
+	}
+}
+
+func TestBackendServer_RecordsSubmissions(t *testing.T) {
+	server, err := NewBackendServer("", "test-key", Behavior{Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+
+	client, err := backend.NewBackendClient(backend.BackendConfig{
+		BaseURL:    server.URL(),
+		APIKey:     "test-key",
+		BatchSize:  2,
+		EnableGzip: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create backend client: %v", err)
+	}
+
+	data := []backend.KeywordMetricsData{
+		{Keyword: "one", URL: "https://example.com/one"},
+		{Keyword: "two", URL: "https://example.com/two"},
+		{Keyword: "three", URL: "https://example.com/three"},
+	}
+	if _, err := client.SubmitBatches(data); err != nil {
+		t.Fatalf("SubmitBatches failed: %v", err)
+	}
+
+	if got := len(server.Stored()); got != 3 {
+		t.Errorf("Expected 3 stored records, got %d", got)
+	}
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 batch requests, got %d", len(requests))
+	}
+	if requests[0].ContentEncoding != "gzip" {
+		t.Errorf("Expected gzip encoding, got %q", requests[0].ContentEncoding)
+	}
+}
+
+func TestBackendServer_InjectedErrors(t *testing.T) {
+	server, err := NewBackendServer("", "", Behavior{ErrorRate: 1, Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to start mock backend: %v", err)
+	}
+	defer server.Close()
+
+	client, err := backend.NewBackendClient(backend.BackendConfig{
+		BaseURL: server.URL(),
+		APIKey:  "any",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create backend client: %v", err)
+	}
+
+	_, err = client.SubmitBatch(backend.KeywordMetricsBatch{{Keyword: "one"}})
+	if err == nil {
+		t.Fatal("Expected error from injected 500")
+	}
+	if len(server.Stored()) != 0 {
+		t.Errorf("Expected nothing stored on error")
+	}
+}
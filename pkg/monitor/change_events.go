@@ -0,0 +1,375 @@
+package monitor
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/detector"
+	"sitemap-go/pkg/parser"
+	"sitemap-go/pkg/storage"
+)
+
+// changeSnapshotRetention is how many snapshots are kept per sitemap
+const changeSnapshotRetention = 10
+
+// changeTracker keeps a URL snapshot per sitemap so each run can tell what changed since the last
+type changeTracker struct {
+	history     *detector.URLHistoryManager
+	detector    *detector.URLChangeDetector
+	removals    *storage.RemovalLedger
+	events      bool // Submit the changes and confirmed removals to the backend as change events
+	changedOnly bool // Extract and query only pages added or modified since the last snapshot
+}
+
+func newChangeTracker(store storage.Storage) *changeTracker {
+	history := detector.NewURLHistoryManager(store)
+	history.SetRetention(changeSnapshotRetention)
+	return &changeTracker{
+		history:  history,
+		detector: detector.NewURLChangeDetector(store),
+		removals: storage.NewRemovalLedger(store, storage.DefaultRemovalGrace),
+	}
+}
+
+// SitemapChanges counts the pages of a sitemap that changed since its previous snapshot
+type SitemapChanges struct {
+	Added              int        `json:"added"`
+	Removed            int        `json:"removed"`
+	Modified           int        `json:"modified"`
+	FirstSnapshot      bool       `json:"first_snapshot,omitempty"` // Nothing to compare with yet
+	PreviousSnapshotAt *time.Time `json:"previous_snapshot_at,omitempty"`
+}
+
+// sitemapDiff is what comparing a sitemap with its previous snapshot found
+type sitemapDiff struct {
+	changeSet  *detector.ChangeSet // nil for a sitemap's first snapshot
+	previousAt *time.Time
+}
+
+// EnableChangeEvents submits the added, removed and modified URLs every run finds to the
+// backend as change events, along with delisting notices for confirmed removals
+func (sm *SitemapMonitor) EnableChangeEvents() {
+	sm.changeTracker.events = true
+}
+
+// SetChangedOnly makes runs extract and query only the pages added or modified since a
+// sitemap's previous snapshot; modified pages are queried again even if an earlier run did.
+// A sitemap's first snapshot processes every page. Changed pages past the URL cap are left
+// for the next runs
+func (sm *SitemapMonitor) SetChangedOnly(enabled bool) {
+	sm.changeTracker.changedOnly = enabled
+}
+
+// SetRemovalGrace sets how long a page must stay missing from its sitemap, over at least two
+// runs, before its removal is confirmed and delisted
+func (sm *SitemapMonitor) SetRemovalGrace(grace time.Duration) {
+	sm.changeTracker.removals.SetGrace(grace)
+}
+
+// trackSitemapChanges saves urls as the sitemap's new snapshot and compares it with the previous
+// one. It returns nil when nothing was tracked: an empty parse keeps the old snapshot so a briefly
+// empty sitemap does not remove every page, and a failed save is not compared so the next run
+// reports the changes instead
+// With -changed-only, limit (0 = none) caps the changed pages this run takes; the rest are left
+// for a later run, see deferChangesBeyond
+func (sm *SitemapMonitor) trackSitemapChanges(ctx context.Context, sitemapURL string, urls []parser.URL, limit int) *sitemapDiff {
+	if sm.changeTracker == nil || len(urls) == 0 {
+		return nil
+	}
+	history := sm.changeTracker.history
+	name := snapshotName(sitemapURL)
+
+	previous, err := history.GetLatestSnapshot(ctx, name)
+	hasPrevious := err == nil
+	diff := &sitemapDiff{}
+	if hasPrevious {
+		if snapshots, err := history.GetSnapshotHistory(ctx, name, 1); err == nil && len(snapshots) > 0 {
+			diff.previousAt = &snapshots[0].Timestamp
+		}
+		if diff.changeSet, err = sm.changeTracker.detector.DetectChanges(ctx, previous, urls); err != nil {
+			return nil
+		}
+	}
+	snapshot := urls
+	if sm.changeTracker.changedOnly && limit > 0 {
+		snapshot, diff.changeSet = deferChangesBeyond(previous, urls, diff.changeSet, limit)
+	}
+
+	// Without the new snapshot the next run would report the same changes again under a new run ID
+	if err := history.SaveSnapshot(ctx, name, snapshot); err != nil {
+		sm.secureLog.WarnWithURL("Failed to save sitemap snapshot, changes not tracked", sitemapURL, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	if !hasPrevious {
+		sm.secureLog.DebugWithURL("First snapshot of sitemap saved", sitemapURL, nil)
+	}
+	return diff
+}
+
+// deferChangesBeyond keeps the first limit changed pages of urls, in sitemap order, and returns
+// the snapshot to save with the change set left to them. Pages past the limit keep their previous
+// state in the snapshot (added ones stay out of it), so a later run finds them changed again
+// instead of skipping them as unchanged. On a first snapshot (nil changeSet) every page is new
+func deferChangesBeyond(previous, urls []parser.URL, changeSet *detector.ChangeSet, limit int) ([]parser.URL, *detector.ChangeSet) {
+	changed := make(map[string]bool)
+	if changeSet != nil {
+		for _, change := range changeSet.Changes {
+			if change.Type != detector.ChangeTypeRemoved {
+				changed[change.URL.Address] = true
+			}
+		}
+	}
+	if len(urls) <= limit || changeSet != nil && len(changed) <= limit {
+		return urls, changeSet
+	}
+	previousByURL := make(map[string]parser.URL, len(previous))
+	for _, u := range previous {
+		previousByURL[u.Address] = u
+	}
+
+	snapshot := make([]parser.URL, 0, len(urls))
+	deferred := make(map[string]bool)
+	taken := 0
+	for _, u := range urls {
+		if changeSet != nil && !changed[u.Address] {
+			snapshot = append(snapshot, u)
+			continue
+		}
+		if taken < limit {
+			taken++
+			snapshot = append(snapshot, u)
+			continue
+		}
+		deferred[u.Address] = true
+		if old, ok := previousByURL[u.Address]; ok {
+			snapshot = append(snapshot, old)
+		}
+	}
+	if changeSet == nil {
+		return snapshot, nil
+	}
+
+	kept := *changeSet
+	kept.Changes = make([]detector.URLChange, 0, len(changeSet.Changes)-len(deferred))
+	for _, change := range changeSet.Changes {
+		if deferred[change.URL.Address] {
+			switch change.Type {
+			case detector.ChangeTypeAdded:
+				kept.TotalAdded--
+			case detector.ChangeTypeModified:
+				kept.TotalModified--
+			}
+			continue
+		}
+		kept.Changes = append(kept.Changes, change)
+	}
+	return snapshot, &kept
+}
+
+// counts summarises the diff for MonitorResult
+func (d *sitemapDiff) counts() *SitemapChanges {
+	if d == nil {
+		return nil
+	}
+	if d.changeSet == nil {
+		return &SitemapChanges{FirstSnapshot: true}
+	}
+	return &SitemapChanges{
+		Added:              d.changeSet.TotalAdded,
+		Removed:            d.changeSet.TotalRemoved,
+		Modified:           d.changeSet.TotalModified,
+		PreviousSnapshotAt: d.previousAt,
+	}
+}
+
+// changedURLs keeps the pages of urls the diff reports as added or modified, in sitemap order,
+// and returns the modified ones separately
+func (d *sitemapDiff) changedURLs(urls []parser.URL) ([]parser.URL, map[string]bool) {
+	changed := make(map[string]detector.ChangeType, len(d.changeSet.Changes))
+	for _, change := range d.changeSet.Changes {
+		if change.Type != detector.ChangeTypeRemoved {
+			changed[change.URL.Address] = change.Type
+		}
+	}
+	kept := make([]parser.URL, 0, len(changed))
+	modified := make(map[string]bool)
+	for _, u := range urls {
+		changeType, ok := changed[u.Address]
+		if !ok {
+			continue
+		}
+		kept = append(kept, u)
+		if changeType == detector.ChangeTypeModified {
+			modified[u.Address] = true
+		}
+	}
+	return kept, modified
+}
+
+// reportSitemapChanges queues a change event for every difference the diff found, when change
+// events are enabled. A sitemap's first snapshot produces no events
+// keywordByURL holds the query keywords extracted for the sitemap's pages
+func (sm *SitemapMonitor) reportSitemapChanges(ctx context.Context, sitemapURL string, diff *sitemapDiff, keywordByURL map[string]string) {
+	if sm.changeTracker == nil || !sm.changeTracker.events || diff == nil || diff.changeSet == nil || len(diff.changeSet.Changes) == 0 {
+		return
+	}
+	changeSet, previousAt := diff.changeSet, diff.previousAt
+	events := make([]backend.ChangeEvent, 0, len(changeSet.Changes))
+	runID := sm.currentRun(ctx).id
+	for _, change := range changeSet.Changes {
+		events = append(events, backend.ChangeEvent{
+			URL:                change.URL.Address,
+			Domain:             pageDomain(change.URL.Address),
+			SitemapURL:         sitemapURL,
+			ChangeType:         string(change.Type),
+			Keyword:            sm.changeKeyword(change.URL.Address, keywordByURL),
+			LastModified:       change.URL.LastUpdated,
+			DetectedAt:         change.Timestamp,
+			PreviousSnapshotAt: previousAt,
+			RunID:              runID,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].ChangeType != events[j].ChangeType {
+			return events[i].ChangeType < events[j].ChangeType
+		}
+		return events[i].URL < events[j].URL
+	})
+
+	submitCtx, cancel := context.WithTimeout(ctx, submissionQueueWait)
+	defer cancel()
+	err := sm.submissionPool.SubmitChangeEvents(submitCtx, events, func(err error) {
+		if err != nil {
+			sm.secureLog.SafeError("Change event submission failed", err, nil)
+		}
+	})
+	if err != nil {
+		sm.log.WithError(err).WithField("events", len(events)).Warn("Failed to queue change events")
+		return
+	}
+	sm.currentRun(ctx).stats.update(func(summary *RunSummary) { summary.ChangeEvents += len(events) })
+	sm.secureLog.InfoWithURL("Sitemap changes queued for the backend", sitemapURL, map[string]interface{}{
+		"added":    changeSet.TotalAdded,
+		"removed":  changeSet.TotalRemoved,
+		"modified": changeSet.TotalModified,
+	})
+}
+
+// trackRemovals feeds a tracked sitemap into the removal ledger and reports confirmed
+// removals and reappeared pages as delisting notices when change events are enabled
+// urls must be every page the sitemap lists, so pages skipped by -changed-only are not missing
+func (sm *SitemapMonitor) trackRemovals(ctx context.Context, sitemapURL string, urls []parser.URL, diff *sitemapDiff) {
+	if sm.changeTracker == nil || sm.changeTracker.removals == nil || diff == nil {
+		return
+	}
+	observation := storage.RemovalObservation{
+		SitemapURL: sitemapURL,
+		Present:    make([]string, len(urls)),
+		At:         time.Now(),
+	}
+	for i, u := range urls {
+		observation.Present[i] = u.Address
+	}
+	if diff.previousAt != nil {
+		observation.PreviousAt = *diff.previousAt
+	}
+	if diff.changeSet != nil {
+		for _, change := range diff.changeSet.Changes {
+			if change.Type == detector.ChangeTypeRemoved {
+				observation.Removed = append(observation.Removed, change.URL.Address)
+			}
+		}
+	}
+
+	update, err := sm.changeTracker.removals.Observe(ctx, observation)
+	if err != nil {
+		sm.secureLog.WarnWithURL("Failed to update removal ledger", sitemapURL, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	sm.currentRun(ctx).stats.update(func(summary *RunSummary) {
+		summary.ConfirmedRemovals += len(update.Confirmed)
+		summary.ReappearedURLs += len(update.Reappeared)
+		summary.PendingRemovals += update.Pending
+	})
+	if !sm.changeTracker.events || len(update.Confirmed)+len(update.Reappeared) == 0 {
+		return
+	}
+
+	events := make([]backend.ChangeEvent, 0, len(update.Confirmed)+len(update.Reappeared))
+	notice := func(removed storage.RemovedURL, changeType string) backend.ChangeEvent {
+		lastSeen := removed.LastSeen
+		event := backend.ChangeEvent{
+			URL:        removed.URL,
+			Domain:     pageDomain(removed.URL),
+			SitemapURL: sitemapURL,
+			ChangeType: changeType,
+			Keyword:    sm.changeKeyword(removed.URL, nil),
+			DetectedAt: observation.At,
+			LastSeen:   &lastSeen,
+			RunID:      sm.currentRun(ctx).id,
+		}
+		if !removed.FirstSeen.IsZero() {
+			firstSeen := removed.FirstSeen
+			event.FirstSeen = &firstSeen
+		}
+		return event
+	}
+	for _, removed := range update.Confirmed {
+		events = append(events, notice(removed, backend.ChangeDelisted))
+	}
+	for _, reappeared := range update.Reappeared {
+		events = append(events, notice(reappeared, backend.ChangeRelisted))
+	}
+
+	submitCtx, cancel := context.WithTimeout(ctx, submissionQueueWait)
+	defer cancel()
+	err = sm.submissionPool.SubmitChangeEvents(submitCtx, events, func(err error) {
+		if err != nil {
+			sm.secureLog.SafeError("Delisting notice submission failed", err, nil)
+		}
+	})
+	if err != nil {
+		sm.log.WithError(err).WithField("events", len(events)).Warn("Failed to queue delisting notices")
+		return
+	}
+	sm.currentRun(ctx).stats.update(func(summary *RunSummary) { summary.ChangeEvents += len(events) })
+	sm.secureLog.InfoWithURL("Delisting notices queued", sitemapURL, map[string]interface{}{
+		"delisted": len(update.Confirmed),
+		"relisted": len(update.Reappeared),
+	})
+}
+
+// changeKeyword returns the query keyword of a page; removed pages are no longer extracted, so
+// their keyword is derived from the URL the same way
+func (sm *SitemapMonitor) changeKeyword(pageURL string, keywordByURL map[string]string) string {
+	if keyword, ok := keywordByURL[pageURL]; ok {
+		return keyword
+	}
+	keywords, err := sm.keywordExtractor.Extract(pageURL)
+	if err != nil || len(keywords) == 0 {
+		return ""
+	}
+	return sm.formatKeywordForAPI(sm.selectPrimaryKeyword(keywords))
+}
+
+// snapshotName turns a sitemap URL into a storage-safe snapshot name
+func snapshotName(sitemapURL string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(sitemapURL, "https://"), "http://")
+	return strings.NewReplacer("/", "_", ":", "_", "?", "_").Replace(name)
+}
+
+func pageDomain(pageURL string) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
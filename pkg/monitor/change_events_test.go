@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/extractor"
+	"sitemap-go/pkg/logger"
+	"sitemap-go/pkg/parser"
+	"sitemap-go/pkg/storage"
+)
+
+func TestTrackSitemapChanges(t *testing.T) {
+	ctx := context.Background()
+	sm := &SitemapMonitor{
+		changeTracker: newChangeTracker(storage.NewMemoryStorage()),
+		secureLog:     logger.GetSecurityLogger(),
+	}
+	sitemapURL := "https://poki.com/sitemap.xml"
+
+	first := sm.trackSitemapChanges(ctx, sitemapURL, []parser.URL{
+		{Address: "https://poki.com/g/subway-surfers", LastUpdated: "2026-10-01"},
+		{Address: "https://poki.com/g/temple-run"},
+	}, 0)
+	if counts := first.counts(); counts == nil || !counts.FirstSnapshot {
+		t.Fatalf("Expected a first snapshot, got %+v", counts)
+	}
+
+	current := []parser.URL{
+		{Address: "https://poki.com/g/moto-x3m"},
+		{Address: "https://poki.com/g/subway-surfers", LastUpdated: "2026-10-18"},
+	}
+	diff := sm.trackSitemapChanges(ctx, sitemapURL, current, 0)
+	counts := diff.counts()
+	if counts == nil || counts.FirstSnapshot || counts.Added != 1 || counts.Removed != 1 || counts.Modified != 1 {
+		t.Fatalf("Unexpected change counts: %+v", counts)
+	}
+
+	changed, modified := diff.changedURLs(current)
+	if len(changed) != 2 || changed[0].Address != "https://poki.com/g/moto-x3m" {
+		t.Errorf("Expected added and modified pages in sitemap order, got %+v", changed)
+	}
+	if len(modified) != 1 || !modified["https://poki.com/g/subway-surfers"] {
+		t.Errorf("Expected only the republished page as modified, got %v", modified)
+	}
+
+	if sm.trackSitemapChanges(ctx, sitemapURL, nil, 0) != nil {
+		t.Error("An empty parse must not replace the snapshot")
+	}
+}
+
+func TestChangedOnlyLeavesCappedChangesForLaterRuns(t *testing.T) {
+	ctx := context.Background()
+	sm := &SitemapMonitor{
+		changeTracker: newChangeTracker(storage.NewMemoryStorage()),
+		secureLog:     logger.GetSecurityLogger(),
+	}
+	sm.SetChangedOnly(true)
+	sitemapURL := "https://poki.com/sitemap.xml"
+	page := func(name, lastmod string) parser.URL {
+		return parser.URL{Address: "https://poki.com/g/" + name, LastUpdated: lastmod}
+	}
+	// changedPages returns the pages a run with a cap of 2 takes
+	changedPages := func(urls []parser.URL) []string {
+		diff := sm.trackSitemapChanges(ctx, sitemapURL, urls, 2)
+		if diff.changeSet == nil {
+			urls = urls[:2]
+		} else {
+			urls, _ = diff.changedURLs(urls)
+		}
+		names := make([]string, len(urls))
+		for i, u := range urls {
+			names[i] = strings.TrimPrefix(u.Address, "https://poki.com/g/")
+		}
+		return names
+	}
+
+	runs := []struct {
+		urls []parser.URL
+		want []string
+	}{
+		{[]parser.URL{page("a", "1"), page("b", "1"), page("c", "1")}, []string{"a", "b"}},
+		{[]parser.URL{page("a", "2"), page("b", "2"), page("c", "1"), page("d", "1")}, []string{"a", "b"}},
+		{[]parser.URL{page("a", "2"), page("b", "2"), page("c", "1"), page("d", "1")}, []string{"c", "d"}},
+		{[]parser.URL{page("a", "2"), page("b", "2"), page("c", "1"), page("d", "1")}, []string{}},
+		{[]parser.URL{page("a", "3"), page("b", "3"), page("c", "3"), page("d", "3")}, []string{"a", "b"}},
+		{[]parser.URL{page("a", "3"), page("b", "3"), page("c", "3"), page("d", "3")}, []string{"c", "d"}},
+	}
+	for i, run := range runs {
+		if got := changedPages(run.urls); strings.Join(got, ",") != strings.Join(run.want, ",") {
+			t.Errorf("Run %d: expected pages %v, got %v", i+1, run.want, got)
+		}
+	}
+}
+
+func TestTrackRemovalsSendsDelistingNotices(t *testing.T) {
+	ctx := context.Background()
+	recorder := backend.NewRecordingClient()
+	pool := backend.NewSubmissionPool(recorder, 1)
+	pool.Start(ctx)
+	defer pool.Stop()
+	sm := &SitemapMonitor{
+		changeTracker:    newChangeTracker(storage.NewMemoryStorage()),
+		keywordExtractor: extractor.NewURLKeywordExtractor(),
+		submissionPool:   pool,
+		log:              logger.GetLogger(),
+		secureLog:        logger.GetSecurityLogger(),
+	}
+	sm.EnableChangeEvents()
+	sm.SetRemovalGrace(0)
+	sitemapURL := "https://poki.com/sitemap.xml"
+	all := []parser.URL{{Address: "https://poki.com/g/subway-surfers"}, {Address: "https://poki.com/g/temple-run"}}
+
+	run := func(urls []parser.URL) {
+		sm.trackRemovals(ctx, sitemapURL, urls, sm.trackSitemapChanges(ctx, sitemapURL, urls, 0))
+	}
+	run(all)
+	run(all[:1]) // Missing once: pending
+	if summary := sm.currentRun(ctx).stats.snapshot(); summary.PendingRemovals != 1 || summary.ConfirmedRemovals != 0 {
+		t.Fatalf("Expected one pending removal after a single miss, got %+v", summary)
+	}
+	run(all[:1])
+	run(all)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	pool.WaitIdle(waitCtx)
+	events := recorder.ChangeEvents()
+	if len(events) != 2 {
+		t.Fatalf("Expected a delisted and a relisted notice, got %+v", events)
+	}
+	delisted, relisted := events[0], events[1]
+	if delisted.ChangeType != backend.ChangeDelisted || delisted.URL != "https://poki.com/g/temple-run" || delisted.Keyword != "temple run" {
+		t.Errorf("Unexpected delisting notice: %+v", delisted)
+	}
+	if delisted.FirstSeen == nil || delisted.LastSeen == nil || delisted.LastSeen.Before(*delisted.FirstSeen) {
+		t.Errorf("Expected first-seen and last-seen timestamps, got %+v", delisted)
+	}
+	if relisted.ChangeType != backend.ChangeRelisted || relisted.URL != delisted.URL {
+		t.Errorf("Unexpected relisting notice: %+v", relisted)
+	}
+	if summary := sm.currentRun(ctx).stats.snapshot(); summary.ConfirmedRemovals != 1 || summary.ReappearedURLs != 1 {
+		t.Errorf("Unexpected removal counters: %+v", summary)
+	}
+}
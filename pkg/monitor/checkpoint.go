@@ -0,0 +1,263 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/logger"
+	"sitemap-go/pkg/storage"
+)
+
+// checkpointSaveInterval bounds how often progress is written: every write stores the whole
+// checkpoint. Interrupting a run always writes the latest progress
+const checkpointSaveInterval = 5 * time.Second
+
+// runCheckpoint records the progress of the current run so an interrupted run can be resumed
+// A nil runCheckpoint records nothing
+type runCheckpoint struct {
+	store    *storage.CheckpointStore
+	state    *storage.Checkpoint
+	resumed  bool
+	queried  map[string]bool
+	lastSave time.Time
+	mu       sync.Mutex
+	log      *logger.Logger
+}
+
+// EnableCheckpoints records every run's progress in encrypted storage; resume continues the
+// last interrupted run, if its checkpoint is younger than maxAge (0 = default), instead of
+// starting over
+func (sm *SitemapMonitor) EnableCheckpoints(resume bool, maxAge time.Duration) {
+	sm.checkpoints = storage.NewCheckpointStore(sm.storage, maxAge)
+	sm.resume = resume
+}
+
+// beginCheckpoint starts the run's checkpoint; a resumed checkpoint also restores its run ID
+func (sm *SitemapMonitor) beginCheckpoint(ctx context.Context, run *monitorRun, sitemapURLs []string) *runCheckpoint {
+	if sm.checkpoints == nil {
+		return nil
+	}
+	rc := &runCheckpoint{
+		store:   sm.checkpoints,
+		queried: make(map[string]bool),
+		log:     logger.GetLogger().WithField("component", "checkpoint"),
+	}
+	if sm.resume {
+		saved := sm.checkpoints.Load(ctx)
+		if saved != nil && !sameSitemaps(saved.Sitemaps, sitemapURLs) {
+			rc.log.WithField("run_id", saved.RunID).Warn("Checkpoint covers other sitemaps, starting a fresh run")
+			saved = nil
+		}
+		if saved != nil {
+			rc.state, rc.resumed = saved, true
+			for _, keyword := range saved.Queried {
+				rc.queried[keyword] = true
+			}
+			run.id = saved.RunID
+			rc.log.WithFields(map[string]interface{}{
+				"run_id":              saved.RunID,
+				"started":             saved.CreatedAt.Format(time.RFC3339),
+				"parsed_sitemaps":     len(saved.Parsed),
+				"keywords":            len(saved.Keywords),
+				"queried_keywords":    len(saved.Queried),
+				"pending_submissions": saved.PendingSubmissions,
+			}).Info("♻️ Resuming interrupted run from checkpoint")
+			return rc
+		}
+		rc.log.Info("No checkpoint to resume, starting a fresh run")
+	}
+
+	// A fresh run replaces any older checkpoint
+	rc.state = &storage.Checkpoint{
+		RunID:     run.id,
+		CreatedAt: time.Now(),
+		Sitemaps:  sitemapURLs,
+		Parsed:    make(map[string]storage.SitemapCheckpoint),
+	}
+	rc.mu.Lock()
+	rc.saveLocked(ctx)
+	rc.mu.Unlock()
+	return rc
+}
+
+// parsedSitemap returns the extraction results a resumed checkpoint holds for a sitemap
+func (rc *runCheckpoint) parsedSitemap(sitemapURL string) ([]string, []string, map[string]URLSignals, bool) {
+	if rc == nil || !rc.resumed {
+		return nil, nil, nil, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	saved, ok := rc.state.Parsed[sitemapURL]
+	if !ok {
+		return nil, nil, nil, false
+	}
+	signals := make(map[string]URLSignals, len(saved.Pages))
+	for pageURL, page := range saved.Pages {
+		signals[pageURL] = URLSignals{Priority: page.Priority, LastMod: page.LastMod, HrefLang: page.HrefLang}
+	}
+	return saved.Keywords, saved.URLs, signals, true
+}
+
+// recordSitemap records the extraction results of a sitemap
+func (rc *runCheckpoint) recordSitemap(ctx context.Context, sitemapURL string, keywords, urls []string, signals map[string]URLSignals) {
+	if rc == nil {
+		return
+	}
+	pages := make(map[string]storage.CheckpointPage, len(signals))
+	for pageURL, signal := range signals {
+		pages[pageURL] = storage.CheckpointPage{Priority: signal.Priority, LastMod: signal.LastMod, HrefLang: signal.HrefLang}
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.state.Parsed[sitemapURL] = storage.SitemapCheckpoint{Keywords: keywords, URLs: urls, Pages: pages}
+	rc.saveThrottledLocked(ctx)
+}
+
+// resumeKeywords adds the keywords a resumed checkpoint was going to query (pending keywords
+// taken by the interrupted run among them) and drops those it already queried
+// Returns the keywords left to query and how many were skipped as already queried
+func (rc *runCheckpoint) resumeKeywords(keywords []string, keywordToSpecificURLMap, keywordToSitemapMap map[string]string) ([]string, int) {
+	if rc == nil || !rc.resumed {
+		return keywords, 0
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	seen := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		seen[keyword] = true
+	}
+	for _, keyword := range rc.state.Keywords {
+		if seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		keywords = append(keywords, keyword)
+		if keywordToSpecificURLMap[keyword] == "" {
+			keywordToSpecificURLMap[keyword] = rc.state.KeywordURLs[keyword]
+		}
+		if keywordToSitemapMap[keyword] == "" {
+			keywordToSitemapMap[keyword] = rc.state.KeywordSitemaps[keyword]
+		}
+	}
+
+	return rc.dropQueriedLocked(keywords)
+}
+
+// dropQueried removes the keywords the interrupted run already queried
+// Returns the keywords left to query and how many were dropped
+func (rc *runCheckpoint) dropQueried(keywords []string) ([]string, int) {
+	if rc == nil || !rc.resumed {
+		return keywords, 0
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.dropQueriedLocked(keywords)
+}
+
+func (rc *runCheckpoint) dropQueriedLocked(keywords []string) ([]string, int) {
+	remaining := keywords[:0]
+	skipped := 0
+	for _, keyword := range keywords {
+		if rc.queried[keyword] {
+			skipped++
+			continue
+		}
+		remaining = append(remaining, keyword)
+	}
+	return remaining, skipped
+}
+
+// addKeywords records keywords queued for querying, so a resumed run still has those that
+// did not come from a sitemap (pending keywords taken from storage)
+func (rc *runCheckpoint) addKeywords(ctx context.Context, candidates []KeywordCandidate) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.state.KeywordURLs == nil {
+		rc.state.KeywordURLs = make(map[string]string)
+		rc.state.KeywordSitemaps = make(map[string]string)
+	}
+	for _, candidate := range candidates {
+		if _, known := rc.state.KeywordSitemaps[candidate.Keyword]; known {
+			continue
+		}
+		rc.state.Keywords = append(rc.state.Keywords, candidate.Keyword)
+		rc.state.KeywordURLs[candidate.Keyword] = candidate.URL
+		rc.state.KeywordSitemaps[candidate.Keyword] = candidate.SitemapURL
+	}
+	rc.saveThrottledLocked(ctx)
+}
+
+// recordQueried marks a batch whose records were handed over for submission
+func (rc *runCheckpoint) recordQueried(ctx context.Context, batch []string) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, keyword := range batch {
+		if !rc.queried[keyword] {
+			rc.queried[keyword] = true
+			rc.state.Queried = append(rc.state.Queried, keyword)
+		}
+	}
+	rc.saveThrottledLocked(ctx)
+}
+
+// finish keeps the checkpoint of an interrupted run for -resume and removes that of a finished one
+func (rc *runCheckpoint) finish(ctx context.Context, interrupted bool, pendingSubmissions int) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !interrupted {
+		if err := rc.store.Clear(ctx); err != nil {
+			rc.log.WithError(err).Warn("Failed to remove checkpoint of finished run")
+		}
+		return
+	}
+	rc.state.PendingSubmissions = pendingSubmissions
+	rc.saveLocked(ctx)
+	rc.log.WithFields(map[string]interface{}{
+		"run_id":           rc.state.RunID,
+		"parsed_sitemaps":  len(rc.state.Parsed),
+		"queried_keywords": len(rc.state.Queried),
+	}).Warn("Run interrupted, checkpoint kept for -resume")
+}
+
+// saveThrottledLocked writes progress at most every checkpointSaveInterval
+func (rc *runCheckpoint) saveThrottledLocked(ctx context.Context) {
+	if time.Since(rc.lastSave) >= checkpointSaveInterval {
+		rc.saveLocked(ctx)
+	}
+}
+
+func (rc *runCheckpoint) saveLocked(ctx context.Context) {
+	rc.lastSave = time.Now()
+	if err := rc.store.Save(ctx, rc.state); err != nil {
+		rc.log.WithError(err).Warn("Failed to save checkpoint")
+	}
+}
+
+// sameSitemaps reports whether a checkpoint was taken for the same sitemap list, in any order
+func sameSitemaps(saved, requested []string) bool {
+	if len(saved) != len(requested) {
+		return false
+	}
+	counts := make(map[string]int, len(saved))
+	for _, sitemapURL := range saved {
+		counts[sitemapURL]++
+	}
+	for _, sitemapURL := range requested {
+		if counts[sitemapURL] == 0 {
+			return false
+		}
+		counts[sitemapURL]--
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/storage"
+)
+
+func TestCheckpointWritesAreThrottled(t *testing.T) {
+	ctx := context.Background()
+	sm := &SitemapMonitor{storage: storage.NewMemoryStorage()}
+	sm.EnableCheckpoints(false, 0)
+	sitemapURL := "https://poki.com/sitemap.xml"
+	run := &monitorRun{id: "run-1"}
+	rc := sm.beginCheckpoint(ctx, run, []string{sitemapURL})
+
+	rc.recordSitemap(ctx, sitemapURL, []string{"subway surfers"}, []string{"https://poki.com/g/subway-surfers"}, nil)
+	rc.addKeywords(ctx, []KeywordCandidate{{Keyword: "subway surfers", URL: "https://poki.com/g/subway-surfers", SitemapURL: sitemapURL}})
+	rc.recordQueried(ctx, []string{"subway surfers"})
+	if saved := sm.checkpoints.Load(ctx); saved == nil || len(saved.Parsed) != 0 || len(saved.Keywords) != 0 || len(saved.Queried) != 0 {
+		t.Fatalf("Expected only the initial checkpoint written within the save interval, got %+v", saved)
+	}
+
+	// Once the interval has passed the next change writes everything recorded so far
+	rc.lastSave = time.Now().Add(-checkpointSaveInterval)
+	rc.addKeywords(ctx, []KeywordCandidate{{Keyword: "temple run", URL: "https://poki.com/g/temple-run", SitemapURL: sitemapURL}})
+	saved := sm.checkpoints.Load(ctx)
+	if saved == nil || len(saved.Parsed) != 1 || len(saved.Keywords) != 2 || len(saved.Queried) != 1 {
+		t.Fatalf("Expected the recorded progress written after the interval, got %+v", saved)
+	}
+
+	// An interrupted run writes its latest progress regardless of the interval
+	rc.recordQueried(ctx, []string{"temple run"})
+	rc.finish(ctx, true, 0)
+	if saved := sm.checkpoints.Load(ctx); saved == nil || saved.RunID != "run-1" || len(saved.Queried) != 2 {
+		t.Errorf("Expected the interrupted run's progress kept, got %+v", saved)
+	}
+}
@@ -73,6 +73,7 @@ func DefaultConcurrencyConfig() ConcurrencyConfig {
 // AdaptiveConcurrencyManager manages dynamic concurrency adjustment
 type AdaptiveConcurrencyManager struct {
 	config        ConcurrencyConfig
+	configured    ConcurrencyConfig // Worker growth stops at the larger of these and the built-in caps, API rate growth at this rate
 	mu            sync.RWMutex
 	errorRate     float64
 	responseTime  time.Duration
@@ -88,6 +89,7 @@ type AdaptiveConcurrencyManager struct {
 func NewAdaptiveConcurrencyManager(config ConcurrencyConfig) *AdaptiveConcurrencyManager {
 	return &AdaptiveConcurrencyManager{
 		config:         config,
+		configured:     config,
 		log:            logger.GetLogger().WithField("component", "concurrency_manager"),
 		lastAdjustment: time.Now(),
 	}
@@ -144,7 +146,7 @@ func (acm *AdaptiveConcurrencyManager) adjustConcurrency() {
 		
 	} else if acm.errorRate < 0.02 && acm.responseTime < 2*time.Second {
 		// 增加sitemap并发
-		acm.config.MainWorkers = min(20, acm.config.MainWorkers+2) // 最多20个sitemap worker
+		acm.config.MainWorkers = min(max(20, acm.configured.MainWorkers), acm.config.MainWorkers+2) // 最多20个sitemap worker
 		acm.config.ParseWorkers = min(15, acm.config.ParseWorkers+1) // 最多15个解析worker
 		acm.config.SitemapRequestsPerSecond = minFloat(50.0, acm.config.SitemapRequestsPerSecond*1.2)
 		
@@ -171,8 +173,8 @@ func (acm *AdaptiveConcurrencyManager) adjustConcurrency() {
 		
 	} else if acm.errorRate < 0.01 && acm.responseTime < 10*time.Second {
 		// 谨慎增加API并发 - 更新为双SEOKey API的限制
-		acm.config.APIWorkers = min(12, acm.config.APIWorkers+1) // 最多12个API worker (双API支持更高并发)
-		acm.config.APIRequestsPerSecond = minFloat(2.5, acm.config.APIRequestsPerSecond*1.05) // 保守增长，每个API不超过2.5 req/sec
+		acm.config.APIWorkers = min(max(12, acm.configured.APIWorkers), acm.config.APIWorkers+1) // 最多12个API worker (双API支持更高并发)
+		acm.config.APIRequestsPerSecond = minFloat(acm.configured.APIRequestsPerSecond, acm.config.APIRequestsPerSecond*1.05) // 保守增长，不超过配置的API频率
 		
 		acm.log.WithFields(map[string]interface{}{
 			"error_rate":     acm.errorRate,
@@ -1,9 +1,16 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/parser"
+	"sitemap-go/pkg/storage"
 )
 
 // MonitorConfigBuilder implements Builder pattern for creating SitemapMonitor
@@ -15,6 +22,27 @@ type MonitorConfigBuilder struct {
 	batchSize     int
 	workers       int
 	encryptionKey string
+	lbStrategy    string
+	hedge         api.HedgeOptions
+	quota         storage.QuotaLimits
+	localeRules   []LocaleRule
+	localeDetect  bool
+	sinks         []backend.SinkSpec
+	webhookSecret string
+	stream        bool
+	streamBytes   int // Max body per streamed request
+	dryRun        bool
+	dryRunQuery   bool
+	changeEvents  bool
+	changedOnly   bool
+	backendTLS    backend.TLSConfig
+	backendAuth   backend.AuthConfig
+	concurrency   *ConcurrencyConfig
+	sites         []SiteConfig
+	resume        bool
+	checkpointAge time.Duration // Max age of a resumable checkpoint, 0 = default
+	hostLimit     *parser.HostLimit
+	removalGrace  *time.Duration // nil = storage.DefaultRemovalGrace
 	errors        []error
 }
 
@@ -23,29 +51,30 @@ func NewMonitorConfigBuilder() *MonitorConfigBuilder {
 	return &MonitorConfigBuilder{
 		batchSize: 4,   // Default batch size: 4 keywords per request
 		workers:   8,   // Default worker count
+		hedge:     api.DefaultHedgeOptions(),
 		errors:    make([]error, 0),
 	}
 }
 
 // WithTrendsAPI sets the Google Trends API URL(s) with validation
-// Supports both single URL and comma-separated multiple URLs for load balancing
+// Supports both single URL and comma-separated multiple URLs for load balancing,
+// each optionally followed by ;weight=N;rps=R;concurrency=C;daily=D;monthly=M
 func (b *MonitorConfigBuilder) WithTrendsAPI(apiURL string) *MonitorConfigBuilder {
 	if apiURL == "" {
 		b.errors = append(b.errors, fmt.Errorf("trends API URL cannot be empty"))
 		return b
 	}
 	
-	// Parse and validate each URL (supports comma-separated URLs)
-	urls := strings.Split(apiURL, ",")
-	for i, rawURL := range urls {
-		cleanURL := strings.TrimSpace(rawURL)
-		if cleanURL == "" {
-			continue
-		}
-		
+	// Parse and validate each endpoint (supports comma-separated URLs with ;weight=..;rps=.. options)
+	endpoints, err := api.ParseEndpointList(apiURL)
+	if err != nil {
+		b.errors = append(b.errors, fmt.Errorf("invalid trends API URL list: %w", err))
+		return b
+	}
+	for i, endpoint := range endpoints {
 		// Validate URL format
-		if _, err := url.Parse(cleanURL); err != nil {
-			b.errors = append(b.errors, fmt.Errorf("invalid trends API URL #%d (%s): %w", i+1, cleanURL, err))
+		if _, err := url.Parse(endpoint.URL); err != nil {
+			b.errors = append(b.errors, fmt.Errorf("invalid trends API URL #%d (%s): %w", i+1, endpoint.URL, err))
 			return b
 		}
 	}
@@ -54,18 +83,73 @@ func (b *MonitorConfigBuilder) WithTrendsAPI(apiURL string) *MonitorConfigBuilde
 	return b
 }
 
+// WithLoadBalancing sets the selection strategy used when several API endpoints are configured
+func (b *MonitorConfigBuilder) WithLoadBalancing(strategy string) *MonitorConfigBuilder {
+	switch strategy {
+	case "":
+		return b
+	case api.StrategyWeighted, api.StrategyLeastLoaded:
+		b.lbStrategy = strategy
+	default:
+		b.errors = append(b.errors, fmt.Errorf("unknown load balancing strategy %q (use %s or %s)", strategy, api.StrategyWeighted, api.StrategyLeastLoaded))
+	}
+	return b
+}
+
+// WithHedging enables request hedging across API endpoints
+// percentile is the latency percentile (0-1) after which a hedge is sent and budget caps
+// hedges as a fraction of primary requests; only effective with multiple endpoints
+func (b *MonitorConfigBuilder) WithHedging(enabled bool, percentile, budget float64) *MonitorConfigBuilder {
+	if !enabled {
+		b.hedge.Enabled = false
+		return b
+	}
+	if percentile <= 0 || percentile >= 1 {
+		b.errors = append(b.errors, fmt.Errorf("hedge percentile must be between 0 and 1, got: %.2f", percentile))
+		return b
+	}
+	if budget <= 0 || budget > 1 {
+		b.errors = append(b.errors, fmt.Errorf("hedge budget must be between 0 and 1, got: %.2f", budget))
+		return b
+	}
+	b.hedge.Enabled = true
+	b.hedge.Percentile = percentile
+	b.hedge.BudgetRatio = budget
+	return b
+}
+
+// WithQuota sets the default daily and monthly request caps per API endpoint (0 = unlimited)
+// Spend is recorded across runs either way; endpoint daily=/monthly= options override these
+func (b *MonitorConfigBuilder) WithQuota(daily, monthly int64) *MonitorConfigBuilder {
+	if daily < 0 || monthly < 0 {
+		b.errors = append(b.errors, fmt.Errorf("API quota cannot be negative, got daily=%d monthly=%d", daily, monthly))
+		return b
+	}
+	b.quota = storage.QuotaLimits{Daily: daily, Monthly: monthly}
+	return b
+}
+
+// WithLocales configures per-site or per-path locales ("poki.de=de-DE,example.com/fr/=fr-FR")
+// and whether locales are detected from hreflang and /de/-style path sections
+func (b *MonitorConfigBuilder) WithLocales(rules string, detect bool) *MonitorConfigBuilder {
+	parsed, err := ParseLocaleRules(rules)
+	if err != nil {
+		b.errors = append(b.errors, err)
+		return b
+	}
+	b.localeRules = parsed
+	b.localeDetect = detect
+	return b
+}
+
 // WithBackend sets the backend configuration with validation
+// The API key may be empty when WithBackendSecurity reads the credential from a token file
 func (b *MonitorConfigBuilder) WithBackend(backendURL, apiKey string) *MonitorConfigBuilder {
 	if backendURL == "" {
 		b.errors = append(b.errors, fmt.Errorf("backend URL cannot be empty"))
 		return b
 	}
 	
-	if apiKey == "" {
-		b.errors = append(b.errors, fmt.Errorf("backend API key cannot be empty"))
-		return b
-	}
-	
 	// Validate URL format
 	if _, err := url.Parse(backendURL); err != nil {
 		b.errors = append(b.errors, fmt.Errorf("invalid backend URL: %w", err))
@@ -77,6 +161,85 @@ func (b *MonitorConfigBuilder) WithBackend(backendURL, apiKey string) *MonitorCo
 	return b
 }
 
+// WithBackendSecurity sets TLS options (client certificate, CA bundle, SPKI pins, minimum
+// version) and the auth scheme for backend requests
+func (b *MonitorConfigBuilder) WithBackendSecurity(tlsConfig backend.TLSConfig, auth backend.AuthConfig) *MonitorConfigBuilder {
+	if _, err := tlsConfig.Build(); err != nil {
+		b.errors = append(b.errors, fmt.Errorf("invalid backend TLS configuration: %w", err))
+		return b
+	}
+	if err := auth.Validate(); err != nil {
+		b.errors = append(b.errors, err)
+		return b
+	}
+	b.backendTLS = tlsConfig
+	b.backendAuth = auth
+	return b
+}
+
+// WithSinks sets where results go, e.g. "backend,jsonl:out/metrics.jsonl,webhook:https://hooks.example.com/x"
+// Without sinks results go to the backend only; the backend is required only when listed
+func (b *MonitorConfigBuilder) WithSinks(raw, webhookSecret string) *MonitorConfigBuilder {
+	specs, err := backend.ParseSinkList(raw)
+	if err != nil {
+		b.errors = append(b.errors, fmt.Errorf("invalid sink list: %w", err))
+		return b
+	}
+	b.sinks = specs
+	b.webhookSecret = webhookSecret
+	return b
+}
+
+// WithStreaming sends results to the backend as gzipped NDJSON while the API stage runs,
+// starting a new request whenever the body would exceed maxBytes (0 = default)
+func (b *MonitorConfigBuilder) WithStreaming(enabled bool, maxBytes int) *MonitorConfigBuilder {
+	if maxBytes < 0 {
+		b.errors = append(b.errors, fmt.Errorf("stream max bytes cannot be negative, got: %d", maxBytes))
+		return b
+	}
+	if enabled && maxBytes > 0 && maxBytes < 1024 {
+		b.errors = append(b.errors, fmt.Errorf("stream max bytes too small (min 1024), got: %d", maxBytes))
+		return b
+	}
+	b.stream = enabled
+	b.streamBytes = maxBytes
+	return b
+}
+
+// WithChangeEvents reports URLs added to, removed from or modified in each sitemap since the
+// previous run to the backend's change-event endpoint
+func (b *MonitorConfigBuilder) WithChangeEvents(enabled bool) *MonitorConfigBuilder {
+	b.changeEvents = enabled
+	return b
+}
+
+// WithChangedOnly extracts and queries only the pages added or modified since each sitemap's
+// previous snapshot
+func (b *MonitorConfigBuilder) WithChangedOnly(enabled bool) *MonitorConfigBuilder {
+	b.changedOnly = enabled
+	return b
+}
+
+// WithRemovalGrace sets how long a page must stay missing from its sitemap, over at least two
+// runs, before its removal is confirmed and delisting notices are sent
+func (b *MonitorConfigBuilder) WithRemovalGrace(grace time.Duration) *MonitorConfigBuilder {
+	if grace < 0 {
+		b.errors = append(b.errors, fmt.Errorf("removal grace cannot be negative, got: %s", grace))
+		return b
+	}
+	b.removalGrace = &grace
+	return b
+}
+
+// WithDryRun builds a monitor that reports what it would submit and persist instead of doing it
+// queryAPI lets the dry run spend API budget to show real submissions; otherwise it stops
+// once the keywords to query are known
+func (b *MonitorConfigBuilder) WithDryRun(enabled, queryAPI bool) *MonitorConfigBuilder {
+	b.dryRun = enabled
+	b.dryRunQuery = enabled && queryAPI
+	return b
+}
+
 // WithBatchSize sets the batch size with validation
 func (b *MonitorConfigBuilder) WithBatchSize(size int) *MonitorConfigBuilder {
 	if size <= 0 {
@@ -109,6 +272,65 @@ func (b *MonitorConfigBuilder) WithWorkers(count int) *MonitorConfigBuilder {
 	return b
 }
 
+// WithConcurrency replaces DefaultConcurrencyConfig, e.g. with -api-workers, -api-rate-limit and -max-urls
+func (b *MonitorConfigBuilder) WithConcurrency(config ConcurrencyConfig) *MonitorConfigBuilder {
+	if config.MainWorkers <= 0 || config.ParseWorkers <= 0 || config.ExtractWorkers <= 0 || config.APIWorkers <= 0 {
+		b.errors = append(b.errors, fmt.Errorf("concurrency worker counts must be positive, got main=%d parse=%d extract=%d api=%d",
+			config.MainWorkers, config.ParseWorkers, config.ExtractWorkers, config.APIWorkers))
+		return b
+	}
+	if config.APIRequestsPerSecond <= 0 || config.SitemapRequestsPerSecond <= 0 {
+		b.errors = append(b.errors, fmt.Errorf("request rates must be positive, got api=%.2f sitemap=%.2f",
+			config.APIRequestsPerSecond, config.SitemapRequestsPerSecond))
+		return b
+	}
+	if config.MaxURLsPerSitemap < 0 {
+		b.errors = append(b.errors, fmt.Errorf("max URLs per sitemap cannot be negative, got: %d", config.MaxURLsPerSitemap))
+		return b
+	}
+	b.concurrency = &config
+	return b
+}
+
+// WithSites applies per-site overrides (filters, extraction profile, locale, rate limits, tags)
+// to the sitemaps of each site; explicit WithLocales rules win over site locales
+func (b *MonitorConfigBuilder) WithSites(sites []SiteConfig) *MonitorConfigBuilder {
+	names := make(map[string]bool, len(sites))
+	for i, site := range sites {
+		for _, err := range site.Validate() {
+			b.errors = append(b.errors, fmt.Errorf("site #%d (%s): %w", i+1, site.Name, err))
+		}
+		if names[site.Name] {
+			b.errors = append(b.errors, fmt.Errorf("site #%d: duplicate site name %q", i+1, site.Name))
+		}
+		names[site.Name] = true
+	}
+	b.sites = sites
+	return b
+}
+
+// WithCheckpoints configures run checkpoints: resume continues the last interrupted run when
+// its checkpoint is younger than maxAge (0 = storage.DefaultCheckpointMaxAge)
+func (b *MonitorConfigBuilder) WithCheckpoints(resume bool, maxAge time.Duration) *MonitorConfigBuilder {
+	if maxAge < 0 {
+		b.errors = append(b.errors, fmt.Errorf("checkpoint max age cannot be negative, got: %s", maxAge))
+		return b
+	}
+	b.resume = resume
+	b.checkpointAge = maxAge
+	return b
+}
+
+// WithHostLimits sets the per-host politeness limit of sitemap fetches; sites may override it
+func (b *MonitorConfigBuilder) WithHostLimits(limit parser.HostLimit) *MonitorConfigBuilder {
+	if err := limit.Validate(); err != nil {
+		b.errors = append(b.errors, err)
+		return b
+	}
+	b.hostLimit = &limit
+	return b
+}
+
 // WithEncryptionKey sets the encryption key for securing stored data
 func (b *MonitorConfigBuilder) WithEncryptionKey(key string) *MonitorConfigBuilder {
 	if key == "" {
@@ -133,13 +355,26 @@ func (b *MonitorConfigBuilder) WithEncryptionKey(key string) *MonitorConfigBuild
 
 // Validate checks all configuration and returns any validation errors
 func (b *MonitorConfigBuilder) Validate() error {
-	if len(b.errors) == 0 {
+	errs := b.errors
+	if !b.dryRun && b.backendURL == "" && (len(b.sinks) == 0 || backend.HasSink(b.sinks, backend.SinkBackend)) {
+		errs = append(errs, fmt.Errorf("backend URL is required unless only file or webhook sinks are configured"))
+	}
+	if b.backendURL != "" && b.backendAPIKey == "" && b.backendAuth.TokenFile == "" {
+		errs = append(errs, fmt.Errorf("backend API key cannot be empty unless a token file is configured"))
+	}
+	if b.stream && !b.dryRun && (b.backendURL == "" || len(b.sinks) > 0) {
+		errs = append(errs, fmt.Errorf("stream submission needs a backend URL and cannot be combined with output sinks"))
+	}
+	if b.stream && !b.dryRun && b.backendAuth.Scheme == backend.AuthHMAC {
+		errs = append(errs, fmt.Errorf("stream submission cannot be combined with %s auth", backend.AuthHMAC))
+	}
+	if len(errs) == 0 {
 		return nil
 	}
 	
 	// Aggregate all errors into a single error message
 	var errorMessages []string
-	for _, err := range b.errors {
+	for _, err := range errs {
 		errorMessages = append(errorMessages, err.Error())
 	}
 	
@@ -166,18 +401,33 @@ func (b *MonitorConfigBuilder) createMonitor() (*SitemapMonitor, error) {
 		TrendAPIBaseURL:         b.trendsAPIURL,
 		WorkerPoolSize:          b.workers,
 		EncryptionKey:          b.encryptionKey,
-		EnableBackendSubmission: true,
+		EnableBackendSubmission: len(b.sinks) == 0 || backend.HasSink(b.sinks, backend.SinkBackend),
+		Sinks:                   b.sinks,
+		WebhookSecret:           b.webhookSecret,
+		StreamSubmission:        b.stream,
+		StreamMaxBytes:          b.streamBytes,
+		DryRun:                  b.dryRun,
+		DryRunQueryAPI:          b.dryRunQuery,
+		BackendConfig:           backend.BackendConfig{TLS: b.backendTLS, Auth: b.backendAuth},
+		Concurrency:             b.concurrency,
 	}
 	
-	// Check if TRENDS_API_URL contains multiple URLs (comma-separated)
-	if strings.Contains(b.trendsAPIURL, ",") {
-		// Split into primary and secondary for dual API mode
-		urls := strings.Split(b.trendsAPIURL, ",")
-		primaryURL := strings.TrimSpace(urls[0])
-		secondaryURL := strings.TrimSpace(urls[1])
-		
-		// Use dual API monitor for load balancing
-		return NewMonitorWithDualAPI(config, b.backendURL, b.backendAPIKey, b.batchSize, primaryURL, secondaryURL)
+	// Multiple endpoints (or per-endpoint settings) use the weighted load balancing client
+	endpoints, err := api.ParseEndpointList(b.trendsAPIURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trends API endpoint list: %w", err)
+	}
+	if len(endpoints) > 1 || strings.Contains(b.trendsAPIURL, ";") {
+		options := api.DefaultWeightedClientOptions()
+		if b.lbStrategy != "" {
+			options.Strategy = b.lbStrategy
+		}
+		options.Hedge = b.hedge
+		monitor, err := NewMonitorWithWeightedAPI(config, b.backendURL, b.backendAPIKey, b.batchSize, endpoints, options)
+		if err != nil {
+			return nil, err
+		}
+		return b.withQuotaAccounting(monitor, endpoints)
 	}
 	
 	// Use the safe internal constructor
@@ -186,6 +436,33 @@ func (b *MonitorConfigBuilder) createMonitor() (*SitemapMonitor, error) {
 		return nil, fmt.Errorf("failed to create sitemap monitor: %w", err)
 	}
 	
+	return b.withQuotaAccounting(monitor, endpoints)
+}
+
+// withQuotaAccounting attaches the persisted quota ledger, locale resolver, host limits, site overrides and checkpoints to a freshly built monitor
+func (b *MonitorConfigBuilder) withQuotaAccounting(monitor *SitemapMonitor, endpoints []api.EndpointConfig) (*SitemapMonitor, error) {
+	localeRules := append(append([]LocaleRule{}, b.localeRules...), siteLocaleRules(b.sites)...)
+	if b.localeDetect || len(localeRules) > 0 {
+		monitor.SetLocaleResolver(NewLocaleResolver(localeRules, b.localeDetect))
+	}
+	if b.hostLimit != nil {
+		monitor.SetHostLimits(*b.hostLimit)
+	}
+	if len(b.sites) > 0 {
+		monitor.SetSites(b.sites)
+	}
+	if b.changeEvents {
+		monitor.EnableChangeEvents()
+	}
+	monitor.SetChangedOnly(b.changedOnly)
+	if b.removalGrace != nil {
+		monitor.SetRemovalGrace(*b.removalGrace)
+	}
+	monitor.EnableCheckpoints(b.resume, b.checkpointAge)
+	if err := monitor.configureQuota(context.Background(), endpoints, b.quota); err != nil {
+		monitor.Close()
+		return nil, err
+	}
 	return monitor, nil
 }
 
@@ -0,0 +1,271 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/logger"
+	"sitemap-go/pkg/storage"
+)
+
+// Daemon defaults
+const (
+	DefaultRunTimeout   = 30 * time.Minute // Same hard limit as a one-shot run
+	DefaultDrainTimeout = 2 * time.Minute
+)
+
+// DaemonConfig controls scheduled monitoring
+type DaemonConfig struct {
+	Rules        []ScheduleRule // Schedule per site, "*" for every other site
+	Workers      int            // Sitemap workers per run
+	RunTimeout   time.Duration  // Hard limit for one run
+	DrainTimeout time.Duration  // How long shutdown waits for in-flight runs and queued submissions
+}
+
+// DaemonRun describes one scheduled run
+type DaemonRun struct {
+	Sites    []string // The site the run monitored
+	Results  []*MonitorResult
+	Summary  RunSummary
+	Err      error
+	Started  time.Time
+	Duration time.Duration
+}
+
+// Daemon monitors sites on their own schedules with one long-lived SitemapMonitor, so HTTP
+// connection pools, caches and submission workers stay warm between runs
+// Every due site runs on its own, so a slow site does not hold up the others; a site whose run
+// is still going is not started again. Runs share the monitor's caches, API quota and submission
+// pool but have their own run ID and summary, and deduplicate keywords only within the site (the
+// processed-URL cache still skips pages another run answered). Scheduled runs keep no checkpoint:
+// there is a single one, for -resume of a one-shot run
+type Daemon struct {
+	monitor *SitemapMonitor
+	config  DaemonConfig
+	sites   []*daemonSite
+	store   *storage.ScheduleStore
+	onRun   func(DaemonRun)
+	log     *logger.Logger
+}
+
+type daemonSite struct {
+	rule    ScheduleRule
+	state   storage.ScheduleState
+	running bool // Owned by the Run loop
+}
+
+// siteRun reports a finished run of a site to the Run loop
+type siteRun struct {
+	site  *daemonSite
+	state storage.ScheduleState
+}
+
+// NewDaemon schedules every sitemap by the first matching rule; each needs one
+func NewDaemon(monitor *SitemapMonitor, sitemapURLs []string, config DaemonConfig) (*Daemon, error) {
+	if monitor.IsDryRun() {
+		return nil, fmt.Errorf("daemon mode cannot be combined with a dry run")
+	}
+	if len(sitemapURLs) == 0 {
+		return nil, fmt.Errorf("daemon mode needs at least one sitemap")
+	}
+	if config.RunTimeout <= 0 {
+		config.RunTimeout = DefaultRunTimeout
+	}
+	if config.DrainTimeout <= 0 {
+		config.DrainTimeout = DefaultDrainTimeout
+	}
+
+	d := &Daemon{
+		monitor: monitor,
+		config:  config,
+		store:   storage.NewScheduleStore(monitor.storage),
+		log:     logger.GetLogger().WithField("component", "daemon"),
+	}
+	for _, sitemapURL := range sitemapURLs {
+		rule, ok := scheduleFor(config.Rules, sitemapURL)
+		if !ok {
+			return nil, fmt.Errorf("no schedule for %s, add a site rule or a * default", sitemapURL)
+		}
+		d.sites = append(d.sites, &daemonSite{rule: rule, state: storage.ScheduleState{Site: sitemapURL, Schedule: rule.Spec}})
+	}
+	return d, nil
+}
+
+// OnRun registers a callback invoked after every run; runs of different sites may call it concurrently
+func (d *Daemon) OnRun(fn func(DaemonRun)) {
+	d.onRun = fn
+}
+
+// Run monitors sites until ctx is cancelled, then drains: in-flight runs are interrupted, their
+// API batches may finish and queued submissions may be sent within DrainTimeout; whatever is
+// left stays in the outbox
+// Persisted next-run times are honoured across restarts, and runs missed while the daemon
+// was down happen at startup, as do runs of sites without persisted state or with a new schedule
+func (d *Daemon) Run(ctx context.Context) error {
+	persisted := d.store.Load(context.Background())
+	now := time.Now()
+	for _, site := range d.sites {
+		if state, ok := persisted[site.state.Site]; ok && state.Schedule == site.rule.Spec {
+			site.state = state
+		} else {
+			site.state.NextRun = now
+		}
+	}
+
+	// Work outlives ctx so that shutdown can let it finish
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	d.monitor.submissionPool.Start(workCtx)
+	d.log.WithField("sites", len(d.sites)).Info("Daemon started")
+
+	var running sync.WaitGroup
+	finished := make(chan siteRun, len(d.sites)) // A site has at most one run in flight
+	for {
+		due, next := d.dueSites(time.Now())
+		if len(due) > 0 {
+			// Submissions that failed since the last run are retried alongside it
+			d.monitor.submissionPool.ReplayOutbox(workCtx)
+		}
+		for _, site := range due {
+			site.running = true
+			running.Add(1)
+			go func(site *daemonSite) {
+				defer running.Done()
+				finished <- siteRun{site: site, state: d.runSite(workCtx, site)}
+			}(site)
+		}
+
+		var timer *time.Timer
+		var wake <-chan time.Time
+		if !next.IsZero() {
+			d.log.WithField("next_run", next.Format(time.RFC3339)).Debug("Waiting for the next scheduled run")
+			timer = time.NewTimer(time.Until(next))
+			wake = timer.C
+		} else if !d.anyRunning() {
+			d.log.Warn("No site is scheduled to run again, waiting for shutdown")
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return d.drain(&running, cancelWork)
+		case done := <-finished:
+			done.site.state, done.site.running = done.state, false
+		case <-wake:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// dueSites returns the idle sites whose next run has come, and the earliest next run of the other
+// idle sites, zero when none will run again; a site whose schedule has no next run is never due
+func (d *Daemon) dueSites(now time.Time) ([]*daemonSite, time.Time) {
+	var due []*daemonSite
+	var next time.Time
+	for _, site := range d.sites {
+		if site.running || site.state.NextRun.IsZero() {
+			continue
+		}
+		if !site.state.NextRun.After(now) {
+			due = append(due, site)
+			continue
+		}
+		if next.IsZero() || site.state.NextRun.Before(next) {
+			next = site.state.NextRun
+		}
+	}
+	return due, next
+}
+
+func (d *Daemon) anyRunning() bool {
+	for _, site := range d.sites {
+		if site.running {
+			return true
+		}
+	}
+	return false
+}
+
+// runSite monitors one site as a run of its own and returns its new schedule state; the site's
+// rule and name are fixed, its state belongs to the Run loop
+func (d *Daemon) runSite(ctx context.Context, site *daemonSite) storage.ScheduleState {
+	runCtx, cancel := context.WithTimeout(ctx, d.config.RunTimeout)
+	defer cancel()
+
+	state := storage.ScheduleState{Site: site.state.Site, Schedule: site.rule.Spec}
+	run := DaemonRun{Sites: []string{state.Site}, Started: time.Now()}
+	runCtx = withRun(runCtx, d.monitor.beginRun(runCtx, run.Sites, false))
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				run.Err = fmt.Errorf("monitoring panic recovered: %v", r)
+			}
+		}()
+		run.Results, run.Err = d.monitor.processSitemaps(runCtx, run.Sites, d.config.Workers)
+	}()
+	run.Duration = time.Since(run.Started)
+	run.Summary = d.monitor.GetRunSummary(runCtx)
+
+	state.LastRun = run.Started
+	state.LastRunID = run.Summary.RunID
+	for _, result := range run.Results {
+		if !result.Success {
+			state.LastError = result.Error
+		}
+	}
+	if run.Err != nil {
+		state.LastError = run.Err.Error()
+	}
+	state.NextRun = site.rule.Schedule.Next(time.Now())
+	if err := d.store.Save(context.Background(), state); err != nil {
+		d.log.WithError(err).Warn("Failed to persist schedule, sites may run early after a restart")
+	}
+
+	fields := map[string]interface{}{
+		"run_id":   run.Summary.RunID,
+		"site":     state.Site,
+		"duration": run.Duration.String(),
+	}
+	if state.LastError != "" {
+		d.log.WithField("error", state.LastError).WithFields(fields).Error("Scheduled run failed")
+	} else {
+		d.log.WithFields(fields).Info("Scheduled run completed")
+	}
+	if d.onRun != nil {
+		d.onRun(run)
+	}
+	return state
+}
+
+// drain interrupts in-flight runs, so they stop parsing and start no new batches, and lets the
+// batches they hold and queued submissions finish within DrainTimeout; the runs are cancelled
+// only once it is up
+func (d *Daemon) drain(running *sync.WaitGroup, cancelWork context.CancelFunc) error {
+	d.log.WithField("timeout", d.config.DrainTimeout.String()).Info("Shutdown requested, draining daemon")
+	drainCtx, cancel := context.WithTimeout(context.Background(), d.config.DrainTimeout)
+	defer cancel()
+	d.monitor.Interrupt()
+
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-drainCtx.Done():
+		d.log.Warn("In-flight runs did not finish in time, cancelling them")
+		cancelWork()
+		<-done
+	}
+	if !d.monitor.WaitForSubmissions(drainCtx) {
+		d.log.Warn("Submissions still queued at shutdown stay in the outbox for the next start")
+	}
+	d.log.Info("Daemon stopped")
+	return nil
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/extractor"
+	"sitemap-go/pkg/parser"
+)
+
+// newSitemapServer serves a sitemap of n game pages
+func newSitemapServer(n int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var urls strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&urls, "<url><loc>http://%s/game/space-racer-%c</loc></url>", r.Host, 'a'+i)
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">%s</urlset>`, urls.String())
+	}))
+}
+
+func TestDaemonShutdownInterruptsRunsAndSubmitsTheirInFlightBatch(t *testing.T) {
+	server := newSitemapServer(25)
+	defer server.Close()
+	recorder := backend.NewRecordingClient()
+	pool := backend.NewSubmissionPool(recorder, 1)
+	client := &answeringAPIClient{}
+	sm := newQueryTestMonitor(client, pool)
+	sm.parserFactory = parser.GetParserFactory()
+	sm.keywordExtractor = extractor.NewURLKeywordExtractor()
+	sm.changeTracker = newChangeTracker(sm.storage)
+	sm.rateLimiterPool = NewRateLimiterPool()
+	sm.retryProcessor = NewSimpleRetryProcessor(client, sm.simpleTracker, pool, sm.dataConverter)
+
+	sitemapURL := server.URL + "/sitemap.xml"
+	rules, err := ParseScheduleRules("*=@hourly")
+	if err != nil {
+		t.Fatalf("ParseScheduleRules failed: %v", err)
+	}
+	d, err := NewDaemon(sm, []string{sitemapURL}, DaemonConfig{Rules: rules, DrainTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	runIDs := make(chan string, 1)
+	d.OnRun(func(run DaemonRun) { runIDs <- run.Summary.RunID })
+
+	// The signal arrives while the first batch is with the API, which answers only once the
+	// drain has interrupted the run
+	querying := make(chan struct{})
+	client.onQuery = func(n int) {
+		if n == 1 {
+			close(querying)
+			for !sm.isInterrupted() {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- d.Run(ctx) }()
+	select {
+	case <-querying:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The scheduled run never queried the API")
+	}
+	cancel()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("The daemon did not drain")
+	}
+
+	if client.queries != 1 {
+		t.Errorf("Expected no batch started after shutdown, got %d queries", client.queries)
+	}
+	records := recorder.Records()
+	if len(records) != 10 {
+		t.Errorf("Expected the in-flight batch's 10 records submitted, got %d", len(records))
+	}
+	runID := <-runIDs
+	for _, record := range records {
+		if record.RunID != runID {
+			t.Errorf("Expected records submitted under the run's ID %q, got %q", runID, record.RunID)
+			break
+		}
+	}
+	if pending := sm.simpleTracker.CountPendingKeywords(context.Background()); pending != 15 {
+		t.Errorf("Expected the 15 leftover keywords carried over, got %d", pending)
+	}
+}
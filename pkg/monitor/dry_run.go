@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/storage"
+)
+
+// dryRunState captures what a dry run would have done
+// State writes land in an overlay over the real storage and submissions in a recording
+// client, so the run sees real processed-URL and failed-keyword state but changes none of it
+type dryRunState struct {
+	queryAPI bool                     // Query the keyword API; without it the run stops at the candidates
+	overlay  *storage.OverlayStorage  // Monitor storage during the run
+	base     storage.Storage          // Real storage, written only for API spend that really happened
+	recorder *backend.RecordingClient // Stands in for the backend and every sink
+
+	mu         sync.Mutex
+	candidates []KeywordCandidate
+}
+
+func newDryRunState(base storage.Storage, queryAPI bool) *dryRunState {
+	return &dryRunState{
+		queryAPI: queryAPI,
+		overlay:  storage.NewOverlayStorage(base),
+		base:     base,
+		recorder: backend.NewRecordingClient(),
+	}
+}
+
+func (d *dryRunState) recordCandidates(candidates []KeywordCandidate) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.candidates = append(d.candidates, candidates...)
+}
+
+// DryRunReport lists what a real run would have queried, submitted and persisted
+type DryRunReport struct {
+	RunID        string                       `json:"run_id"`
+	GeneratedAt  time.Time                    `json:"generated_at"`
+	QueriedAPI   bool                         `json:"queried_api"`
+	Candidates   []KeywordCandidate           `json:"candidates"`              // Keywords a real run would query, most valuable first
+	Submissions  []backend.KeywordMetricsData `json:"submissions"`             // Records a real run would send to the backend
+	ChangeEvents []backend.ChangeEvent        `json:"change_events,omitempty"` // Sitemap changes a real run would report
+	StateWrites  map[string]json.RawMessage   `json:"state_writes"`            // Storage keys a real run would write, with their new values
+	StateDeletes []string                     `json:"state_deletes,omitempty"` // Existing storage keys a real run would delete
+}
+
+// IsDryRun reports whether the monitor was built for a dry run
+func (sm *SitemapMonitor) IsDryRun() bool {
+	return sm.dryRun != nil
+}
+
+// DryRunReport collects the dry run's outcome once queued submissions are recorded
+func (sm *SitemapMonitor) DryRunReport(ctx context.Context) (*DryRunReport, error) {
+	if sm.dryRun == nil {
+		return nil, fmt.Errorf("monitor is not in dry-run mode")
+	}
+	if !sm.submissionPool.WaitIdle(ctx) {
+		return nil, fmt.Errorf("timed out waiting for submissions to be recorded: %w", ctx.Err())
+	}
+
+	sm.dryRun.mu.Lock()
+	candidates := append([]KeywordCandidate{}, sm.dryRun.candidates...)
+	sm.dryRun.mu.Unlock()
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+
+	writes, deletes := sm.dryRun.overlay.Changes(ctx)
+	return &DryRunReport{
+		RunID:        sm.currentRun(ctx).id,
+		GeneratedAt:  time.Now(),
+		QueriedAPI:   sm.dryRun.queryAPI,
+		Candidates:   candidates,
+		Submissions:  append([]backend.KeywordMetricsData{}, sm.dryRun.recorder.Records()...),
+		ChangeEvents: sm.dryRun.recorder.ChangeEvents(),
+		StateWrites:  writes,
+		StateDeletes: deletes,
+	}, nil
+}
+
+// WriteDryRunReport writes the dry-run report as indented JSON to path
+func (sm *SitemapMonitor) WriteDryRunReport(ctx context.Context, path string) (*DryRunReport, error) {
+	report, err := sm.DryRunReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dry-run report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write dry-run report: %w", err)
+	}
+	return report, nil
+}
+
+// quotaStorage is where API spend is recorded; a dry run that queries spends real budget
+func (sm *SitemapMonitor) quotaStorage() storage.Storage {
+	if sm.dryRun != nil && sm.dryRun.queryAPI {
+		return sm.dryRun.base
+	}
+	return sm.storage
+}
@@ -51,6 +51,8 @@ func NewMonitorWithDualAPI(config MonitorConfig, backendURL, apiKey string, batc
 		BatchSize:  batchSize,
 		EnableGzip: true,
 		Timeout:    60 * time.Second,
+		TLS:        config.BackendConfig.TLS,
+		Auth:       config.BackendConfig.Auth,
 	}
 	backendClient, err := backend.NewBackendClient(backendConfig)
 	if err != nil {
@@ -60,12 +62,18 @@ func NewMonitorWithDualAPI(config MonitorConfig, backendURL, apiKey string, batc
 	
 	// Create non-blocking submission pool
 	submissionPool := backend.NewSubmissionPool(backendClient, 3)
+	submissionPool.SetOutbox(storage.NewOutbox(storageService)) // Survives full queues and early exits
+	submissionPool.SetDeadLetters(storage.NewDeadLetterStore(storageService))
+	submissionPool.SetAutoscale(submissionAutoscale)
 	
 	// Create retry service
 	retryProcessor := NewSimpleRetryProcessor(dualAPIClient, simpleTracker, submissionPool, dataConverter)
 	
 	// Create adaptive concurrency manager
 	concurrencyConfig := DefaultConcurrencyConfig()
+	if config.Concurrency != nil {
+		concurrencyConfig = *config.Concurrency
+	}
 	concurrencyManager := NewAdaptiveConcurrencyManager(concurrencyConfig)
 	
 	// Create rate limiter
@@ -79,6 +87,7 @@ func NewMonitorWithDualAPI(config MonitorConfig, backendURL, apiKey string, batc
 		keywordExtractor:   keywordExtractor,
 		apiClient:          dualAPIClient,
 		storage:            storageService,
+		changeTracker:      newChangeTracker(storageService),
 		workerPool:         workerPool,
 		simpleTracker:      simpleTracker,
 		submissionPool:     submissionPool,
@@ -0,0 +1,313 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/backend"
+)
+
+// FileConfig is the declarative configuration read with -config
+// Secrets (API keys, the encryption key, webhook secrets) stay in flags or the environment;
+// a file holding them is rejected as an unknown field
+type FileConfig struct {
+	Global    GlobalSettings   `json:"global"`
+	Providers []ProviderConfig `json:"providers,omitempty"`
+	Backend   BackendSettings  `json:"backend"`
+	Sinks     []SinkConfig     `json:"sinks,omitempty"`
+	Sites     []SiteConfig     `json:"sites"`
+}
+
+// GlobalSettings are pipeline settings; zero values leave the flag defaults in place
+type GlobalSettings struct {
+	Workers      int            `json:"workers,omitempty"`
+	APIWorkers   int            `json:"api_workers,omitempty"`
+	APIRateLimit float64        `json:"api_rate_limit,omitempty"`
+	MaxURLs      int            `json:"max_urls,omitempty"`
+	BatchSize    int            `json:"batch_size,omitempty"`
+	LBStrategy   string         `json:"lb_strategy,omitempty"`
+	Hedge        *HedgeSettings `json:"hedge,omitempty"`
+	DailyQuota   int64          `json:"daily_quota,omitempty"`
+	MonthlyQuota int64          `json:"monthly_quota,omitempty"`
+	Locales      string         `json:"locales,omitempty"` // Same rules as -locales, e.g. example.com/fr/=fr-FR
+	LocaleDetect *bool          `json:"locale_detect,omitempty"`
+	ChangeEvents *bool          `json:"change_events,omitempty"`
+	ChangedOnly  *bool          `json:"changed_only,omitempty"`
+	RemovalGrace string         `json:"removal_grace,omitempty"` // How long a page must be missing to count as removed, e.g. 48h
+	Schedule     string         `json:"schedule,omitempty"`      // Daemon default schedule
+	RunTimeout   string         `json:"run_timeout,omitempty"`   // Daemon run limit, e.g. 30m
+	DrainTimeout string         `json:"drain_timeout,omitempty"` // Daemon shutdown drain, e.g. 2m
+	Politeness   *HostSettings  `json:"politeness,omitempty"`
+}
+
+// HostSettings mirror -host-max-concurrent, -host-min-interval and -host-burst
+type HostSettings struct {
+	MaxConcurrent int    `json:"max_concurrent,omitempty"`
+	MinInterval   string `json:"min_interval,omitempty"` // e.g. 250ms
+	Burst         int    `json:"burst,omitempty"`
+}
+
+// HedgeSettings mirror -api-hedge, -api-hedge-percentile and -api-hedge-budget
+type HedgeSettings struct {
+	Enabled    bool    `json:"enabled"`
+	Percentile float64 `json:"percentile,omitempty"`
+	Budget     float64 `json:"budget,omitempty"`
+}
+
+// ProviderConfig is one keyword API endpoint; zero options use the global settings
+type ProviderConfig struct {
+	URL          string  `json:"url"`
+	Weight       int     `json:"weight,omitempty"`
+	RPS          float64 `json:"rps,omitempty"`
+	Concurrency  int     `json:"concurrency,omitempty"`
+	DailyQuota   int64   `json:"daily_quota,omitempty"`
+	MonthlyQuota int64   `json:"monthly_quota,omitempty"`
+}
+
+// BackendSettings are the non-secret backend settings
+type BackendSettings struct {
+	URL            string             `json:"url,omitempty"`
+	Auth           string             `json:"auth,omitempty"`
+	TokenFile      string             `json:"token_file,omitempty"`
+	KeyID          string             `json:"key_id,omitempty"`
+	Stream         *bool              `json:"stream,omitempty"`
+	StreamMaxBytes int                `json:"stream_max_bytes,omitempty"`
+	TLS            BackendTLSSettings `json:"tls"`
+}
+
+// BackendTLSSettings mirror the -backend-tls-* flags
+type BackendTLSSettings struct {
+	CertFile   string   `json:"cert_file,omitempty"`
+	KeyFile    string   `json:"key_file,omitempty"`
+	CAFile     string   `json:"ca_file,omitempty"`
+	Pins       []string `json:"pins,omitempty"`
+	MinVersion string   `json:"min_version,omitempty"`
+}
+
+// SinkConfig is one result output, as in -sinks
+type SinkConfig struct {
+	Type      string `json:"type"`
+	Target    string `json:"target,omitempty"`
+	Batch     int    `json:"batch,omitempty"`
+	OnFailure string `json:"on_failure,omitempty"`
+}
+
+// LoadFileConfig reads and validates a JSON config file
+func LoadFileConfig(path string) (*FileConfig, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return nil, fmt.Errorf("config %s: YAML is not supported, use JSON", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var config FileConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields() // Typos must not silently fall back to defaults
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Validate checks the whole file and reports every problem with its field path
+func (c *FileConfig) Validate() error {
+	var errs []string
+	add := func(field string, err error) {
+		errs = append(errs, fmt.Sprintf("%s: %v", field, err))
+	}
+
+	g := c.Global
+	if g.Workers < 0 || g.Workers > 50 {
+		add("global.workers", fmt.Errorf("must be at most 50, got %d", g.Workers))
+	}
+	if g.APIWorkers < 0 {
+		add("global.api_workers", fmt.Errorf("cannot be negative, got %d", g.APIWorkers))
+	}
+	if g.APIRateLimit < 0 {
+		add("global.api_rate_limit", fmt.Errorf("cannot be negative, got %.2f", g.APIRateLimit))
+	}
+	if g.MaxURLs < 0 {
+		add("global.max_urls", fmt.Errorf("cannot be negative, got %d", g.MaxURLs))
+	}
+	if g.BatchSize < 0 || g.BatchSize > 1000 {
+		add("global.batch_size", fmt.Errorf("must be at most 1000, got %d", g.BatchSize))
+	}
+	switch g.LBStrategy {
+	case "", api.StrategyWeighted, api.StrategyLeastLoaded:
+	default:
+		add("global.lb_strategy", fmt.Errorf("unknown strategy %q (use %s or %s)", g.LBStrategy, api.StrategyWeighted, api.StrategyLeastLoaded))
+	}
+	if h := g.Hedge; h != nil && h.Enabled {
+		if h.Percentile < 0 || h.Percentile >= 1 {
+			add("global.hedge.percentile", fmt.Errorf("must be between 0 and 1, got %.2f", h.Percentile))
+		}
+		if h.Budget < 0 || h.Budget > 1 {
+			add("global.hedge.budget", fmt.Errorf("must be between 0 and 1, got %.2f", h.Budget))
+		}
+	}
+	if g.DailyQuota < 0 || g.MonthlyQuota < 0 {
+		add("global.daily_quota", fmt.Errorf("quotas cannot be negative"))
+	}
+	if _, err := ParseLocaleRules(g.Locales); err != nil {
+		add("global.locales", err)
+	}
+	if g.Schedule != "" {
+		if _, err := ParseSchedule(g.Schedule); err != nil {
+			add("global.schedule", err)
+		}
+	}
+	if p := g.Politeness; p != nil {
+		if p.MaxConcurrent < 0 || p.Burst < 0 {
+			add("global.politeness", fmt.Errorf("max_concurrent and burst cannot be negative"))
+		}
+		if p.MinInterval != "" {
+			if d, err := time.ParseDuration(p.MinInterval); err != nil || d < 0 {
+				add("global.politeness.min_interval", fmt.Errorf("invalid duration %q", p.MinInterval))
+			}
+		}
+	}
+	if g.RemovalGrace != "" {
+		if d, err := time.ParseDuration(g.RemovalGrace); err != nil || d < 0 {
+			add("global.removal_grace", fmt.Errorf("invalid duration %q", g.RemovalGrace))
+		}
+	}
+	for _, timeout := range []struct{ field, value string }{{"global.run_timeout", g.RunTimeout}, {"global.drain_timeout", g.DrainTimeout}} {
+		if timeout.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(timeout.value); err != nil || d <= 0 {
+			add(timeout.field, fmt.Errorf("invalid duration %q", timeout.value))
+		}
+	}
+
+	for i, provider := range c.Providers {
+		field := fmt.Sprintf("providers[%d]", i)
+		if parsed, err := url.Parse(provider.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			add(field+".url", fmt.Errorf("%q is not an http(s) URL", provider.URL))
+		}
+		if provider.Weight < 0 || provider.RPS < 0 || provider.Concurrency < 0 || provider.DailyQuota < 0 || provider.MonthlyQuota < 0 {
+			add(field, fmt.Errorf("weight, rps, concurrency and quotas cannot be negative"))
+		}
+	}
+
+	b := c.Backend
+	if b.URL != "" {
+		if parsed, err := url.Parse(b.URL); err != nil || parsed.Host == "" {
+			add("backend.url", fmt.Errorf("%q is not a URL", b.URL))
+		}
+	}
+	if err := (backend.AuthConfig{Scheme: b.Auth}).Validate(); err != nil {
+		add("backend.auth", err)
+	}
+	if b.StreamMaxBytes < 0 {
+		add("backend.stream_max_bytes", fmt.Errorf("cannot be negative, got %d", b.StreamMaxBytes))
+	}
+	switch b.TLS.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		add("backend.tls.min_version", fmt.Errorf("must be 1.2 or 1.3, got %q", b.TLS.MinVersion))
+	}
+
+	for i, sink := range c.Sinks {
+		if _, err := backend.ParseSinkList(sink.spec()); err != nil {
+			add(fmt.Sprintf("sinks[%d]", i), err)
+		}
+	}
+
+	names := make(map[string]int, len(c.Sites))
+	sitemaps := make(map[string]int)
+	for i, site := range c.Sites {
+		field := fmt.Sprintf("sites[%d]", i)
+		for _, err := range site.Validate() {
+			errs = append(errs, fmt.Sprintf("%s.%v", field, err))
+		}
+		if first, ok := names[site.Name]; ok && site.Name != "" {
+			add(field+".name", fmt.Errorf("%q is already used by sites[%d]", site.Name, first))
+		} else {
+			names[site.Name] = i
+		}
+		for j, sitemapURL := range site.Sitemaps {
+			if first, ok := sitemaps[sitemapURL]; ok {
+				add(fmt.Sprintf("%s.sitemaps[%d]", field, j), fmt.Errorf("%s is already listed by sites[%d]", sitemapURL, first))
+			} else {
+				sitemaps[sitemapURL] = i
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("configuration validation failed: %s", strings.Join(errs, "; "))
+}
+
+// EndpointList renders the providers in -trends-api-url syntax
+func (c *FileConfig) EndpointList() string {
+	entries := make([]string, 0, len(c.Providers))
+	for _, p := range c.Providers {
+		entry := p.URL
+		if p.Weight > 0 {
+			entry += ";weight=" + strconv.Itoa(p.Weight)
+		}
+		if p.RPS > 0 {
+			entry += ";rps=" + strconv.FormatFloat(p.RPS, 'f', -1, 64)
+		}
+		if p.Concurrency > 0 {
+			entry += ";concurrency=" + strconv.Itoa(p.Concurrency)
+		}
+		if p.DailyQuota > 0 {
+			entry += ";daily=" + strconv.FormatInt(p.DailyQuota, 10)
+		}
+		if p.MonthlyQuota > 0 {
+			entry += ";monthly=" + strconv.FormatInt(p.MonthlyQuota, 10)
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+// SinkList renders the sinks in -sinks syntax
+func (c *FileConfig) SinkList() string {
+	entries := make([]string, 0, len(c.Sinks))
+	for _, sink := range c.Sinks {
+		entries = append(entries, sink.spec())
+	}
+	return strings.Join(entries, ",")
+}
+
+func (s SinkConfig) spec() string {
+	entry := s.Type
+	if s.Target != "" {
+		entry += ":" + s.Target
+	}
+	if s.Batch != 0 {
+		entry += ";batch=" + strconv.Itoa(s.Batch)
+	}
+	if s.OnFailure != "" {
+		entry += ";on-failure=" + s.OnFailure
+	}
+	return entry
+}
+
+// SitemapURLs returns the sitemaps of every site, in file order
+func (c *FileConfig) SitemapURLs() []string {
+	var urls []string
+	for _, site := range c.Sites {
+		urls = append(urls, site.Sitemaps...)
+	}
+	return urls
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/parser"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFileConfig(t *testing.T) {
+	path := writeConfig(t, "sites.json", `{
+		"global": {"workers": 10, "api_workers": 4, "api_rate_limit": 1.5, "max_urls": 5000, "schedule": "@every 6h",
+			"politeness": {"max_concurrent": 3, "min_interval": "100ms"}},
+		"providers": [
+			{"url": "https://api-a.example.com/api", "weight": 3, "rps": 2.5, "daily_quota": 5000},
+			{"url": "https://api-b.example.com/api"}
+		],
+		"backend": {"url": "https://backend.example.com", "auth": "bearer"},
+		"sinks": [{"type": "backend"}, {"type": "jsonl", "target": "out/metrics.jsonl", "on_failure": "drop"}],
+		"sites": [
+			{
+				"name": "poki",
+				"sitemaps": ["https://poki.com/sitemap.xml", "https://poki.com/de/sitemap.xml"],
+				"filters": {"include_paths": ["/g/"], "exclude_keywords": ["online"]},
+				"extraction": "path-analysis",
+				"locale": "en-US",
+				"rate_limit": {"requests_per_second": 2, "max_urls": 1000, "max_concurrent": 2, "min_interval": "250ms", "burst": 3},
+				"schedule": "0 */4 * * *",
+				"tags": ["games"]
+			},
+			{"name": "y8", "sitemaps": ["https://www.y8.com/sitemap.xml"]}
+		]
+	}`)
+
+	config, err := LoadFileConfig(path)
+	if err != nil {
+		t.Fatalf("LoadFileConfig failed: %v", err)
+	}
+	if got := config.SitemapURLs(); len(got) != 3 || got[2] != "https://www.y8.com/sitemap.xml" {
+		t.Errorf("SitemapURLs = %v", got)
+	}
+
+	endpoints, err := api.ParseEndpointList(config.EndpointList())
+	if err != nil {
+		t.Fatalf("EndpointList %q does not parse: %v", config.EndpointList(), err)
+	}
+	if len(endpoints) != 2 || endpoints[0].Weight != 3 || endpoints[0].RequestsPerSecond != 2.5 || endpoints[0].DailyQuota != 5000 {
+		t.Errorf("Unexpected endpoints: %+v", endpoints)
+	}
+	sinks, err := backend.ParseSinkList(config.SinkList())
+	if err != nil || len(sinks) != 2 || sinks[1].OnFailure != backend.FailureDrop {
+		t.Errorf("SinkList %q parsed to %+v, %v", config.SinkList(), sinks, err)
+	}
+
+	if limit, ok := config.Sites[0].RateLimit.hostLimit(); !ok || limit.MaxConcurrent != 2 || limit.MinInterval != 250*time.Millisecond || limit.Burst != 3 {
+		t.Errorf("Unexpected site host limit: %+v", limit)
+	}
+	if _, ok := config.Sites[1].RateLimit.hostLimit(); ok {
+		t.Error("A site without politeness settings should keep the global host limit")
+	}
+
+	rules := SiteScheduleRules(config.Sites)
+	if len(rules) != 2 || rules[0].Host != "poki.com" || rules[0].Spec != "0 */4 * * *" {
+		t.Errorf("Unexpected site schedule rules: %+v", rules)
+	}
+}
+
+func TestLoadFileConfigReportsEveryProblem(t *testing.T) {
+	path := writeConfig(t, "bad.json", `{
+		"global": {"workers": 80, "schedule": "10s", "politeness": {"burst": -1}, "removal_grace": "-1h"},
+		"providers": [{"url": "api.example.com"}],
+		"sinks": [{"type": "ftp"}],
+		"sites": [
+			{"name": "a", "sitemaps": ["https://a.example/sitemap.xml"], "extraction": "magic", "rate_limit": {"min_interval": "soon"}},
+			{"name": "a", "sitemaps": ["https://a.example/sitemap.xml"], "locale": "not a locale"},
+			{"sitemaps": []}
+		]
+	}`)
+
+	_, err := LoadFileConfig(path)
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+	for _, field := range []string{
+		"global.workers", "global.schedule", "global.politeness", "global.removal_grace", "providers[0].url", "sinks[0]",
+		"sites[0].extraction", "sites[0].rate_limit.min_interval", "sites[1].name", "sites[1].sitemaps[0]", "sites[1].locale",
+		"sites[2].name is required", "sites[2].sitemaps",
+	} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Error does not mention %s: %v", field, err)
+		}
+	}
+
+	if _, err := LoadFileConfig(writeConfig(t, "typo.json", `{"sites": [], "global": {"wrokers": 4}}`)); err == nil || !strings.Contains(err.Error(), "wrokers") {
+		t.Errorf("Expected unknown field error, got %v", err)
+	}
+	if _, err := LoadFileConfig(writeConfig(t, "sites.yaml", "sites: []")); err == nil || !strings.Contains(err.Error(), "YAML") {
+		t.Errorf("Expected YAML to be rejected, got %v", err)
+	}
+}
+
+func TestSiteProfileSelectsURLs(t *testing.T) {
+	monitor := &SitemapMonitor{rateLimiterPool: NewRateLimiterPool()}
+	defer monitor.rateLimiterPool.Close()
+	monitor.SetSites([]SiteConfig{{
+		Name:     "poki",
+		Sitemaps: []string{"https://poki.com/sitemap.xml"},
+		Filters: SiteFilters{
+			IncludePaths:    []string{"/g/"},
+			ExcludePaths:    []string{"/g/test-"},
+			ExcludeKeywords: []string{"online"},
+		},
+		RateLimit: SiteRateLimit{MaxURLs: 2},
+	}})
+
+	profile := monitor.siteProfiles["https://poki.com/sitemap.xml"]
+	if profile == nil {
+		t.Fatal("Expected a profile for the site's sitemap")
+	}
+	urls := []parser.URL{
+		{Address: "https://poki.com/g/subway-surfers"},
+		{Address: "https://poki.com/blog/news"},
+		{Address: "https://poki.com/g/test-page"},
+		{Address: "https://poki.com/g/online-chess"},
+	}
+	filtered := profile.filterURLs(urls)
+	if len(filtered) != 2 || filtered[0].Address != urls[0].Address || filtered[1].Address != urls[3].Address {
+		t.Errorf("Unexpected filtered URLs: %+v", filtered)
+	}
+	if limit := profile.urlLimit(100000); limit != 2 {
+		t.Errorf("urlLimit = %d, want the site's 2", limit)
+	}
+	if limit := monitor.siteProfiles["https://y8.com/sitemap.xml"].urlLimit(100000); limit != 100000 {
+		t.Errorf("urlLimit without a site = %d, want the global limit", limit)
+	}
+
+	keywords, err := profile.extractor.Extract("https://poki.com/g/online-chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keyword := range keywords {
+		if keyword == "online" {
+			t.Errorf("Stop word survived extraction: %v", keywords)
+		}
+	}
+}
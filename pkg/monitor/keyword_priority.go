@@ -0,0 +1,243 @@
+package monitor
+
+import (
+	"container/heap"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/parser"
+)
+
+// URLSignals are hints taken from a page's sitemap entry
+type URLSignals struct {
+	Priority float64   `json:"priority"`           // <priority> value, -1 when the sitemap omits it
+	LastMod  time.Time `json:"lastmod"`            // Zero when absent or unparseable
+	HrefLang string    `json:"hreflang,omitempty"` // Language the page declares for itself via xhtml:link
+}
+
+// lastModFormats covers W3C datetime variants used by sitemaps plus RSS parser output
+var lastModFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// signalsFromURL extracts sitemap priority and lastmod from a parsed URL
+func signalsFromURL(u parser.URL) URLSignals {
+	signals := URLSignals{Priority: -1, HrefLang: u.Metadata["hreflang"]}
+	if raw := strings.TrimSpace(u.Metadata["priority"]); raw != "" {
+		if priority, err := strconv.ParseFloat(raw, 64); err == nil && priority >= 0 && priority <= 1 {
+			signals.Priority = priority
+		}
+	}
+	if raw := strings.TrimSpace(u.LastUpdated); raw != "" {
+		for _, layout := range lastModFormats {
+			if parsed, err := time.Parse(layout, raw); err == nil {
+				signals.LastMod = parsed
+				break
+			}
+		}
+	}
+	return signals
+}
+
+// KeywordCandidate is a keyword waiting to be queried, with the signals used to rank it
+type KeywordCandidate struct {
+	Keyword        string     `json:"keyword"` // Query key, see api.LocaleKey
+	Locale         api.Locale `json:"locale"`
+	URL            string     `json:"url"`
+	SitemapURL     string     `json:"sitemap_url"`
+	NewURL         bool       `json:"new_url"` // Discovered this run rather than carried over
+	Signals        URLSignals `json:"signals"`
+	SiteCount      int        `json:"site_count"`      // Distinct sites listing the same game
+	PreviousVolume int64      `json:"previous_volume"` // Last known monthly searches, -1 when never queried
+	Score          float64    `json:"score"`
+}
+
+// KeywordPriorityWeights controls how much each signal contributes to a keyword's score
+// Every signal is normalised to 0-1 before weighting, so weights compare directly
+type KeywordPriorityWeights struct {
+	NewURL            float64       `json:"new_url"`
+	SitemapPriority   float64       `json:"sitemap_priority"`
+	Freshness         float64       `json:"freshness"`
+	SiteCount         float64       `json:"site_count"`
+	SearchVolume      float64       `json:"search_volume"`
+	FreshnessHalfLife time.Duration `json:"freshness_half_life"`
+}
+
+// DefaultKeywordPriorityWeights favours games that many sites list and that searched well before
+func DefaultKeywordPriorityWeights() KeywordPriorityWeights {
+	return KeywordPriorityWeights{
+		NewURL:            1.0,
+		SitemapPriority:   1.0,
+		Freshness:         1.5,
+		SiteCount:         2.0,
+		SearchVolume:      2.5,
+		FreshnessHalfLife: 7 * 24 * time.Hour,
+	}
+}
+
+// Score computes the value of querying a keyword now
+func (w KeywordPriorityWeights) Score(c KeywordCandidate, now time.Time) float64 {
+	score := 0.0
+	if c.NewURL {
+		score += w.NewURL
+	}
+
+	priority := c.Signals.Priority
+	if priority < 0 {
+		priority = 0.5 // Sitemap protocol default
+	}
+	score += w.SitemapPriority * priority
+
+	if !c.Signals.LastMod.IsZero() && w.FreshnessHalfLife > 0 {
+		age := now.Sub(c.Signals.LastMod)
+		if age < 0 {
+			age = 0
+		}
+		score += w.Freshness * math.Exp2(-float64(age)/float64(w.FreshnessHalfLife))
+	}
+
+	if c.SiteCount > 1 {
+		score += w.SiteCount * math.Min(1, float64(c.SiteCount-1)/4) // Saturates at 5 sites
+	}
+
+	if c.PreviousVolume > 0 {
+		score += w.SearchVolume * math.Min(1, math.Log10(float64(c.PreviousVolume)+1)/6) // Saturates at 1M searches
+	}
+	return score
+}
+
+// KeywordQueue is a thread-safe max-heap of keyword candidates ordered by score
+// Equal scores keep insertion order so runs are deterministic
+type KeywordQueue struct {
+	items candidateHeap
+	next  int
+	mu    sync.Mutex
+}
+
+// NewKeywordQueue creates a queue holding the given scored candidates
+func NewKeywordQueue(candidates []KeywordCandidate) *KeywordQueue {
+	queue := &KeywordQueue{}
+	for _, candidate := range candidates {
+		queue.items = append(queue.items, queuedCandidate{candidate: candidate, seq: queue.next})
+		queue.next++
+	}
+	heap.Init(&queue.items)
+	return queue
+}
+
+// Push adds a candidate to the queue
+func (q *KeywordQueue) Push(candidate KeywordCandidate) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	heap.Push(&q.items, queuedCandidate{candidate: candidate, seq: q.next})
+	q.next++
+}
+
+// PopBatch removes up to n of the highest-scoring candidates that share one locale
+// A batch is one API request, and a request targets a single locale; candidates of other
+// locales met while filling the batch are put back with their original order
+func (q *KeywordQueue) PopBatch(n int) []KeywordCandidate {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 || n <= 0 {
+		return nil
+	}
+
+	first := heap.Pop(&q.items).(queuedCandidate)
+	batch := []KeywordCandidate{first.candidate}
+	var skipped []queuedCandidate
+	for len(batch) < n && len(q.items) > 0 && len(skipped) < 4*n {
+		item := heap.Pop(&q.items).(queuedCandidate)
+		if item.candidate.Locale != first.candidate.Locale {
+			skipped = append(skipped, item)
+			continue
+		}
+		batch = append(batch, item.candidate)
+	}
+	for _, item := range skipped {
+		heap.Push(&q.items, item)
+	}
+	return batch
+}
+
+// Drain removes and returns all remaining candidates, highest score first
+func (q *KeywordQueue) Drain() []KeywordCandidate {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	drained := make([]KeywordCandidate, 0, len(q.items))
+	for len(q.items) > 0 {
+		drained = append(drained, heap.Pop(&q.items).(queuedCandidate).candidate)
+	}
+	return drained
+}
+
+// Len returns the number of queued candidates
+func (q *KeywordQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+type queuedCandidate struct {
+	candidate KeywordCandidate
+	seq       int
+}
+
+// candidateHeap implements heap.Interface as a max-heap on score
+type candidateHeap []queuedCandidate
+
+func (h candidateHeap) Len() int { return len(h) }
+func (h candidateHeap) Less(i, j int) bool {
+	if h[i].candidate.Score != h[j].candidate.Score {
+		return h[i].candidate.Score > h[j].candidate.Score
+	}
+	return h[i].seq < h[j].seq
+}
+func (h candidateHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x interface{}) { *h = append(*h, x.(queuedCandidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// buildKeywordCandidates scores keywords using sitemap signals, cross-site popularity and volume history
+// volumes holds the last known search volume per query key
+func (sm *SitemapMonitor) buildKeywordCandidates(volumes map[string]int64, keywords []string, keywordToSpecificURLMap, keywordToSitemapMap map[string]string, urlSignals map[string]URLSignals, siteCounts map[string]int, carriedOver map[string]bool) []KeywordCandidate {
+	weights := DefaultKeywordPriorityWeights()
+	now := time.Now()
+
+	candidates := make([]KeywordCandidate, 0, len(keywords))
+	for _, keyword := range keywords {
+		pageURL := keywordToSpecificURLMap[keyword]
+		word, locale := api.SplitLocaleKey(keyword)
+		candidate := KeywordCandidate{
+			Keyword:        keyword,
+			Locale:         locale,
+			URL:            pageURL,
+			SitemapURL:     keywordToSitemapMap[keyword],
+			NewURL:         !carriedOver[keyword],
+			Signals:        URLSignals{Priority: -1},
+			SiteCount:      siteCounts[sm.normalizeForDeduplication(word)],
+			PreviousVolume: -1,
+		}
+		if signals, exists := urlSignals[pageURL]; exists {
+			candidate.Signals = signals
+		}
+		if volume, exists := volumes[keyword]; exists {
+			candidate.PreviousVolume = volume
+		}
+		candidate.Score = weights.Score(candidate, now)
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/parser"
+)
+
+func TestSignalsFromURL(t *testing.T) {
+	signals := signalsFromURL(parser.URL{
+		LastUpdated: "2026-10-01",
+		Metadata:    map[string]string{"priority": "0.8"},
+	})
+	if signals.Priority != 0.8 || signals.LastMod.Format("2006-01-02") != "2026-10-01" {
+		t.Errorf("Unexpected signals: %+v", signals)
+	}
+
+	missing := signalsFromURL(parser.URL{LastUpdated: "not a date", Metadata: map[string]string{"priority": ""}})
+	if missing.Priority != -1 || !missing.LastMod.IsZero() {
+		t.Errorf("Expected unknown signals, got %+v", missing)
+	}
+}
+
+func TestKeywordPriorityScoreOrdering(t *testing.T) {
+	weights := DefaultKeywordPriorityWeights()
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	base := KeywordCandidate{Signals: URLSignals{Priority: -1}, PreviousVolume: -1}
+	popular := base
+	popular.SiteCount = 5
+	popular.PreviousVolume = 100000
+	fresh := base
+	fresh.Signals.LastMod = now.Add(-time.Hour)
+	stale := base
+	stale.Signals.LastMod = now.Add(-90 * 24 * time.Hour)
+
+	if weights.Score(popular, now) <= weights.Score(base, now) {
+		t.Error("Popular keyword should outrank an unknown one")
+	}
+	if weights.Score(fresh, now) <= weights.Score(stale, now) {
+		t.Error("Recently modified page should outrank a stale one")
+	}
+}
+
+func TestKeywordQueueDrainsHighestFirst(t *testing.T) {
+	queue := NewKeywordQueue([]KeywordCandidate{
+		{Keyword: "low", Score: 1},
+		{Keyword: "high", Score: 5},
+		{Keyword: "mid-a", Score: 3},
+		{Keyword: "mid-b", Score: 3},
+	})
+	queue.Push(KeywordCandidate{Keyword: "top", Score: 9})
+
+	batch := queue.PopBatch(2)
+	if batch[0].Keyword != "top" || batch[1].Keyword != "high" {
+		t.Errorf("Unexpected first batch: %+v", batch)
+	}
+
+	rest := queue.Drain()
+	if len(rest) != 3 || rest[0].Keyword != "mid-a" || rest[1].Keyword != "mid-b" || rest[2].Keyword != "low" {
+		t.Errorf("Expected ties in insertion order, got %+v", rest)
+	}
+	if queue.Len() != 0 || len(queue.PopBatch(10)) != 0 {
+		t.Error("Queue should be empty after drain")
+	}
+}
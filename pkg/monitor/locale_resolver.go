@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/extractor"
+)
+
+// LocaleRule pins a locale to a whole site or to a path section of a site
+type LocaleRule struct {
+	Host       string     `json:"host"`        // Host without "www.", "*" matches every site
+	PathPrefix string     `json:"path_prefix"` // "" applies to the whole site
+	Locale     api.Locale `json:"locale"`
+}
+
+// ParseLocaleRules parses a comma-separated rule list:
+//
+//	poki.de=de-DE,example.com/fr/=fr-FR,*=en
+func ParseLocaleRules(raw string) ([]LocaleRule, error) {
+	var rules []LocaleRule
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		target, tag, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid locale rule %q (expected site[/path]=locale)", entry)
+		}
+		locale, err := api.ParseLocale(tag)
+		if err != nil || locale.IsZero() {
+			return nil, fmt.Errorf("invalid locale rule %q: %v", entry, err)
+		}
+
+		target = strings.TrimSpace(target)
+		target = strings.TrimPrefix(strings.TrimPrefix(target, "https://"), "http://")
+		host, path, _ := strings.Cut(target, "/")
+		host = strings.TrimPrefix(strings.ToLower(host), "www.")
+		if host == "" {
+			return nil, fmt.Errorf("invalid locale rule %q: missing site", entry)
+		}
+		rule := LocaleRule{Host: host, Locale: locale}
+		if path != "" {
+			rule.PathPrefix = "/" + strings.Trim(path, "/") + "/"
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// LocaleResolver decides which locale a page's keyword is queried in
+// Precedence: path rules, hreflang, detected path section, site rules, wildcard rule
+type LocaleResolver struct {
+	pathRules []LocaleRule // Longest prefix first
+	siteRules []LocaleRule // Wildcard last
+	detect    bool
+	analyzer  *extractor.PathAnalyzer
+}
+
+// NewLocaleResolver creates a resolver from explicit rules; detect enables hreflang and /de/-style detection
+func NewLocaleResolver(rules []LocaleRule, detect bool) *LocaleResolver {
+	resolver := &LocaleResolver{detect: detect, analyzer: extractor.NewPathAnalyzer()}
+	for _, rule := range rules {
+		if rule.PathPrefix != "" {
+			resolver.pathRules = append(resolver.pathRules, rule)
+		} else {
+			resolver.siteRules = append(resolver.siteRules, rule)
+		}
+	}
+	sort.SliceStable(resolver.pathRules, func(i, j int) bool {
+		return len(resolver.pathRules[i].PathPrefix) > len(resolver.pathRules[j].PathPrefix)
+	})
+	sort.SliceStable(resolver.siteRules, func(i, j int) bool {
+		return resolver.siteRules[j].Host == "*" && resolver.siteRules[i].Host != "*"
+	})
+	return resolver
+}
+
+// Enabled reports whether the resolver can produce any locale at all
+func (r *LocaleResolver) Enabled() bool {
+	return r != nil && (r.detect || len(r.pathRules) > 0 || len(r.siteRules) > 0)
+}
+
+// Resolve returns the locale for a page URL; hreflang is the page's own sitemap hreflang, if any
+func (r *LocaleResolver) Resolve(pageURL, hreflang string) api.Locale {
+	if !r.Enabled() {
+		return api.Locale{}
+	}
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return api.Locale{}
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	path := parsed.Path
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	for _, rule := range r.pathRules {
+		if hostMatches(rule.Host, host) && strings.HasPrefix(path, rule.PathPrefix) {
+			return rule.Locale
+		}
+	}
+
+	if r.detect {
+		if locale, err := api.ParseLocale(hreflang); err == nil && !locale.IsZero() {
+			return locale
+		}
+		if detected := r.analyzer.DetectLocale(parsed.Path); detected != "" {
+			if locale, err := api.ParseLocale(detected); err == nil {
+				return locale
+			}
+		}
+	}
+
+	for _, rule := range r.siteRules {
+		if hostMatches(rule.Host, host) {
+			return rule.Locale
+		}
+	}
+	return api.Locale{}
+}
+
+// SetLocaleResolver enables locale-targeted queries; nil queries every keyword without a locale
+func (sm *SitemapMonitor) SetLocaleResolver(resolver *LocaleResolver) {
+	sm.localeResolver = resolver
+}
+
+// hostMatches matches a rule host against a page host, including subdomains
+func hostMatches(ruleHost, host string) bool {
+	return ruleHost == "*" || host == ruleHost || strings.HasSuffix(host, "."+ruleHost)
+}
+
+// processedURLKey keys the processed-URL cache by page and locale, so a page whose locale
+// changes is queried again; pages without a locale keep their plain URL key
+func processedURLKey(queryKey, pageURL string) string {
+	_, locale := api.SplitLocaleKey(queryKey)
+	return api.LocaleKey(pageURL, locale)
+}
@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"testing"
+)
+
+func TestLocaleResolverPrecedence(t *testing.T) {
+	rules, err := ParseLocaleRules("example.com=en-US, example.com/fr/=fr-CA, *=en")
+	if err != nil {
+		t.Fatalf("ParseLocaleRules failed: %v", err)
+	}
+	resolver := NewLocaleResolver(rules, true)
+
+	cases := []struct {
+		url      string
+		hreflang string
+		want     string
+	}{
+		{"https://www.example.com/fr/game", "fr-FR", "fr-CA"},        // Path rule beats hreflang
+		{"https://example.com/play/game", "es-MX", "es-MX"},          // hreflang beats site rule
+		{"https://example.com/de/game", "", "de"},                    // Detected path section
+		{"https://example.com/games/action", "", "en-US"},            // Site rule
+		{"https://games.other.org/id/12345", "", "en"},               // "id" is not a locale; wildcard
+		{"https://games.other.org/pt-br/jogo", "x-default", "pt-BR"}, // x-default ignored
+	}
+	for _, c := range cases {
+		if got := resolver.Resolve(c.url, c.hreflang).String(); got != c.want {
+			t.Errorf("Resolve(%s, %q) = %q, want %q", c.url, c.hreflang, got, c.want)
+		}
+	}
+
+	var disabled *LocaleResolver
+	if !disabled.Resolve("https://example.com/de/game", "de").IsZero() {
+		t.Error("Nil resolver must not produce a locale")
+	}
+	if _, err := ParseLocaleRules("example.com"); err == nil {
+		t.Error("Expected error for rule without locale")
+	}
+}
+
+func TestKeywordQueueBatchesShareLocale(t *testing.T) {
+	de := KeywordCandidate{Keyword: "a@de", Score: 5}
+	de.Locale.Language = "de"
+	de2 := KeywordCandidate{Keyword: "c@de", Score: 3}
+	de2.Locale.Language = "de"
+	queue := NewKeywordQueue([]KeywordCandidate{de, {Keyword: "b", Score: 4}, de2})
+
+	first := queue.PopBatch(10)
+	if len(first) != 2 || first[0].Keyword != "a@de" || first[1].Keyword != "c@de" {
+		t.Errorf("Expected the two German keywords together, got %+v", first)
+	}
+	if second := queue.PopBatch(10); len(second) != 1 || second[0].Keyword != "b" {
+		t.Errorf("Expected the untargeted keyword left over, got %+v", second)
+	}
+}
+
+func TestProcessedURLKeyIncludesLocale(t *testing.T) {
+	page := "https://example.com/games/subway-surfers"
+	if got := processedURLKey("subway surfers", page); got != page {
+		t.Errorf("Expected the plain URL without a locale, got %q", got)
+	}
+	german := processedURLKey("subway surfers@de-DE", page)
+	french := processedURLKey("subway surfers@fr-FR", page)
+	if german == page || german == french {
+		t.Errorf("Expected a distinct key per locale, got %q and %q", german, french)
+	}
+}
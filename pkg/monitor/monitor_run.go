@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/parser"
+	"sitemap-go/pkg/storage"
+)
+
+// monitorRun holds the state of one ProcessSitemaps call; it travels in the run's context, so
+// runs of different sites can overlap on one monitor
+type monitorRun struct {
+	id                 string                      // Identifies the run in idempotency keys
+	stats              runStats                    // Counters for the run
+	checkpoint         *runCheckpoint              // Progress of the run, nil when not recorded
+	quotaExhausted     int32                       // Set atomically once the API budget runs out
+	submissionBaseline backend.SubmissionStats     // Pool counters when the run started
+	hostBaseline       map[string]parser.HostStats // Host limiter counters when the run started
+}
+
+type monitorRunKey struct{}
+
+// withRun carries the run in ctx, with its ID for the tracker records it stamps
+func withRun(ctx context.Context, run *monitorRun) context.Context {
+	return context.WithValue(storage.WithRunID(ctx, run.id), monitorRunKey{}, run)
+}
+
+// runState keeps the latest run for callers outside any run, like GetRunSummary after ProcessSitemaps
+type runState struct {
+	mu      sync.Mutex
+	lastRun *monitorRun
+}
+
+// currentRun returns the run ctx belongs to; outside a run, the latest one
+func (sm *SitemapMonitor) currentRun(ctx context.Context) *monitorRun {
+	if run, ok := ctx.Value(monitorRunKey{}).(*monitorRun); ok {
+		return run
+	}
+	sm.runs.mu.Lock()
+	defer sm.runs.mu.Unlock()
+	if sm.runs.lastRun == nil {
+		sm.runs.lastRun = &monitorRun{}
+	}
+	return sm.runs.lastRun
+}
+
+// beginRun starts a run with a fresh ID, or the checkpointed one when resuming
+// Runs without a checkpoint can overlap: there is one checkpoint, for one run at a time
+func (sm *SitemapMonitor) beginRun(ctx context.Context, sitemapURLs []string, checkpoint bool) *monitorRun {
+	run := &monitorRun{
+		id:                 backend.NewRunID(),
+		submissionBaseline: sm.submissionPool.GetStats(),
+		hostBaseline:       make(map[string]parser.HostStats),
+	}
+	for _, stats := range parser.DefaultHostLimiter().Stats() {
+		run.hostBaseline[stats.Host] = stats
+	}
+	if checkpoint {
+		run.checkpoint = sm.beginCheckpoint(ctx, run, sitemapURLs)
+	}
+	run.stats.update(func(summary *RunSummary) {
+		summary.RunID = run.id
+		summary.Resumed = run.checkpoint != nil && run.checkpoint.resumed
+	})
+
+	sm.runs.mu.Lock()
+	sm.runs.lastRun = run
+	sm.runs.mu.Unlock()
+	return run
+}
+
+func (run *monitorRun) quotaSpent() bool {
+	return atomic.LoadInt32(&run.quotaExhausted) == 1
+}
+
+func (run *monitorRun) spendQuota() {
+	atomic.StoreInt32(&run.quotaExhausted, 1)
+}
@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/logger"
+)
+
+func TestOverlappingRunsKeepTheirOwnState(t *testing.T) {
+	sm := &SitemapMonitor{
+		submissionPool: backend.NewSubmissionPool(backend.NewRecordingClient(), 1),
+		log:            logger.GetLogger(),
+	}
+	slow := sm.beginRun(context.Background(), []string{"https://slow.example/sitemap.xml"}, false)
+	slowCtx := withRun(context.Background(), slow)
+	fast := sm.beginRun(context.Background(), []string{"https://fast.example/sitemap.xml"}, false)
+	fastCtx := withRun(context.Background(), fast)
+	if slow.id == fast.id {
+		t.Fatal("Overlapping runs must have their own run IDs")
+	}
+
+	sm.currentRun(slowCtx).stats.update(func(summary *RunSummary) { summary.QueriedKeywords = 3 })
+	fast.spendQuota()
+	if summary := sm.GetRunSummary(fastCtx); summary.RunID != fast.id || summary.QueriedKeywords != 0 {
+		t.Errorf("The fast run picked up the slow run's counters: %+v", summary)
+	}
+	if summary := sm.GetRunSummary(slowCtx); summary.RunID != slow.id || summary.QueriedKeywords != 3 {
+		t.Errorf("Unexpected slow run summary: %+v", summary)
+	}
+	if slow.quotaSpent() {
+		t.Error("An exhausted quota in one run must not stop the other")
+	}
+	if sm.GetRunSummary(context.Background()).RunID != fast.id {
+		t.Error("Outside a run the summary must be the latest run's")
+	}
+
+	// Interrupt stops the upstream stages of every running run
+	slowUpstream, doneSlow := sm.upstreamContext(slowCtx, slow)
+	fastUpstream, doneFast := sm.upstreamContext(fastCtx, fast)
+	doneFast()
+	sm.Interrupt()
+	if slowUpstream.Err() == nil || fastUpstream.Err() == nil {
+		t.Error("Expected the upstream stages of both runs cancelled")
+	}
+	doneSlow()
+	if len(sm.cancelUpstream) != 0 {
+		t.Errorf("Finished runs must not stay registered, %d left", len(sm.cancelUpstream))
+	}
+}
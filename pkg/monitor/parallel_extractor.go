@@ -33,7 +33,7 @@ func putStringSlice(s []string) {
 
 // ParallelKeywordExtractor extracts keywords from URLs using optimized concurrency
 type ParallelKeywordExtractor struct {
-	extractor *extractor.URLKeywordExtractor
+	extractor extractor.KeywordExtractor
 	workers   int
 }
 
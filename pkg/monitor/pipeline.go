@@ -0,0 +1,442 @@
+package monitor
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sitemap-go/pkg/api"
+)
+
+const (
+	// candidateBufferSize bounds the candidates waiting for the query stage; a full buffer
+	// holds the parsers back. Candidates are ranked by value within the buffer
+	candidateBufferSize = 2000
+	// candidateWorkers dedupe, filter and score extracted sitemaps concurrently
+	candidateWorkers = 4
+)
+
+// sitemapExtract is what parsing and extracting one sitemap produced
+type sitemapExtract struct {
+	sitemapURL string
+	keywords   []string // Primary keyword per page, in page order
+	urls       []string
+	signals    map[string]URLSignals
+	changes    *SitemapChanges // nil when the sitemap was not compared with a snapshot
+	modified   map[string]bool // Pages to query again because they changed (changed-only runs)
+	err        error
+}
+
+// streamSitemaps parses sitemaps and extracts their keywords concurrently, sending each
+// sitemap as soon as it is done. The channel holds one result per worker, so a slow
+// consumer holds the parsers back. It is closed once every sitemap has been sent
+func (sm *SitemapMonitor) streamSitemaps(ctx context.Context, sitemapURLs []string, workers int) <-chan sitemapExtract {
+	// Use adaptive concurrency settings instead of fixed workers count
+	config := sm.concurrencyManager.GetCurrentConfig()
+	actualWorkers := config.MainWorkers
+	if len(sitemapURLs) < actualWorkers {
+		actualWorkers = len(sitemapURLs) // Don't exceed sitemap count
+	}
+	if actualWorkers < 1 {
+		actualWorkers = 1
+	}
+	sm.secureLog.SafeInfo("Starting optimized concurrent sitemap processing", map[string]interface{}{
+		"requested_workers": workers,
+		"actual_workers":    actualWorkers,
+		"sitemap_count":     len(sitemapURLs),
+	})
+
+	jobs := make(chan string)
+	out := make(chan sitemapExtract, actualWorkers)
+	var wg sync.WaitGroup
+	for i := 0; i < actualWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sitemapURL := range jobs {
+				out <- sm.extractSitemap(ctx, sitemapURL)
+			}
+		}()
+	}
+	go func() {
+		for _, sitemapURL := range sitemapURLs {
+			jobs <- sitemapURL
+		}
+		close(jobs)
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// extractSitemap parses and extracts one sitemap, or takes it from a resumed checkpoint
+func (sm *SitemapMonitor) extractSitemap(ctx context.Context, sitemapURL string) sitemapExtract {
+	// A resumed run reuses what the interrupted run extracted; its change events were already sent
+	run := sm.currentRun(ctx)
+	if keywords, urls, signals, ok := run.checkpoint.parsedSitemap(sitemapURL); ok {
+		run.stats.update(func(summary *RunSummary) { summary.ResumedSitemaps++ })
+		return sitemapExtract{sitemapURL: sitemapURL, keywords: keywords, urls: urls, signals: signals}
+	}
+
+	// Rate limiting should only apply to API calls, not local keyword extraction
+	startTime := time.Now()
+	extract := sm.extractKeywordsFromSitemap(ctx, sitemapURL)
+	// Update performance metrics for adaptive adjustment
+	sm.concurrencyManager.UpdateMetrics(time.Since(startTime), extract.err == nil)
+	if extract.err == nil {
+		run.checkpoint.recordSitemap(ctx, sitemapURL, extract.keywords, extract.urls, extract.signals)
+	}
+	return extract
+}
+
+// monitorResult reports an extracted sitemap
+func (sm *SitemapMonitor) monitorResult(extract sitemapExtract) *MonitorResult {
+	result := &MonitorResult{
+		SitemapURL: extract.sitemapURL,
+		Keywords:   extract.keywords,
+		Success:    extract.err == nil,
+		Timestamp:  time.Now(),
+		Metadata:   make(map[string]interface{}),
+		Changes:    extract.changes,
+	}
+	if extract.err != nil {
+		result.Error = extract.err.Error()
+	} else {
+		result.Metadata["url_count"] = len(extract.urls)
+	}
+	if profile := sm.siteProfiles[extract.sitemapURL]; profile != nil {
+		result.Metadata["site"] = profile.name
+		if len(profile.tags) > 0 {
+			result.Metadata["tags"] = profile.tags
+		}
+	}
+	return result
+}
+
+// queryKeys turns a sitemap's keywords into query keys ("action-games" → "action games",
+// locale-qualified for localized pages), with the page URL of each key when known
+func (sm *SitemapMonitor) queryKeys(extract sitemapExtract) ([]string, []string) {
+	keys := make([]string, len(extract.keywords))
+	pages := make([]string, len(extract.keywords))
+	for i, keyword := range extract.keywords {
+		keys[i] = sm.formatKeywordForAPI(keyword)
+		if i < len(extract.urls) {
+			pageURL := extract.urls[i]
+			locale := sm.localeResolver.Resolve(pageURL, extract.signals[pageURL].HrefLang)
+			keys[i] = api.LocaleKey(keys[i], locale)
+			pages[i] = pageURL
+		}
+	}
+	return keys, pages
+}
+
+// keywordSet is the run-wide set of normalised keywords used for global deduplication
+// It is safe for concurrent use
+type keywordSet struct {
+	keys sync.Map
+	size int64
+}
+
+// add reports whether the keyword was not in the set yet
+func (s *keywordSet) add(keyword string) bool {
+	if _, loaded := s.keys.LoadOrStore(keyword, struct{}{}); loaded {
+		return false
+	}
+	atomic.AddInt64(&s.size, 1)
+	return true
+}
+
+func (s *keywordSet) len() int {
+	return int(atomic.LoadInt64(&s.size))
+}
+
+// candidateStream connects the candidate stage to the query workers: a bounded priority queue
+// that producers wait on while it is full and workers wait on until candidates arrive or the
+// producers are done
+type candidateStream struct {
+	queue     *KeywordQueue
+	capacity  int
+	mu        sync.Mutex
+	cond      *sync.Cond
+	pushed    int
+	closed    bool          // No more candidates will be pushed
+	abandoned bool          // Workers have stopped; pushes no longer wait for room
+	done      chan struct{} // Closed together with closed
+}
+
+func newCandidateStream(capacity int) *candidateStream {
+	s := &candidateStream{
+		queue:    NewKeywordQueue(nil),
+		capacity: capacity,
+		done:     make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// push queues candidates, waiting for room while the buffer is full
+// Once ctx ends or the workers stop, candidates are queued without waiting so that they are
+// carried over rather than lost
+func (s *candidateStream) push(ctx context.Context, candidates []KeywordCandidate) {
+	stop := context.AfterFunc(ctx, s.wake)
+	defer stop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, candidate := range candidates {
+		for s.queue.Len() >= s.capacity && !s.abandoned && ctx.Err() == nil {
+			s.cond.Wait()
+		}
+		s.queue.Push(candidate)
+		s.pushed++
+		s.cond.Broadcast()
+	}
+}
+
+// popBatch takes up to n of the most valuable candidates, waiting until n are buffered so
+// requests are not spent on partial batches while producers are still running
+// It returns nil once the stream is closed and empty, ctx ends or stop reports true
+func (s *candidateStream) popBatch(ctx context.Context, n int, stop func() bool) []KeywordCandidate {
+	release := context.AfterFunc(ctx, s.wake)
+	defer release()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if ctx.Err() != nil || stop() {
+			return nil
+		}
+		if s.closed || s.queue.Len() >= n {
+			if batch := s.queue.PopBatch(n); len(batch) > 0 {
+				s.cond.Broadcast() // Room for producers
+				return batch
+			}
+			if s.closed {
+				return nil
+			}
+		}
+		s.cond.Wait()
+	}
+}
+
+// close marks the end of the candidates
+func (s *candidateStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	s.cond.Broadcast()
+}
+
+// abandon stops producers from waiting once no worker will take more candidates
+func (s *candidateStream) abandon() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.abandoned = true
+	s.cond.Broadcast()
+}
+
+// drain waits for the producers to finish and returns every candidate left, most valuable first
+func (s *candidateStream) drain() []KeywordCandidate {
+	<-s.done
+	return s.queue.Drain()
+}
+
+// total is the number of candidates pushed so far
+func (s *candidateStream) total() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pushed
+}
+
+func (s *candidateStream) wake() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+// keywordPipeline turns parsed sitemaps into ranked query candidates as they arrive:
+// parse → extract → dedup → filter → score, feeding the query workers through its stream
+type keywordPipeline struct {
+	sm       *SitemapMonitor
+	stream   *candidateStream
+	seen     keywordSet
+	volumes  map[string]int64 // Last known search volume per query key
+	query    bool             // Candidates go to the query stage; a dry run without API access only records them
+	mu       sync.Mutex
+	results  []*MonitorResult
+	hosts    map[string]map[string]bool // Normalised keyword -> sitemap hosts listing it
+	total    int                        // Keywords extracted
+	filtered int                        // Unique keywords left after the URL and no-data filters
+	done     chan struct{}
+}
+
+func (sm *SitemapMonitor) newKeywordPipeline(ctx context.Context) *keywordPipeline {
+	return &keywordPipeline{
+		sm:      sm,
+		stream:  newCandidateStream(candidateBufferSize),
+		volumes: sm.simpleTracker.LoadKeywordVolumes(ctx),
+		query:   sm.dryRun == nil || sm.dryRun.queryAPI,
+		hosts:   make(map[string]map[string]bool),
+		done:    make(chan struct{}),
+	}
+}
+
+// start feeds the stream in the background: keywords deferred by earlier runs (and those an
+// interrupted run left, when resuming) first, then every sitemap's keywords as it is parsed
+// Parsing uses upstreamCtx, waiting on the query stage uses ctx
+func (p *keywordPipeline) start(ctx, upstreamCtx context.Context, sitemapURLs []string, workers int) {
+	go func() {
+		defer close(p.done)
+		defer p.stream.close()
+
+		p.seed(ctx)
+
+		extracts := p.sm.streamSitemaps(upstreamCtx, sitemapURLs, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < candidateWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for extract := range extracts {
+					p.addSitemap(ctx, extract)
+				}
+			}()
+		}
+		wg.Wait()
+
+		p.sm.log.WithFields(map[string]interface{}{
+			"total_sitemaps":        len(sitemapURLs),
+			"keywords_before_dedup": p.total,
+			"keywords_after_dedup":  p.seen.len(),
+			"keywords_after_filter": p.filtered,
+			"queued_keywords":       p.stream.total(),
+		}).Info("Keyword pipeline finished extracting")
+	}()
+}
+
+// wait blocks until every sitemap went through the pipeline and returns their results
+func (p *keywordPipeline) wait() []*MonitorResult {
+	<-p.done
+	return p.results
+}
+
+// seed queues the keywords that are not discovered from this run's sitemaps
+func (p *keywordPipeline) seed(ctx context.Context) {
+	keywordToSpecificURLMap := make(map[string]string)
+	keywordToSitemapMap := make(map[string]string)
+	keywords, carriedOver := p.sm.mergePendingKeywords(ctx, nil, keywordToSpecificURLMap, keywordToSitemapMap)
+	keywords, alreadyQueried := p.sm.currentRun(ctx).checkpoint.resumeKeywords(keywords, keywordToSpecificURLMap, keywordToSitemapMap)
+	p.countResumed(ctx, alreadyQueried)
+
+	var fresh []string
+	for _, keyword := range keywords {
+		if p.seen.add(p.sm.normalizeForDeduplication(keyword)) {
+			fresh = append(fresh, keyword)
+		}
+	}
+	p.offer(ctx, p.sm.buildKeywordCandidates(p.volumes, fresh, keywordToSpecificURLMap, keywordToSitemapMap, nil, nil, carriedOver))
+}
+
+// addSitemap dedupes, filters and scores one sitemap's keywords and queues the new ones
+func (p *keywordPipeline) addSitemap(ctx context.Context, extract sitemapExtract) {
+	sm := p.sm
+	p.mu.Lock()
+	p.results = append(p.results, sm.monitorResult(extract))
+	p.mu.Unlock()
+	if extract.err != nil {
+		return
+	}
+
+	keys, pages := sm.queryKeys(extract)
+	siteCounts := p.countSites(extract)
+	keywordToSpecificURLMap := make(map[string]string, len(keys))
+	keywordToSitemapMap := make(map[string]string, len(keys))
+	var unique []string
+	for i, key := range keys {
+		if !p.seen.add(sm.normalizeForDeduplication(key)) {
+			continue
+		}
+		unique = append(unique, key)
+		if pages[i] != "" {
+			keywordToSpecificURLMap[key] = pages[i]
+		}
+		keywordToSitemapMap[key] = extract.sitemapURL
+	}
+
+	// URL-level dedup: pages answered by earlier runs are not queried again, unless they changed
+	var refresh []string
+	if len(extract.modified) > 0 {
+		var kept []string
+		for _, key := range unique {
+			if extract.modified[keywordToSpecificURLMap[key]] {
+				refresh = append(refresh, key)
+			} else {
+				kept = append(kept, key)
+			}
+		}
+		unique = kept
+	}
+	filtered, err := sm.filterUnprocessedKeywordURLs(ctx, unique, keywordToSpecificURLMap)
+	if err != nil {
+		sm.secureLog.SafeError("Failed to filter processed URLs", err, nil)
+		filtered = unique // Fallback to process all
+	}
+	filtered = append(filtered, refresh...)
+	filtered = sm.skipNoDataBackoff(ctx, filtered)
+	filtered, alreadyQueried := sm.currentRun(ctx).checkpoint.dropQueried(filtered)
+	p.countResumed(ctx, alreadyQueried)
+
+	p.mu.Lock()
+	p.total += len(keys)
+	p.filtered += len(filtered)
+	p.mu.Unlock()
+
+	p.offer(ctx, sm.buildKeywordCandidates(p.volumes, filtered, keywordToSpecificURLMap, keywordToSitemapMap, extract.signals, siteCounts, nil))
+}
+
+// offer records candidates in the checkpoint (and dry-run report) and queues them for querying
+func (p *keywordPipeline) offer(ctx context.Context, candidates []KeywordCandidate) {
+	if len(candidates) == 0 {
+		return
+	}
+	p.sm.currentRun(ctx).checkpoint.addKeywords(ctx, candidates)
+	if p.sm.dryRun != nil {
+		p.sm.dryRun.recordCandidates(candidates)
+	}
+	if p.query {
+		p.stream.push(ctx, candidates)
+	}
+}
+
+// countSites registers the sitemap's host for each of its keywords and returns how many
+// distinct hosts list them so far; keywords queued before another site lists them keep
+// their lower count
+func (p *keywordPipeline) countSites(extract sitemapExtract) map[string]int {
+	host := extract.sitemapURL
+	if parsed, err := url.Parse(extract.sitemapURL); err == nil && parsed.Host != "" {
+		host = strings.TrimPrefix(parsed.Host, "www.")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make(map[string]int, len(extract.keywords))
+	for _, keyword := range extract.keywords {
+		normalized := p.sm.normalizeForDeduplication(p.sm.formatKeywordForAPI(keyword))
+		if p.hosts[normalized] == nil {
+			p.hosts[normalized] = make(map[string]bool)
+		}
+		p.hosts[normalized][host] = true
+		counts[normalized] = len(p.hosts[normalized])
+	}
+	return counts
+}
+
+func (p *keywordPipeline) countResumed(ctx context.Context, alreadyQueried int) {
+	if alreadyQueried == 0 {
+		return
+	}
+	p.sm.currentRun(ctx).stats.update(func(summary *RunSummary) { summary.ResumedQueried += alreadyQueried })
+}
@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func streamCandidates(n int) []KeywordCandidate {
+	candidates := make([]KeywordCandidate, n)
+	for i := range candidates {
+		candidates[i] = KeywordCandidate{Keyword: fmt.Sprintf("keyword %d", i), Score: float64(i)}
+	}
+	return candidates
+}
+
+func TestCandidateStreamBackpressure(t *testing.T) {
+	ctx := context.Background()
+	stream := newCandidateStream(4)
+	never := func() bool { return false }
+
+	pushed := make(chan struct{})
+	go func() {
+		stream.push(ctx, streamCandidates(6))
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("Push should wait while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if batch := stream.popBatch(ctx, 3, never); len(batch) != 3 || batch[0].Score != 3 {
+		t.Fatalf("Expected the three most valuable buffered candidates, got %+v", batch)
+	}
+	<-pushed
+	stream.close()
+
+	var rest int
+	for batch := stream.popBatch(ctx, 3, never); batch != nil; batch = stream.popBatch(ctx, 3, never) {
+		rest += len(batch)
+	}
+	if rest != 3 || stream.total() != 6 {
+		t.Errorf("Expected 3 more candidates out of 6, got %d of %d", rest, stream.total())
+	}
+}
+
+func TestCandidateStreamAbandonKeepsCandidates(t *testing.T) {
+	ctx := context.Background()
+	stream := newCandidateStream(2)
+	stream.abandon()
+	stream.push(ctx, streamCandidates(5)) // Must not wait without workers
+	stream.close()
+
+	if left := stream.drain(); len(left) != 5 || left[0].Score != 4 {
+		t.Errorf("Expected every candidate carried over, most valuable first, got %+v", left)
+	}
+}
+
+func TestCandidateStreamStops(t *testing.T) {
+	stream := newCandidateStream(10)
+	stream.push(context.Background(), streamCandidates(10))
+	if batch := stream.popBatch(context.Background(), 5, func() bool { return true }); batch != nil {
+		t.Errorf("Expected no batch once stopped, got %d candidates", len(batch))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []KeywordCandidate)
+	empty := newCandidateStream(10)
+	go func() { done <- empty.popBatch(ctx, 5, func() bool { return false }) }()
+	cancel()
+	select {
+	case batch := <-done:
+		if batch != nil {
+			t.Errorf("Expected no batch after cancellation, got %+v", batch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("popBatch kept waiting after its context ended")
+	}
+}
+
+func TestKeywordSet(t *testing.T) {
+	var set keywordSet
+	if !set.add("subway surfers") || set.add("subway surfers") || !set.add("temple run") {
+		t.Error("Expected only first additions to report new keywords")
+	}
+	if set.len() != 2 {
+		t.Errorf("Expected 2 keywords, got %d", set.len())
+	}
+}
@@ -0,0 +1,142 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/logger"
+	"sitemap-go/pkg/storage"
+)
+
+const querySitemap = "https://poki.com/sitemap.xml"
+
+// answeringAPIClient answers every keyword with data; onQuery, when set, runs before answering
+// with the 1-based number of the query
+type answeringAPIClient struct {
+	mu      sync.Mutex
+	queries int
+	onQuery func(n int)
+}
+
+func (c *answeringAPIClient) HandlesRateLimiting() bool { return true }
+
+func (c *answeringAPIClient) Query(ctx context.Context, keywords []string) (*api.APIResponse, error) {
+	c.mu.Lock()
+	c.queries++
+	n := c.queries
+	c.mu.Unlock()
+	if c.onQuery != nil {
+		c.onQuery(n)
+	}
+	response := &api.APIResponse{Status: "success"}
+	for _, keyword := range keywords {
+		response.Keywords = append(response.Keywords, api.Keyword{Word: keyword, SearchVolume: 1000})
+	}
+	return response, nil
+}
+
+// newQueryTestMonitor returns a monitor that queries with one API worker and submits to pool
+func newQueryTestMonitor(client api.APIClient, pool *backend.SubmissionPool) *SitemapMonitor {
+	config := DefaultConcurrencyConfig()
+	config.APIWorkers = 1
+	backing := storage.NewMemoryStorage()
+	sm := &SitemapMonitor{
+		apiClient:          client,
+		storage:            backing,
+		simpleTracker:      storage.NewSimpleTracker(backing),
+		submissionPool:     pool,
+		dataConverter:      backend.NewDataConverter(),
+		concurrencyManager: NewAdaptiveConcurrencyManager(config),
+		log:                logger.GetLogger(),
+		secureLog:          logger.GetSecurityLogger(),
+	}
+	sm.EnableCheckpoints(false, 0)
+	return sm
+}
+
+// queryCandidates returns n candidates for pages of querySitemap, most valuable first
+func queryCandidates(n int) []KeywordCandidate {
+	candidates := make([]KeywordCandidate, n)
+	for i := range candidates {
+		keyword := fmt.Sprintf("game %d", i)
+		candidates[i] = KeywordCandidate{Keyword: keyword, URL: "https://poki.com/g/" + keyword, SitemapURL: querySitemap, Score: float64(n - i)}
+	}
+	return candidates
+}
+
+func (rc *runCheckpoint) isQueried(keyword string) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.queried[keyword]
+}
+
+func TestQueriedOnlyOnceRecordsAreHandedOver(t *testing.T) {
+	recorder := backend.NewRecordingClient()
+	pool := backend.NewSubmissionPool(recorder, 1)
+	client := &answeringAPIClient{}
+	sm := newQueryTestMonitor(client, pool)
+	run := sm.beginRun(context.Background(), []string{querySitemap}, true)
+	ctx := withRun(context.Background(), run)
+
+	// The first batch's records wait for a full chunk, so it is not queried yet while the second runs
+	inFlight := make(chan bool, 1)
+	client.onQuery = func(n int) {
+		if n == 2 {
+			time.Sleep(50 * time.Millisecond) // Leave the collector time to take the first result
+			inFlight <- run.checkpoint.isQueried("game 0")
+		}
+	}
+	stream := newCandidateStream(20)
+	stream.push(ctx, queryCandidates(11))
+	stream.close()
+	if err := sm.queryAndSubmitKeywords(ctx, stream); err != nil {
+		t.Fatalf("queryAndSubmitKeywords failed: %v", err)
+	}
+	if <-inFlight {
+		t.Error("A batch counted as queried while its records were still buffered")
+	}
+
+	for _, candidate := range queryCandidates(11) {
+		if !run.checkpoint.isQueried(candidate.Keyword) {
+			t.Errorf("Expected %q queried once its records were submitted", candidate.Keyword)
+		}
+	}
+	if stats := pool.GetStats(); stats.QueueDepth != 1 {
+		t.Errorf("Expected the records queued as one submission, got %+v", stats)
+	}
+}
+
+func TestRecordsThePoolRefusesAreCarriedOver(t *testing.T) {
+	pool := backend.NewSubmissionPool(backend.NewRecordingClient(), 1)
+	pool.Stop()
+	sm := newQueryTestMonitor(&answeringAPIClient{}, pool)
+	run := sm.beginRun(context.Background(), []string{querySitemap}, true)
+	ctx := withRun(context.Background(), run)
+
+	stream := newCandidateStream(20)
+	stream.push(ctx, queryCandidates(11))
+	stream.close()
+	if err := sm.queryAndSubmitKeywords(ctx, stream); err != nil {
+		t.Fatalf("queryAndSubmitKeywords failed: %v", err)
+	}
+
+	for _, candidate := range queryCandidates(11) {
+		if processed, _ := sm.simpleTracker.IsURLProcessed(ctx, candidate.URL); processed {
+			t.Errorf("Expected %s not saved as processed when its record was refused", candidate.URL)
+		}
+		if run.checkpoint.isQueried(candidate.Keyword) {
+			t.Errorf("Expected %q not queried in the checkpoint when its record was refused", candidate.Keyword)
+		}
+	}
+	if pending := sm.simpleTracker.CountPendingKeywords(ctx); pending != 11 {
+		t.Errorf("Expected the 11 refused keywords carried over, got %d", pending)
+	}
+	if summary := sm.GetRunSummary(ctx); summary.QueriedKeywords != 0 || summary.UnqueriedKeywords != 11 || summary.FailedKeywords != 0 {
+		t.Errorf("Unexpected run summary: %+v", summary)
+	}
+}
@@ -0,0 +1,171 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/storage"
+)
+
+// quotaProvider identifies the keyword API in the quota ledger
+const quotaProvider = "seokey"
+
+// ledgerQuotaGuard adapts storage.QuotaLedger to api.QuotaGuard for one provider
+type ledgerQuotaGuard struct {
+	ledger   *storage.QuotaLedger
+	provider string
+	monitor  *SitemapMonitor
+}
+
+func (g *ledgerQuotaGuard) Available(ctx context.Context, endpoint string) bool {
+	return g.ledger.Available(ctx, g.provider, endpoint)
+}
+
+func (g *ledgerQuotaGuard) Reserve(ctx context.Context, endpoint string) error {
+	err := g.ledger.Reserve(ctx, g.provider, endpoint, 1)
+	if err == nil {
+		return nil
+	}
+
+	var exhausted *storage.ErrQuotaExhausted
+	if errors.As(err, &exhausted) {
+		return fmt.Errorf("%w: %w", api.ErrQuotaExhausted, exhausted)
+	}
+
+	// Ledger persistence problems must not stop the run; the request is still counted in memory
+	g.monitor.secureLog.SafeWarn("Quota ledger update failed, continuing", map[string]interface{}{
+		"error": err.Error(),
+	})
+	return nil
+}
+
+// configureQuota enables persisted quota accounting for the API client
+// Per-endpoint limits override the defaults; zero limits only record spend
+func (sm *SitemapMonitor) configureQuota(ctx context.Context, endpoints []api.EndpointConfig, defaults storage.QuotaLimits) error {
+	sm.quotaLedger = storage.NewQuotaLedger(sm.quotaStorage())
+	guard := &ledgerQuotaGuard{ledger: sm.quotaLedger, provider: quotaProvider, monitor: sm}
+
+	for _, endpoint := range endpoints {
+		limits := defaults
+		if endpoint.DailyQuota > 0 {
+			limits.Daily = endpoint.DailyQuota
+		}
+		if endpoint.MonthlyQuota > 0 {
+			limits.Monthly = endpoint.MonthlyQuota
+		}
+		if err := sm.quotaLedger.SetLimits(ctx, quotaProvider, endpoint.URL, limits); err != nil {
+			return fmt.Errorf("failed to configure quota for endpoint: %w", err)
+		}
+	}
+
+	if weighted, ok := sm.apiClient.(*api.WeightedAPIClient); ok {
+		weighted.SetQuotaGuard(guard)
+	} else if len(endpoints) > 0 {
+		sm.apiClient = api.NewQuotaLimitedClient(sm.apiClient, guard, endpoints[0].URL)
+	}
+	sm.retryProcessor.apiClient = sm.apiClient
+	return nil
+}
+
+// flushQuota persists API spend the ledger still holds in memory
+func (sm *SitemapMonitor) flushQuota(ctx context.Context) {
+	if sm.quotaLedger == nil {
+		return
+	}
+	if err := sm.quotaLedger.Flush(ctx); err != nil {
+		sm.secureLog.SafeWarn("Failed to persist quota ledger", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// deferKeywords queues keywords that were not queried because the budget ran out
+func (sm *SitemapMonitor) deferKeywords(ctx context.Context, keywords []string, keywordToSpecificURLMap, keywordToSitemapMap map[string]string, notBefore time.Time) {
+	sm.savePendingKeywords(ctx, keywords, keywordToSpecificURLMap, keywordToSitemapMap, storage.PendingReasonQuota, notBefore)
+}
+
+// carryOverKeywords queues keywords the run did not reach so the next run queries them
+func (sm *SitemapMonitor) carryOverKeywords(ctx context.Context, keywords []string, keywordToSpecificURLMap, keywordToSitemapMap map[string]string) {
+	sm.savePendingKeywords(ctx, keywords, keywordToSpecificURLMap, keywordToSitemapMap, storage.PendingReasonUnqueried, time.Time{})
+	sm.log.WithField("unqueried_keywords", len(keywords)).Warn("Run ended before all keywords were queried, carried over to next run")
+}
+
+func (sm *SitemapMonitor) savePendingKeywords(ctx context.Context, keywords []string, keywordToSpecificURLMap, keywordToSitemapMap map[string]string, reason string, notBefore time.Time) {
+	if len(keywords) == 0 {
+		return
+	}
+	now := time.Now()
+	records := make([]storage.PendingKeywordRecord, 0, len(keywords))
+	for _, keyword := range keywords {
+		records = append(records, storage.PendingKeywordRecord{
+			Keyword:    keyword,
+			SourceURL:  keywordToSpecificURLMap[keyword],
+			SitemapURL: keywordToSitemapMap[keyword],
+			Reason:     reason,
+			QueuedAt:   now,
+			NotBefore:  notBefore,
+		})
+	}
+	if err := sm.simpleTracker.SavePendingKeywords(ctx, records); err != nil {
+		sm.secureLog.SafeError("Failed to save pending keywords", err, map[string]interface{}{
+			"reason": reason,
+		})
+	}
+}
+
+// quotaResetTime returns the reset time carried by a quota error, or the next daily window
+func (sm *SitemapMonitor) quotaResetTime(err error) time.Time {
+	var exhausted *storage.ErrQuotaExhausted
+	if errors.As(err, &exhausted) {
+		return exhausted.ResetAt
+	}
+	if sm.quotaLedger != nil {
+		return sm.quotaLedger.NextWindow("daily")
+	}
+	return time.Now().Add(24 * time.Hour)
+}
+
+// mergePendingKeywords adds keywords deferred by earlier runs to this run's query set
+// Returns the merged list and the set of keywords that were carried over
+func (sm *SitemapMonitor) mergePendingKeywords(ctx context.Context, keywords []string, keywordToSpecificURLMap, keywordToSitemapMap map[string]string) ([]string, map[string]bool) {
+	carriedOver := make(map[string]bool)
+	pending, err := sm.simpleTracker.TakeReadyPendingKeywords(ctx)
+	if err != nil {
+		sm.secureLog.SafeError("Failed to load pending keywords", err, nil)
+		return keywords, carriedOver
+	}
+	if len(pending) == 0 {
+		return keywords, carriedOver
+	}
+
+	seen := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		seen[keyword] = true
+	}
+	resumed := 0
+	for _, record := range pending {
+		if seen[record.Keyword] {
+			continue // Rediscovered from the sitemap this run
+		}
+		seen[record.Keyword] = true
+		carriedOver[record.Keyword] = true
+		keywords = append(keywords, record.Keyword)
+		if keywordToSpecificURLMap[record.Keyword] == "" {
+			keywordToSpecificURLMap[record.Keyword] = record.SourceURL
+		}
+		if keywordToSitemapMap[record.Keyword] == "" {
+			keywordToSitemapMap[record.Keyword] = record.SitemapURL
+		}
+		resumed++
+	}
+
+	sm.log.WithFields(map[string]interface{}{
+		"pending_ready":    len(pending),
+		"resumed_keywords": resumed,
+		"total_to_query":   len(keywords),
+	}).Info("Resumed keywords deferred by earlier runs")
+	return keywords, carriedOver
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/storage"
+)
+
+// normalizeResponseKeyword folds case and whitespace so "Subway  Surfers " matches "subway surfers"
+func normalizeResponseKeyword(word string) string {
+	return strings.ToLower(strings.Join(strings.Fields(word), " "))
+}
+
+// reconcileBatch matches an API response against the query keys that were requested
+// Returned keywords that match a request get the requested spelling so they map back to their URL;
+// found and missing partition the batch, and an empty or nil response leaves every key missing.
+// A batch targets one locale, so the plain word identifies a key within it
+func reconcileBatch(batch []string, response *api.APIResponse) (keywords []api.Keyword, found, missing []string) {
+	requested := make(map[string]string, len(batch))
+	for _, key := range batch {
+		word, _ := api.SplitLocaleKey(key)
+		requested[normalizeResponseKeyword(word)] = word
+	}
+
+	answered := make(map[string]bool, len(batch))
+	if response != nil {
+		keywords = make([]api.Keyword, 0, len(response.Keywords))
+		for _, keyword := range response.Keywords {
+			normalized := normalizeResponseKeyword(keyword.Word)
+			if word, exists := requested[normalized]; exists {
+				keyword.Word = word
+				answered[normalized] = true
+			}
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	for _, key := range batch {
+		word, _ := api.SplitLocaleKey(key)
+		if answered[normalizeResponseKeyword(word)] {
+			found = append(found, key)
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return keywords, found, missing
+}
+
+// recordNoDataKeywords stores keywords the API had no data for under their own retry policy
+// Returns keywords that used up their attempts; their URLs should be marked processed
+func (sm *SitemapMonitor) recordNoDataKeywords(ctx context.Context, keywords []string, keywordToSpecificURLMap, keywordToSitemapMap map[string]string) []string {
+	records := make([]storage.FailedKeywordRecord, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keywordToSpecificURLMap[keyword] == "" {
+			continue
+		}
+		records = append(records, storage.FailedKeywordRecord{
+			Keyword:    keyword,
+			SourceURL:  keywordToSpecificURLMap[keyword],
+			SitemapURL: keywordToSitemapMap[keyword],
+		})
+	}
+
+	exhausted, err := sm.simpleTracker.SaveNoDataKeywords(ctx, records)
+	if err != nil {
+		sm.secureLog.SafeError("Failed to save keywords without API data", err, nil)
+		return nil
+	}
+	givenUp := make([]string, 0, len(exhausted))
+	for _, record := range exhausted {
+		givenUp = append(givenUp, record.Keyword)
+	}
+	if len(givenUp) > 0 {
+		sm.log.WithField("keywords", len(givenUp)).Info("Giving up on keywords the API never had data for")
+	}
+	return givenUp
+}
+
+// skipNoDataBackoff drops keywords whose no-data retry is not due yet
+func (sm *SitemapMonitor) skipNoDataBackoff(ctx context.Context, keywords []string) []string {
+	backoff := sm.simpleTracker.NoDataBackoff(ctx)
+	if len(backoff) == 0 {
+		return keywords
+	}
+
+	filtered := make([]string, 0, len(keywords))
+	var nextDue time.Time
+	for _, keyword := range keywords {
+		if due, waiting := backoff[keyword]; waiting {
+			if nextDue.IsZero() || due.Before(nextDue) {
+				nextDue = due
+			}
+			continue
+		}
+		filtered = append(filtered, keyword)
+	}
+	if skipped := len(keywords) - len(filtered); skipped > 0 {
+		sm.log.WithFields(map[string]interface{}{
+			"skipped_keywords": skipped,
+			"next_retry_at":    nextDue.Format(time.RFC3339),
+		}).Info("Skipping keywords waiting out their no-data retry delay")
+	}
+	return filtered
+}
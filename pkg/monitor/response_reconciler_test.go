@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+
+	"sitemap-go/pkg/api"
+)
+
+func TestReconcileBatchPartialResponse(t *testing.T) {
+	batch := []string{"subway surfers@de", "moto x3m@de", "rare game@de"}
+	response := &api.APIResponse{Keywords: []api.Keyword{
+		{Word: " Subway  Surfers", SearchVolume: 5000, Locale: "de"},
+		{Word: "MOTO X3M", SearchVolume: 800, Locale: "de"},
+	}}
+
+	keywords, found, missing := reconcileBatch(batch, response)
+	if !reflect.DeepEqual(found, []string{"subway surfers@de", "moto x3m@de"}) {
+		t.Errorf("Unexpected found keys: %v", found)
+	}
+	if !reflect.DeepEqual(missing, []string{"rare game@de"}) {
+		t.Errorf("Unexpected missing keys: %v", missing)
+	}
+	if keywords[0].LocaleKey() != "subway surfers@de" || keywords[1].LocaleKey() != "moto x3m@de" {
+		t.Errorf("Expected answered keywords to take the requested spelling, got %+v", keywords)
+	}
+
+	_, found, missing = reconcileBatch(batch, nil)
+	if len(found) != 0 || len(missing) != len(batch) {
+		t.Errorf("Expected every key missing for a nil response, got found=%v missing=%v", found, missing)
+	}
+}
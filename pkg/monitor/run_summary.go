@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+
+	"sitemap-go/pkg/parser"
+	"sitemap-go/pkg/storage"
+)
+
+// RunSummary collects per-run counters that are not part of individual MonitorResults
+type RunSummary struct {
+	RunID             string               `json:"run_id"`
+	Resumed           bool                 `json:"resumed,omitempty"`          // Continued an interrupted run's checkpoint
+	ResumedSitemaps   int                  `json:"resumed_sitemaps,omitempty"` // Sitemaps taken from the checkpoint instead of parsed
+	ResumedQueried    int                  `json:"resumed_queried,omitempty"`  // Keywords skipped as queried before the interruption
+	QueriedKeywords   int                  `json:"queried_keywords"`
+	FailedKeywords    int                  `json:"failed_keywords"`
+	DeferredKeywords  int                  `json:"deferred_keywords"`  // Queued for the next quota window
+	UnqueriedKeywords int                  `json:"unqueried_keywords"` // Not reached before the run ended, carried over
+	NoDataKeywords    int                  `json:"no_data_keywords"`   // Queried, but missing from the API response
+	BackendAccepted   int64                `json:"backend_accepted"`   // Records the backend stored
+	BackendRejected   int64                `json:"backend_rejected"`   // Records rejected and dead-lettered
+	BackendDuplicates int64                `json:"backend_duplicates"` // Records the backend already had
+	SubmitWaitMs      int64                `json:"submit_wait_ms"`     // Time the run blocked on a full submission queue
+	SpilledTasks      int64                `json:"spilled_tasks"`      // Submissions that overflowed to the outbox
+	PeakQueueDepth    int                  `json:"peak_queue_depth"`   // Deepest the submission queue has been
+	QueuedSubmissions int                  `json:"queued_submissions"` // Submissions not sent yet; they are kept in the outbox
+	ChangeEvents      int                  `json:"change_events"`      // Sitemap changes queued for the backend
+	ConfirmedRemovals int                  `json:"confirmed_removals"` // Pages delisted after the removal grace period
+	ReappearedURLs    int                  `json:"reappeared_urls"`    // Delisted pages listed again
+	PendingRemovals   int                  `json:"pending_removals"`   // Missing pages still within the grace period
+	QuotaExhausted    bool                 `json:"quota_exhausted"`
+	Interrupted       bool                 `json:"interrupted,omitempty"` // Stopped by Interrupt or its context before finishing
+	QuotaUsage        []storage.QuotaUsage `json:"quota_usage,omitempty"`
+	HostUsage         []parser.HostStats   `json:"host_usage,omitempty"` // Sitemap fetches per host and how long politeness limits held them
+}
+
+// runStats is the mutable counterpart of RunSummary owned by the run
+type runStats struct {
+	mu      sync.Mutex
+	summary RunSummary
+}
+
+func (rs *runStats) update(fn func(*RunSummary)) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	fn(&rs.summary)
+}
+
+func (rs *runStats) snapshot() RunSummary {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.summary
+}
+
+// GetRunSummary returns counters for the run ctx belongs to, else the last ProcessSitemaps call,
+// including quota spend
+// Backend counts cover submissions finished so far; call WaitForSubmissions first for final numbers.
+// They come from the shared submission pool, so runs that overlap count each other's submissions
+func (sm *SitemapMonitor) GetRunSummary(ctx context.Context) RunSummary {
+	run := sm.currentRun(ctx)
+	summary := run.stats.snapshot()
+	stats := sm.submissionPool.GetStats()
+	summary.BackendAccepted = stats.AcceptedItems - run.submissionBaseline.AcceptedItems
+	summary.BackendRejected = stats.RejectedItems - run.submissionBaseline.RejectedItems
+	summary.BackendDuplicates = stats.DuplicateItems - run.submissionBaseline.DuplicateItems
+	summary.SubmitWaitMs = stats.SubmitWaitMs - run.submissionBaseline.SubmitWaitMs
+	summary.SpilledTasks = stats.SpilledTasks - run.submissionBaseline.SpilledTasks
+	summary.PeakQueueDepth = stats.PeakQueueDepth
+	summary.QueuedSubmissions = stats.QueueDepth
+	summary.HostUsage = run.hostUsage()
+	if sm.quotaLedger != nil {
+		if usage, err := sm.quotaLedger.Snapshot(ctx); err == nil {
+			summary.QuotaUsage = usage
+		}
+	}
+	return summary
+}
+
+// hostUsage returns the host limiter counters since the run started, for hosts requested in it
+func (run *monitorRun) hostUsage() []parser.HostStats {
+	var usage []parser.HostStats
+	for _, stats := range parser.DefaultHostLimiter().Stats() {
+		base := run.hostBaseline[stats.Host]
+		stats.Requests -= base.Requests
+		stats.Throttled -= base.Throttled
+		stats.WaitMs -= base.WaitMs
+		if stats.Requests > 0 {
+			usage = append(usage, stats)
+		}
+	}
+	return usage
+}
+
+// WaitForSubmissions blocks until queued backend submissions finish or ctx ends
+// It reports whether the queue drained
+func (sm *SitemapMonitor) WaitForSubmissions(ctx context.Context) bool {
+	// Failed streamed requests are resent through the pool, so drain the stream first
+	if sm.streamSubmitter != nil && !sm.streamSubmitter.Wait(ctx) {
+		return false
+	}
+	return sm.submissionPool.WaitIdle(ctx)
+}
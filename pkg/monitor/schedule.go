@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a site is monitored next
+type Schedule interface {
+	// Next returns the first run time strictly after the given time
+	Next(after time.Time) time.Time
+}
+
+// minScheduleInterval keeps an interval schedule from hammering sites and the API
+const minScheduleInterval = time.Minute
+
+// ParseSchedule parses an interval or a cron expression:
+//
+//	@every 6h, 90m, @hourly, @daily, @weekly, @monthly, 0 */4 * * *, 30 2 * * 1-5
+//
+// Cron expressions have five fields (minute hour day-of-month month day-of-week) and are
+// evaluated in UTC, like API quota windows
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	}
+
+	if every, ok := strings.CutPrefix(spec, "@every "); ok || !strings.Contains(spec, " ") {
+		if !ok {
+			every = spec
+		}
+		interval, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		if interval < minScheduleInterval {
+			return nil, fmt.Errorf("invalid schedule %q: interval must be at least %s", spec, minScheduleInterval)
+		}
+		return intervalSchedule(interval), nil
+	}
+	return parseCron(spec)
+}
+
+// intervalSchedule runs a fixed time after the previous run
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// cronSchedule holds one bit per allowed value of each field
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // An unrestricted field does not take part in day matching
+}
+
+func parseCron(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: cron expressions need 5 fields, got %d", spec, len(fields))
+	}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var bits [5]uint64
+	for i, field := range fields {
+		parsed, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		bits[i] = parsed
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1 // 7 is Sunday, like 0
+	}
+	schedule := &cronSchedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+	// Fields can be valid on their own and still name a day that never exists, like 30 2 or 31 4
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid schedule %q: never matches a real date", spec)
+	}
+	return schedule, nil
+}
+
+// parseCronField parses a comma-separated list of *, values, ranges and /steps
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("bad step in %q", part)
+			}
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("bad value in %q", part)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("bad range in %q", part)
+				}
+			} else if hasStep {
+				high = max // 5/15 means every 15 starting at 5
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	// Every valid expression matches within a few years (Feb 29 on a given weekday takes longest);
+	// the zero time means it never does
+	limit := t.AddDate(8, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day fields are restricted, either one matching is enough
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// ScheduleRule assigns a schedule to a site; Host "*" is the default for every other site
+type ScheduleRule struct {
+	Host     string   `json:"host"` // Host without "www."
+	Spec     string   `json:"spec"`
+	Schedule Schedule `json:"-"`
+}
+
+// ParseScheduleRules parses a semicolon-separated rule list (cron fields may contain commas):
+//
+//	poki.com=@every 2h;y8.com=0 */4 * * *;*=@daily
+func ParseScheduleRules(raw string) ([]ScheduleRule, error) {
+	var rules []ScheduleRule
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		target, spec, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid schedule rule %q (expected site=schedule)", entry)
+		}
+		schedule, err := ParseSchedule(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule rule %q: %w", entry, err)
+		}
+		target = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(target), "https://"), "http://")
+		host, _, _ := strings.Cut(target, "/")
+		host = strings.TrimPrefix(strings.ToLower(host), "www.")
+		if host == "" {
+			return nil, fmt.Errorf("invalid schedule rule %q: missing site", entry)
+		}
+		rules = append(rules, ScheduleRule{Host: host, Spec: strings.TrimSpace(spec), Schedule: schedule})
+	}
+	return rules, nil
+}
+
+// scheduleFor returns the rule for a sitemap: the most specific host match, then the wildcard
+func scheduleFor(rules []ScheduleRule, sitemapURL string) (ScheduleRule, bool) {
+	parsed, err := url.Parse(sitemapURL)
+	if err != nil {
+		return ScheduleRule{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	var best ScheduleRule
+	found := false
+	for _, rule := range rules {
+		if !hostMatches(rule.Host, host) {
+			continue
+		}
+		if !found || best.Host == "*" || (rule.Host != "*" && len(rule.Host) > len(best.Host)) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/storage"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Friday 2026-01-30 10:17 UTC
+	from := time.Date(2026, 1, 30, 10, 17, 30, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@every 90m", from.Add(90 * time.Minute)},
+		{"6h", from.Add(6 * time.Hour)},
+		{"@hourly", time.Date(2026, 1, 30, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 30, 10, 30, 0, 0, time.UTC)},
+		{"0 */4 * * *", time.Date(2026, 1, 30, 12, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2026, 2, 2, 2, 30, 0, 0, time.UTC)}, // Skips the weekend
+		{"0 9 * * 7", time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)},     // 7 is Sunday
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},   // Next leap day
+		{"0 0 1 * 5", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},     // Either day field matches
+		{"5,40 10 30 1 *", time.Date(2026, 1, 30, 10, 40, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.spec)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) failed: %v", c.spec, err)
+			continue
+		}
+		if got := schedule.Next(from); !got.Equal(c.want) {
+			t.Errorf("%q: Next = %s, want %s", c.spec, got, c.want)
+		}
+	}
+
+	for _, spec := range []string{"", "10s", "@every soon", "* * * *", "60 * * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *",
+		"0 0 30 2 *", "0 0 31 4 *", "0 0 31 2,4,6,9,11 *"} { // Days that never exist
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected ParseSchedule(%q) to fail", spec)
+		}
+	}
+}
+
+func TestScheduleRulesPickMostSpecificSite(t *testing.T) {
+	rules, err := ParseScheduleRules("*=@daily; poki.com=@every 2h; games.poki.com=0,30 * * * *")
+	if err != nil {
+		t.Fatalf("ParseScheduleRules failed: %v", err)
+	}
+
+	cases := map[string]string{
+		"https://poki.com/sitemap.xml":           "@every 2h",
+		"https://www.poki.com/sitemap.xml":       "@every 2h",
+		"https://games.poki.com/en/sitemap.xml":  "0,30 * * * *",
+		"https://www.crazygames.com/sitemap.xml": "@daily",
+	}
+	for sitemapURL, want := range cases {
+		rule, ok := scheduleFor(rules, sitemapURL)
+		if !ok || rule.Spec != want {
+			t.Errorf("scheduleFor(%s) = %q, want %q", sitemapURL, rule.Spec, want)
+		}
+	}
+
+	if _, err := ParseScheduleRules("poki.com"); err == nil {
+		t.Error("Expected error for rule without schedule")
+	}
+	if _, ok := scheduleFor(rules[1:], "https://y8.com/sitemap.xml"); ok {
+		t.Error("Expected no schedule without a matching rule or default")
+	}
+}
+
+func TestDueSitesSkipsSitesThatNeverRun(t *testing.T) {
+	now := time.Date(2026, 1, 30, 10, 0, 0, 0, time.UTC)
+	d := &Daemon{sites: []*daemonSite{
+		{state: storage.ScheduleState{Site: "never"}},
+		{state: storage.ScheduleState{Site: "due", NextRun: now}},
+		{state: storage.ScheduleState{Site: "later", NextRun: now.Add(time.Hour)}},
+	}}
+
+	due, next := d.dueSites(now)
+	if len(due) != 1 || due[0].state.Site != "due" || !next.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Expected only the due site and the later run, got %d sites, next %s", len(due), next)
+	}
+
+	d.sites = d.sites[:1]
+	if due, next := d.dueSites(now); len(due) != 0 || !next.IsZero() {
+		t.Errorf("A site without a next run must never be due, got %d sites, next %s", len(due), next)
+	}
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Interrupt stops the run from taking new work: sitemap parsing is cancelled and API workers
+// finish the batches they hold without starting new ones; keywords left over are carried over
+// to the next run. In-flight requests keep running until the run's context ends, so the caller
+// decides how long they get. Interrupt applies to every running run and to runs started after it
+func (sm *SitemapMonitor) Interrupt() {
+	if !atomic.CompareAndSwapInt32(&sm.interrupted, 0, 1) {
+		return
+	}
+	sm.log.Warn("Run interrupted: stopping new work, in-flight API batches may finish")
+	sm.upstreamMu.Lock()
+	defer sm.upstreamMu.Unlock()
+	for _, cancel := range sm.cancelUpstream {
+		cancel()
+	}
+}
+
+// isInterrupted reports whether Interrupt was called
+func (sm *SitemapMonitor) isInterrupted() bool {
+	return atomic.LoadInt32(&sm.interrupted) == 1
+}
+
+// upstreamContext derives the context of the run's stages Interrupt cancels (sitemap parsing and
+// extraction); the returned function cancels it once the run is over
+func (sm *SitemapMonitor) upstreamContext(ctx context.Context, run *monitorRun) (context.Context, context.CancelFunc) {
+	upstreamCtx, cancel := context.WithCancel(ctx)
+	sm.upstreamMu.Lock()
+	defer sm.upstreamMu.Unlock()
+	if sm.cancelUpstream == nil {
+		sm.cancelUpstream = make(map[*monitorRun]context.CancelFunc)
+	}
+	sm.cancelUpstream[run] = cancel
+	if sm.isInterrupted() {
+		cancel()
+	}
+	return upstreamCtx, func() {
+		cancel()
+		sm.upstreamMu.Lock()
+		defer sm.upstreamMu.Unlock()
+		delete(sm.cancelUpstream, run)
+	}
+}
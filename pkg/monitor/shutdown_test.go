@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"sitemap-go/pkg/backend"
+)
+
+func TestInterruptFinishesInFlightBatchAndCarriesOverTheRest(t *testing.T) {
+	pool := backend.NewSubmissionPool(backend.NewRecordingClient(), 1)
+	client := &answeringAPIClient{}
+	sm := newQueryTestMonitor(client, pool)
+	run := sm.beginRun(context.Background(), []string{querySitemap}, true)
+	ctx := withRun(context.Background(), run)
+	upstreamCtx, done := sm.upstreamContext(ctx, run)
+	defer done()
+
+	// The signal arrives while the first batch is with the API
+	client.onQuery = func(n int) {
+		if n == 1 {
+			sm.Interrupt()
+		}
+	}
+	stream := newCandidateStream(50)
+	stream.push(ctx, queryCandidates(25))
+	stream.close()
+	if err := sm.queryAndSubmitKeywords(ctx, stream); err != nil {
+		t.Fatalf("queryAndSubmitKeywords failed: %v", err)
+	}
+
+	if upstreamCtx.Err() == nil {
+		t.Error("Expected Interrupt to cancel the upstream stages")
+	}
+	if client.queries != 1 {
+		t.Errorf("Expected no batch started after Interrupt, got %d queries", client.queries)
+	}
+	if stats := pool.GetStats(); stats.QueueDepth != 1 {
+		t.Errorf("Expected the in-flight batch's records submitted, got %+v", stats)
+	}
+	if !run.checkpoint.isQueried("game 0") || run.checkpoint.isQueried("game 10") {
+		t.Error("Expected only the in-flight batch queried in the checkpoint")
+	}
+	if pending := sm.simpleTracker.CountPendingKeywords(ctx); pending != 15 {
+		t.Errorf("Expected the 15 leftover keywords carried over, got %d", pending)
+	}
+	if summary := sm.GetRunSummary(ctx); summary.QueriedKeywords != 10 || summary.UnqueriedKeywords != 15 || summary.FailedKeywords != 0 {
+		t.Errorf("Unexpected run summary: %+v", summary)
+	}
+}
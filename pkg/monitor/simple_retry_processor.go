@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"strings"
+	"sync"
 	"time"
 
 	"sitemap-go/pkg/api"
@@ -19,6 +20,8 @@ type SimpleRetryProcessor struct {
 	dataConverter   *backend.DataConverter
 	log             *logger.Logger
 	secureLog       *logger.SecurityLogger
+	mu              sync.Mutex
+	done            chan struct{} // Closed when the latest retry finishes; nil before the first
 }
 
 // NewSimpleRetryProcessor creates a new simple retry processor
@@ -39,16 +42,34 @@ func NewSimpleRetryProcessor(
 }
 
 // ProcessFailedKeywordsAtStartup processes failed keywords once at startup in a separate goroutine
+// While a retry from an earlier run is still going no other starts, so overlapping runs never
+// query the same keywords twice; Wait blocks until it finishes
 func (srp *SimpleRetryProcessor) ProcessFailedKeywordsAtStartup(ctx context.Context) {
+	srp.mu.Lock()
+	if srp.done != nil {
+		select {
+		case <-srp.done:
+		default:
+			srp.mu.Unlock()
+			srp.log.Debug("Failed keyword retry still running, not starting another")
+			return
+		}
+	}
+	done := make(chan struct{})
+	srp.done = done
+	srp.mu.Unlock()
+
 	// Check if there are any failed keywords to process
 	failedKeywordRecords, err := srp.simpleTracker.GetRetryableKeywords(ctx)
 	if err != nil {
 		srp.log.WithError(err).Error("Failed to get retryable keywords")
+		close(done)
 		return
 	}
 
 	if len(failedKeywordRecords) == 0 {
 		srp.log.Debug("No failed keywords to retry at startup")
+		close(done)
 		return
 	}
 
@@ -56,6 +77,7 @@ func (srp *SimpleRetryProcessor) ProcessFailedKeywordsAtStartup(ctx context.Cont
 
 	// Launch retry processing in separate goroutine (non-blocking)
 	go func() {
+		defer close(done)
 		defer func() {
 			if r := recover(); r != nil {
 				srp.log.WithField("panic", r).Error("Panic recovered in retry goroutine")
@@ -66,6 +88,22 @@ func (srp *SimpleRetryProcessor) ProcessFailedKeywordsAtStartup(ctx context.Cont
 	}()
 }
 
+// Wait blocks until the running retry finishes or ctx ends; it reports whether none is left running
+func (srp *SimpleRetryProcessor) Wait(ctx context.Context) bool {
+	srp.mu.Lock()
+	done := srp.done
+	srp.mu.Unlock()
+	if done == nil {
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // processFailedKeywords processes the failed keywords with deduplication
 func (srp *SimpleRetryProcessor) processFailedKeywords(ctx context.Context, failedKeywordRecords []storage.FailedKeywordRecord) {
 	startTime := time.Now()
@@ -73,8 +111,14 @@ func (srp *SimpleRetryProcessor) processFailedKeywords(ctx context.Context, fail
 
 	// Step 1: Extract keywords and remove duplicates
 	keywords := make([]string, 0, len(failedKeywordRecords))
+	sourceURLs := make(map[string]string)
+	runIDs := make(map[string]string) // Resubmit under the original run so idempotency keys match
 	for _, record := range failedKeywordRecords {
 		keywords = append(keywords, record.Keyword)
+		if record.SourceURL != "" {
+			sourceURLs[record.Keyword] = record.SourceURL
+		}
+		runIDs[record.Keyword] = record.RunID
 	}
 	
 	uniqueKeywords := srp.deduplicateKeywords(keywords)
@@ -88,20 +132,28 @@ func (srp *SimpleRetryProcessor) processFailedKeywords(ctx context.Context, fail
 		"unique_count":   len(uniqueKeywords),
 	}).Info("Deduplicated failed keywords")
 
-	// Step 2: Process keywords in batches of 8 (Google Trends API limit)
+	// Step 2: Process keywords in batches of 8 (Google Trends API limit), one locale per batch
 	batchSize := 8
-	totalBatches := (len(uniqueKeywords) + batchSize - 1) / batchSize
+	var batches [][]string
+	var batchLocales []api.Locale
+	locales, groups := api.GroupByLocale(uniqueKeywords)
+	for _, locale := range locales {
+		group := groups[locale]
+		for i := 0; i < len(group); i += batchSize {
+			end := i + batchSize
+			if end > len(group) {
+				end = len(group)
+			}
+			batches = append(batches, group[i:end])
+			batchLocales = append(batchLocales, locale)
+		}
+	}
+	totalBatches := len(batches)
 	successCount := 0
 	failedCount := 0
 
-	for i := 0; i < len(uniqueKeywords); i += batchSize {
-		end := i + batchSize
-		if end > len(uniqueKeywords) {
-			end = len(uniqueKeywords)
-		}
-		
-		batch := uniqueKeywords[i:end]
-		batchNum := (i / batchSize) + 1
+	for i, batch := range batches {
+		batchNum := i + 1
 		
 		srp.log.WithFields(map[string]interface{}{
 			"batch_num":   batchNum,
@@ -110,7 +162,7 @@ func (srp *SimpleRetryProcessor) processFailedKeywords(ctx context.Context, fail
 		}).Debug("Processing failed keyword batch")
 
 		// Query Google Trends API
-		response, err := srp.apiClient.Query(ctx, batch)
+		response, err := srp.apiClient.Query(api.WithLocale(ctx, batchLocales[i]), batch)
 		if err != nil {
 			srp.log.WithError(err).WithField("batch", batchNum).Error("Failed to query batch")
 			failedCount += len(batch)
@@ -126,6 +178,7 @@ func (srp *SimpleRetryProcessor) processFailedKeywords(ctx context.Context, fail
 				TrendData:  response,
 				Success:    true,
 				Timestamp:  time.Now(),
+				Metadata:   map[string]interface{}{"keyword_url_mapping": sourceURLs},
 			}
 			
 			backendData, err := srp.dataConverter.ConvertMonitorResults([]*storage.MonitorResult{monitorResult})
@@ -134,6 +187,10 @@ func (srp *SimpleRetryProcessor) processFailedKeywords(ctx context.Context, fail
 				failedCount += len(batch)
 				continue
 			}
+			for j := range backendData {
+				key := api.Keyword{Word: backendData[j].Keyword, Locale: backendData[j].Locale}.LocaleKey()
+				backendData[j].RunID = runIDs[key]
+			}
 			
 			// Submit to backend via submission pool (non-blocking)
 			submitted := srp.submissionPool.Submit(backendData, func(submitErr error) {
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/backend"
+	"sitemap-go/pkg/storage"
+)
+
+// blockingAPIClient counts queries and holds each one until release is closed
+type blockingAPIClient struct {
+	queries int32
+	release chan struct{}
+}
+
+func (c *blockingAPIClient) Query(ctx context.Context, keywords []string) (*api.APIResponse, error) {
+	atomic.AddInt32(&c.queries, 1)
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &api.APIResponse{}, nil
+}
+
+func TestRetryProcessorDoesNotOverlap(t *testing.T) {
+	ctx := context.Background()
+	backing := storage.NewMemoryStorage()
+	failed := []storage.FailedKeywordRecord{{Keyword: "subway surfers", NextRetryAt: time.Now().Add(-time.Minute)}}
+	if err := backing.Save(ctx, "failed_keywords", failed); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	client := &blockingAPIClient{release: make(chan struct{})}
+	processor := NewSimpleRetryProcessor(client, storage.NewSimpleTracker(backing),
+		backend.NewSubmissionPool(backend.NewRecordingClient(), 1), backend.NewDataConverter())
+
+	if !processor.Wait(ctx) {
+		t.Fatal("Wait must not block before any retry started")
+	}
+	processor.ProcessFailedKeywordsAtStartup(ctx)
+	for atomic.LoadInt32(&client.queries) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A second run starting meanwhile leaves the keywords to the running retry
+	processor.ProcessFailedKeywordsAtStartup(ctx)
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if processor.Wait(waitCtx) {
+		t.Fatal("Wait returned while the retry was still querying")
+	}
+
+	close(client.release)
+	if !processor.Wait(ctx) {
+		t.Fatal("Wait did not return once the retry finished")
+	}
+	if queries := atomic.LoadInt32(&client.queries); queries != 1 {
+		t.Errorf("Expected the failed keyword queried once, got %d queries", queries)
+	}
+}
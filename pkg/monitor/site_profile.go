@@ -0,0 +1,274 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"sitemap-go/pkg/api"
+	"sitemap-go/pkg/extractor"
+	"sitemap-go/pkg/parser"
+)
+
+// Extraction profiles a site can choose
+const (
+	ExtractionDefault      = "default"       // Path and query words of the URL
+	ExtractionPathAnalysis = "path-analysis" // Adds words from semantic path analysis
+)
+
+// SiteConfig is one monitored site and its overrides of the global pipeline settings
+type SiteConfig struct {
+	Name       string        `json:"name"`
+	Sitemaps   []string      `json:"sitemaps"`
+	Filters    SiteFilters   `json:"filters"`
+	Extraction string        `json:"extraction,omitempty"` // ExtractionDefault or ExtractionPathAnalysis
+	Locale     string        `json:"locale,omitempty"`     // Site locale rule, e.g. de-DE; hreflang still wins
+	RateLimit  SiteRateLimit `json:"rate_limit"`
+	Schedule   string        `json:"schedule,omitempty"` // Daemon schedule of the site's sitemaps
+	Tags       []string      `json:"tags,omitempty"`     // Copied into the metadata of the site's results
+}
+
+// SiteFilters narrow the pages of a site that keywords are extracted from
+type SiteFilters struct {
+	IncludePaths    []string `json:"include_paths,omitempty"`    // Keep only pages whose path contains one of these
+	ExcludePaths    []string `json:"exclude_paths,omitempty"`    // Drop pages whose path contains one of these
+	ExcludeKeywords []string `json:"exclude_keywords,omitempty"` // Words never used as keywords
+}
+
+// SiteRateLimit bounds how hard a site is crawled
+// The politeness fields apply per host to every fetch from the site's sitemap hosts,
+// including child sitemaps on those hosts
+type SiteRateLimit struct {
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty"` // Sitemap fetches per second, 0 = global limit
+	MaxURLs           int     `json:"max_urls,omitempty"`            // Pages per sitemap, 0 = global -max-urls
+	MaxConcurrent     int     `json:"max_concurrent,omitempty"`      // Requests in flight per host, 0 = global -host-max-concurrent
+	MinInterval       string  `json:"min_interval,omitempty"`        // Gap between requests per host, e.g. 500ms, "" = global -host-min-interval
+	Burst             int     `json:"burst,omitempty"`               // Requests before MinInterval applies, 0 = global -host-burst
+}
+
+// hostLimit converts the politeness fields; zero fields keep the global limit
+func (r SiteRateLimit) hostLimit() (parser.HostLimit, bool) {
+	limit := parser.HostLimit{MaxConcurrent: r.MaxConcurrent, Burst: r.Burst}
+	if r.MinInterval != "" {
+		interval, err := time.ParseDuration(r.MinInterval)
+		if err != nil {
+			return parser.HostLimit{}, false // Rejected by Validate
+		}
+		limit.MinInterval = interval
+	}
+	return limit, limit != parser.HostLimit{}
+}
+
+// Validate checks a site on its own; field names in errors are relative to the site
+func (s SiteConfig) Validate() []error {
+	var errs []error
+	if strings.TrimSpace(s.Name) == "" {
+		errs = append(errs, fmt.Errorf("name is required"))
+	}
+	if len(s.Sitemaps) == 0 {
+		errs = append(errs, fmt.Errorf("sitemaps: at least one sitemap URL is required"))
+	}
+	for i, sitemapURL := range s.Sitemaps {
+		parsed, err := url.Parse(sitemapURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs = append(errs, fmt.Errorf("sitemaps[%d]: %q is not an http(s) URL", i, sitemapURL))
+		}
+	}
+	switch s.Extraction {
+	case "", ExtractionDefault, ExtractionPathAnalysis:
+	default:
+		errs = append(errs, fmt.Errorf("extraction: unknown profile %q (use %s or %s)", s.Extraction, ExtractionDefault, ExtractionPathAnalysis))
+	}
+	if s.Locale != "" {
+		if _, err := api.ParseLocale(s.Locale); err != nil {
+			errs = append(errs, fmt.Errorf("locale: %v", err))
+		}
+	}
+	if s.RateLimit.RequestsPerSecond < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit.requests_per_second cannot be negative, got: %.2f", s.RateLimit.RequestsPerSecond))
+	}
+	if s.RateLimit.MaxURLs < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit.max_urls cannot be negative, got: %d", s.RateLimit.MaxURLs))
+	}
+	if s.RateLimit.MaxConcurrent < 0 || s.RateLimit.Burst < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit.max_concurrent and rate_limit.burst cannot be negative"))
+	}
+	if s.RateLimit.MinInterval != "" {
+		if d, err := time.ParseDuration(s.RateLimit.MinInterval); err != nil || d < 0 {
+			errs = append(errs, fmt.Errorf("rate_limit.min_interval: invalid duration %q", s.RateLimit.MinInterval))
+		}
+	}
+	if s.Schedule != "" {
+		if _, err := ParseSchedule(s.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("schedule: %v", err))
+		}
+	}
+	return errs
+}
+
+// SiteScheduleRules returns a schedule rule for the host of each sitemap of sites with a schedule
+func SiteScheduleRules(sites []SiteConfig) []ScheduleRule {
+	var rules []ScheduleRule
+	for _, site := range sites {
+		if site.Schedule == "" {
+			continue
+		}
+		schedule, err := ParseSchedule(site.Schedule)
+		if err != nil {
+			continue // Rejected by Validate
+		}
+		for _, sitemapURL := range site.Sitemaps {
+			rules = append(rules, ScheduleRule{Host: siteHost(sitemapURL), Spec: site.Schedule, Schedule: schedule})
+		}
+	}
+	return rules
+}
+
+// siteLocaleRules returns a site locale rule for the host of each sitemap of sites with a locale
+func siteLocaleRules(sites []SiteConfig) []LocaleRule {
+	var rules []LocaleRule
+	for _, site := range sites {
+		locale, err := api.ParseLocale(site.Locale)
+		if err != nil || locale.IsZero() {
+			continue
+		}
+		for _, sitemapURL := range site.Sitemaps {
+			rules = append(rules, LocaleRule{Host: siteHost(sitemapURL), Locale: locale})
+		}
+	}
+	return rules
+}
+
+// siteHost returns the lowercase host of a URL without "www.", as rules match it
+func siteHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// siteProfile is a SiteConfig prepared for the pipeline
+type siteProfile struct {
+	name      string
+	include   *parser.PathFilter         // nil keeps every page
+	exclude   *parser.PathFilter         // nil drops none
+	extractor extractor.KeywordExtractor // nil uses the default extractor
+	limiter   *RateLimitedExecutor       // nil leaves fetches to the global limit
+	maxURLs   int
+	tags      []string
+}
+
+// SetSites applies per-site overrides to the sitemaps of the given sites
+// Sitemaps monitored without a site keep the global settings
+func (sm *SitemapMonitor) SetSites(sites []SiteConfig) {
+	profiles := make(map[string]*siteProfile)
+	for _, site := range sites {
+		profile := &siteProfile{
+			name:    site.Name,
+			maxURLs: site.RateLimit.MaxURLs,
+			tags:    site.Tags,
+		}
+		if len(site.Filters.IncludePaths) > 0 {
+			profile.include = parser.NewPathFilter(site.Name+"_include", site.Filters.IncludePaths)
+		}
+		if len(site.Filters.ExcludePaths) > 0 {
+			profile.exclude = parser.NewPathFilter(site.Name+"_exclude", site.Filters.ExcludePaths)
+		}
+		if site.Extraction == ExtractionPathAnalysis || len(site.Filters.ExcludeKeywords) > 0 {
+			profile.extractor = newSiteExtractor(site)
+		}
+		if site.RateLimit.RequestsPerSecond > 0 {
+			profile.limiter = sm.rateLimiterPool.GetOrCreateForAPI("site:"+site.Name, site.RateLimit.RequestsPerSecond)
+		}
+		hostLimit, politeness := site.RateLimit.hostLimit()
+		for _, sitemapURL := range site.Sitemaps {
+			profiles[sitemapURL] = profile
+			if politeness {
+				parser.DefaultHostLimiter().SetHostLimit(parser.HostKey(sitemapURL), hostLimit)
+			}
+		}
+	}
+	sm.siteProfiles = profiles
+}
+
+// SetHostLimits sets the politeness limit of sitemap hosts without a site override
+// The limiter is shared by every parser HTTP client in the process
+func (sm *SitemapMonitor) SetHostLimits(limit parser.HostLimit) {
+	parser.DefaultHostLimiter().SetDefaultLimit(limit)
+}
+
+// newSiteExtractor builds the keyword extractor for a site's extraction profile and stop words
+func newSiteExtractor(site SiteConfig) extractor.KeywordExtractor {
+	var siteExtractor extractor.KeywordExtractor = extractor.NewURLKeywordExtractor()
+	if site.Extraction == ExtractionPathAnalysis {
+		siteExtractor = &pathAnalysisExtractor{EnhancedKeywordExtractor: extractor.NewEnhancedKeywordExtractor()}
+	}
+	if len(site.Filters.ExcludeKeywords) > 0 {
+		siteExtractor.SetFilters([]extractor.Filter{extractor.NewStopWordFilter(site.Name+"_stop_words", site.Filters.ExcludeKeywords)})
+	}
+	return siteExtractor
+}
+
+// pathAnalysisExtractor adapts EnhancedKeywordExtractor's path analysis to KeywordExtractor
+// Filters run after path analysis so they also see the words it adds
+type pathAnalysisExtractor struct {
+	*extractor.EnhancedKeywordExtractor
+	filters []extractor.Filter
+}
+
+func (e *pathAnalysisExtractor) Extract(pageURL string) ([]string, error) {
+	extracted, err := e.ExtractWithAnalysis(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	keywords := extracted.Keywords
+	for _, filter := range e.filters {
+		keywords = filter.Apply(keywords)
+	}
+	return keywords, nil
+}
+
+func (e *pathAnalysisExtractor) SetFilters(filters []extractor.Filter) {
+	e.filters = filters
+}
+
+// fetch runs a sitemap fetch under the site's rate limit, if it has one
+func (p *siteProfile) fetch(ctx context.Context, fn func() error) error {
+	if p == nil || p.limiter == nil {
+		return fn()
+	}
+	return p.limiter.Execute(ctx, fn)
+}
+
+// filterURLs applies the site's path filters
+func (p *siteProfile) filterURLs(urls []parser.URL) []parser.URL {
+	if p == nil || (p.include == nil && p.exclude == nil) {
+		return urls
+	}
+	kept := make([]parser.URL, 0, len(urls))
+	for _, u := range urls {
+		parsed, err := url.Parse(u.Address)
+		if err != nil {
+			continue
+		}
+		if p.include != nil && !p.include.ShouldExclude(parsed) {
+			continue // ShouldExclude reports a match
+		}
+		if p.exclude != nil && p.exclude.ShouldExclude(parsed) {
+			continue
+		}
+		kept = append(kept, u)
+	}
+	return kept
+}
+
+// urlLimit returns the site's URL cap per sitemap, else globalMax
+func (p *siteProfile) urlLimit(globalMax int) int {
+	if p != nil && p.maxURLs > 0 {
+		return p.maxURLs
+	}
+	return globalMax
+}
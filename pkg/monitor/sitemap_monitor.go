@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -18,6 +19,12 @@ import (
 	"sitemap-go/pkg/worker"
 )
 
+// Submission workers start at 3 and grow to 12 while the backend keeps up with the queue
+var submissionAutoscale = backend.AutoscaleConfig{MinWorkers: 3, MaxWorkers: 12}
+
+// submissionQueueWait bounds how long a run blocks on a full submission queue before spilling
+const submissionQueueWait = 30 * time.Second
+
 // SitemapMonitor orchestrates the sitemap monitoring workflow
 type SitemapMonitor struct {
 	parserFactory      parser.ParserFactory
@@ -35,6 +42,18 @@ type SitemapMonitor struct {
 	apiExecutor        *api.SequentialExecutor // Sequential API execution with 1s interval
 	log                *logger.Logger
 	secureLog          *logger.SecurityLogger  // Security-aware logger for sensitive data
+	quotaLedger        *storage.QuotaLedger    // Persisted API spend, nil when quotas are not tracked
+	localeResolver     *LocaleResolver         // Locale per page URL, nil queries without locale
+	runs               runState                // Latest run; each run's own state travels in its context
+	streamSubmitter    *backend.StreamSubmitter // Streams records as they arrive; nil submits per run
+	dryRun             *dryRunState             // Set when the run must not change state or reach the backend
+	changeTracker      *changeTracker           // Sitemap snapshots compared every run
+	siteProfiles       map[string]*siteProfile  // Per-site overrides by sitemap URL
+	checkpoints        *storage.CheckpointStore // Run progress for -resume, nil when not recorded
+	resume             bool                     // Continue the last interrupted run's checkpoint
+	interrupted        int32                    // Set atomically by Interrupt
+	upstreamMu         sync.Mutex
+	cancelUpstream     map[*monitorRun]context.CancelFunc // Cancels parsing and extraction of each running run
 }
 
 // MonitorConfig holds configuration for sitemap monitoring
@@ -45,6 +64,13 @@ type MonitorConfig struct {
 	EncryptionKey     string                `json:"encryption_key"`
 	WorkerPoolSize    int                   `json:"worker_pool_size"`
 	EnableBackendSubmission bool            `json:"enable_backend_submission"`
+	Sinks             []backend.SinkSpec    `json:"sinks,omitempty"` // Empty sends to the backend only
+	WebhookSecret     string                `json:"-"`
+	StreamSubmission  bool                  `json:"stream_submission"`          // NDJSON streaming to the backend as results arrive
+	StreamMaxBytes    int                   `json:"stream_max_bytes,omitempty"` // Body limit per streamed request, 0 = default
+	DryRun            bool                  `json:"dry_run"`                    // Report instead of submitting or persisting
+	DryRunQueryAPI    bool                  `json:"dry_run_query_api"`          // Let a dry run query the keyword API
+	Concurrency       *ConcurrencyConfig    `json:"concurrency,omitempty"`      // nil uses DefaultConcurrencyConfig
 }
 
 // MonitorResult represents the result of monitoring a sitemap
@@ -56,6 +82,7 @@ type MonitorResult struct {
 	Error      string                 `json:"error,omitempty"`
 	Timestamp  time.Time              `json:"timestamp"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Changes    *SitemapChanges        `json:"changes,omitempty"` // Pages changed since the previous snapshot, nil when not compared
 }
 
 // NewSitemapMonitor creates a new sitemap monitor
@@ -115,6 +142,9 @@ func NewSitemapMonitor(cfg interface{}) (*SitemapMonitor, error) {
 	
 	// Create non-blocking submission pool
 	submissionPool := backend.NewSubmissionPool(backendClient, 3) // 3 worker threads
+	submissionPool.SetOutbox(storage.NewOutbox(storageService)) // Survives full queues and early exits
+	submissionPool.SetDeadLetters(storage.NewDeadLetterStore(storageService))
+	submissionPool.SetAutoscale(submissionAutoscale)
 	
 	// Create simple retry processor for failed keywords (startup only)
 	retryProcessor := NewSimpleRetryProcessor(trendAPIClient, simpleTracker, submissionPool, dataConverter)
@@ -134,6 +164,7 @@ func NewSitemapMonitor(cfg interface{}) (*SitemapMonitor, error) {
 		keywordExtractor:   keywordExtractor,
 		apiClient:          trendAPIClient,
 		storage:            storageService,
+		changeTracker:      newChangeTracker(storageService),
 		workerPool:         workerPool,
 		simpleTracker:      simpleTracker,
 		submissionPool:     submissionPool,
@@ -188,6 +219,17 @@ func NewSitemapMonitorWithConfig(backendURL, apiKey string, batchSize int, trend
 	return monitor
 }
 
+// newOutputClient returns the backend client, or a fan-out over the configured sinks
+func newOutputClient(config MonitorConfig, backendConfig backend.BackendConfig) (backend.BackendClient, error) {
+	if len(config.Sinks) == 0 {
+		return backend.NewBackendClient(backendConfig)
+	}
+	return backend.NewFanOutClient(config.Sinks, backend.SinkOptions{
+		Backend:       backendConfig,
+		WebhookSecret: config.WebhookSecret,
+	})
+}
+
 // createSitemapMonitorInternal is the internal safe constructor used by the builder
 func createSitemapMonitorInternal(config MonitorConfig, backendURL, apiKey string, batchSize int) (*SitemapMonitor, error) {
 	// Initialize components
@@ -208,6 +250,11 @@ func createSitemapMonitorInternal(config MonitorConfig, backendURL, apiKey strin
 	if err != nil {
 		storageService = storage.NewMemoryStorage()
 	}
+	var dryRun *dryRunState
+	if config.DryRun {
+		dryRun = newDryRunState(storageService, config.DryRunQueryAPI)
+		storageService = dryRun.overlay
+	}
 	
 	// Create high-performance worker pool
 	poolConfig := worker.DefaultPoolConfig()
@@ -224,21 +271,45 @@ func createSitemapMonitorInternal(config MonitorConfig, backendURL, apiKey strin
 		BatchSize:  batchSize,
 		EnableGzip: true,
 		Timeout:    60 * time.Second,
+		TLS:        config.BackendConfig.TLS,
+		Auth:       config.BackendConfig.Auth,
 	}
-	backendClient, err := backend.NewBackendClient(backendConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create backend client: %w", err)
+	var backendClient backend.BackendClient
+	if dryRun != nil {
+		backendClient = dryRun.recorder
+	} else {
+		backendClient, err = newOutputClient(config, backendConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output client: %w", err)
+		}
 	}
 	dataConverter := backend.NewDataConverter()
 	
 	// Create non-blocking submission pool
 	submissionPool := backend.NewSubmissionPool(backendClient, 3)
+	if dryRun == nil {
+		submissionPool.SetOutbox(storage.NewOutbox(storageService)) // Survives full queues and early exits
+		submissionPool.SetDeadLetters(storage.NewDeadLetterStore(storageService))
+		submissionPool.SetAutoscale(submissionAutoscale)
+	}
+	
+	// Streaming falls back to the pool, and its outbox, for requests that fail
+	var streamSubmitter *backend.StreamSubmitter
+	if config.StreamSubmission && dryRun == nil {
+		streamSubmitter, err = backend.NewStreamSubmitter(backendConfig, backend.StreamConfig{MaxBytes: config.StreamMaxBytes}, submissionPool)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stream submitter: %w", err)
+		}
+	}
 	
 	// Create retry service
 	retryProcessor := NewSimpleRetryProcessor(trendAPIClient, simpleTracker, submissionPool, dataConverter)
 	
 	// Create adaptive concurrency manager with optimized settings
 	concurrencyConfig := DefaultConcurrencyConfig()
+	if config.Concurrency != nil {
+		concurrencyConfig = *config.Concurrency
+	}
 	concurrencyManager := NewAdaptiveConcurrencyManager(concurrencyConfig)
 	
 	// Create rate limiter for sitemap requests
@@ -248,10 +319,13 @@ func createSitemapMonitorInternal(config MonitorConfig, backendURL, apiKey strin
 	rateLimiterPool := NewRateLimiterPool()
 	
 	return &SitemapMonitor{
+		streamSubmitter:    streamSubmitter,
+		dryRun:             dryRun,
 		parserFactory:      parserFactory,
 		keywordExtractor:   keywordExtractor,
 		apiClient:          trendAPIClient,
 		storage:            storageService,
+		changeTracker:      newChangeTracker(storageService),
 		workerPool:         workerPool,
 		simpleTracker:      simpleTracker,
 		submissionPool:     submissionPool,
@@ -270,75 +344,59 @@ func createSitemapMonitorInternal(config MonitorConfig, backendURL, apiKey strin
 
 // ProcessSitemaps processes multiple sitemaps with global keyword deduplication
 func (sm *SitemapMonitor) ProcessSitemaps(ctx context.Context, sitemapURLs []string, workers int) ([]*MonitorResult, error) {
+	run := sm.beginRun(ctx, sitemapURLs, true) // A resumed run keeps its original ID
+	
+	// Records submitted outside the run's context, like retries of failures older than run IDs,
+	// get its ID too; a one-shot run is the only run of the process, unlike scheduled ones
+	sm.submissionPool.SetRunID(run.id)
+	sm.simpleTracker.SetRunID(run.id)
+	if sm.streamSubmitter != nil {
+		sm.streamSubmitter.SetRunID(run.id)
+	}
+	return sm.processSitemaps(withRun(ctx, run), sitemapURLs, workers)
+}
+
+// processSitemaps runs the pipeline for the run carried by ctx
+func (sm *SitemapMonitor) processSitemaps(ctx context.Context, sitemapURLs []string, workers int) ([]*MonitorResult, error) {
 	if workers <= 0 {
 		workers = 8
 	}
+	run := sm.currentRun(ctx)
 	
 	sm.secureLog.SafeInfo("Starting batch sitemap processing with global keyword deduplication", map[string]interface{}{
+		"run_id":        run.id,
 		"sitemap_count": len(sitemapURLs),
 		"workers":       workers,
 	})
 	
+	// Every record submitted by this run carries its ID from ctx, so resends can be deduplicated
+	
 	// Start background services
 	sm.submissionPool.Start(ctx)
 	
-	// Process failed keywords at startup (non-blocking)
-	sm.retryProcessor.ProcessFailedKeywordsAtStartup(ctx)
+	// Process failed keywords at startup (non-blocking); a dry run without API access cannot retry
+	if sm.dryRun == nil || sm.dryRun.queryAPI {
+		sm.retryProcessor.ProcessFailedKeywordsAtStartup(ctx)
+	}
 	
 	// Note: Submission pool will be stopped in Close() method
 	
-	// Step 1: Extract all keywords from all sitemaps first
-	// Step 1: Extract keywords from all sitemaps
-	allKeywords, keywordToSpecificURLMap, sitemapResults, err := sm.extractAllKeywords(ctx, sitemapURLs, workers)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract keywords: %w", err)
-	}
-	
-	// Step 2: Global keyword deduplication
-	sm.log.WithField("total_keywords_before_dedup", len(allKeywords)).Info("Step 2: Starting global keyword deduplication")
-	uniqueKeywords := sm.deduplicateKeywords(allKeywords)
-	sm.log.WithFields(map[string]interface{}{
-		"keywords_before_dedup": len(allKeywords),
-		"keywords_after_dedup":  len(uniqueKeywords),
-		"deduplication_ratio":   fmt.Sprintf("%.1f%%", float64(len(uniqueKeywords))/float64(len(allKeywords))*100),
-	}).Info("Global deduplication completed")
-	
-	// Step 2.5: URL级别去重 - 直接过滤已处理的URL (修复根本问题)
-	sm.log.WithField("total_keywords", len(uniqueKeywords)).Info("Step 2.5: Filtering already processed URLs")
-	filteredKeywords, err := sm.filterUnprocessedKeywordURLs(ctx, uniqueKeywords, keywordToSpecificURLMap)
-	if err != nil {
-		sm.secureLog.SafeError("Failed to filter processed URLs", err, nil)
-		filteredKeywords = uniqueKeywords // Fallback to process all
-	}
+	// Steps 1-3 run as one pipeline, so API queries start as soon as the first sitemap is
+	// parsed: parse → extract → dedup → filter → batch → query → submit, with bounded buffers
+	// between the stages. Interrupt cancels parsing only
+	upstreamCtx, cancelUpstream := sm.upstreamContext(ctx, run)
+	defer cancelUpstream()
+	pipeline := sm.newKeywordPipeline(ctx)
+	pipeline.start(ctx, upstreamCtx, sitemapURLs, workers)
 
-	sm.log.WithFields(map[string]interface{}{
-		"total_sitemaps":         len(sitemapURLs),
-		"keywords_before_filter": len(uniqueKeywords),
-		"keywords_after_filter":  len(filteredKeywords),
-	}).Info("URL filtering completed")
-
-	// Step 3: Query SEOKey API for keywords from unprocessed sitemaps only
-	if len(filteredKeywords) > 0 {
-		sm.log.WithField("filtered_keywords", len(filteredKeywords)).Info("Step 3: Starting SEOKey API queries for unprocessed sitemaps")
-		
-		// Create reverse mapping from keyword to sitemap URL for success tracking
-		keywordToSitemapMap := make(map[string]string)
-		for _, result := range sitemapResults {
-			if result.Success {
-				for _, keyword := range result.Keywords {
-					formattedKeyword := sm.formatKeywordForAPI(keyword)
-					keywordToSitemapMap[formattedKeyword] = result.SitemapURL
-				}
-			}
-		}
-		
-		err = sm.queryAndSubmitKeywords(ctx, filteredKeywords, keywordToSpecificURLMap, keywordToSitemapMap)
-		if err != nil {
-			sm.secureLog.SafeError("Failed to query and submit keywords", err, nil)
-		}
-	} else {
-		sm.log.Info("No keywords from unprocessed sitemaps found to query")
+	if !pipeline.query {
+		sm.log.Info("Dry run: skipping API queries")
+	} else if err := sm.queryAndSubmitKeywords(ctx, pipeline.stream); err != nil {
+		sm.secureLog.SafeError("Failed to query and submit keywords", err, nil)
 	}
+	sitemapResults := pipeline.wait()
+	// The retry belongs to this run: it must not outlive it and overlap the next one
+	sm.retryProcessor.Wait(ctx)
 	
 	// Step 4: Sitemap processing completed
 	// Note: Successful sitemaps are now saved in queryAndSubmitKeywords after API success
@@ -355,9 +413,15 @@ func (sm *SitemapMonitor) ProcessSitemaps(ctx context.Context, sitemapURLs []str
 		"total_sitemaps":        len(sitemapResults),
 		"success_count":         successCount,
 		"failure_count":         len(sitemapResults) - successCount,
-		"unique_keywords_queried": len(uniqueKeywords),
+		"unique_keywords":       pipeline.seen.len(),
 	}).Debug("Batch processing completed")
 	
+	// Queued submissions are replayed from the outbox, the checkpoint only counts them
+	interrupted := ctx.Err() != nil || sm.isInterrupted()
+	run.stats.update(func(summary *RunSummary) { summary.Interrupted = interrupted })
+	run.checkpoint.finish(context.WithoutCancel(ctx), interrupted, sm.submissionPool.GetStats().QueueDepth)
+	sm.flushQuota(context.WithoutCancel(ctx))
+	
 	return sitemapResults, nil
 }
 
@@ -748,6 +812,14 @@ func (sm *SitemapMonitor) getAPIEndpointForRateLimiting() string {
 	return "default-api"
 }
 
+// clientHandlesRateLimiting reports whether the API client applies per-endpoint rate limits itself
+func (sm *SitemapMonitor) clientHandlesRateLimiting() bool {
+	if limited, ok := sm.apiClient.(api.SelfRateLimited); ok {
+		return limited.HandlesRateLimiting()
+	}
+	return false
+}
+
 // saveFailedKeywords saves keywords that failed API query for retry
 func (sm *SitemapMonitor) saveFailedKeywords(ctx context.Context, keywords []string, keywordURLMap map[string]string, sitemapURL string, err error) {
 	sm.secureLog.WarnWithURL("API query failed, saving keywords for retry", sitemapURL, map[string]interface{}{
@@ -769,120 +841,40 @@ func (sm *SitemapMonitor) saveFailedKeywords(ctx context.Context, keywords []str
 
 // ExtractAllKeywords extracts keywords from all sitemaps with optimized concurrency (exported for testing)
 func (sm *SitemapMonitor) ExtractAllKeywords(ctx context.Context, sitemapURLs []string, workers int) ([]string, map[string]string, []*MonitorResult, error) {
-	return sm.extractAllKeywords(ctx, sitemapURLs, workers)
+	extraction, err := sm.extractAllKeywords(ctx, sitemapURLs, workers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return extraction.keywords, extraction.keywordURLs, extraction.results, nil
 }
 
-// extractAllKeywords extracts keywords from all sitemaps with optimized concurrency
-func (sm *SitemapMonitor) extractAllKeywords(ctx context.Context, sitemapURLs []string, workers int) ([]string, map[string]string, []*MonitorResult, error) {
-	// Use adaptive concurrency settings instead of fixed workers count
-	config := sm.concurrencyManager.GetCurrentConfig()
-	actualWorkers := config.MainWorkers
-	if len(sitemapURLs) < actualWorkers {
-		actualWorkers = len(sitemapURLs) // Don't exceed sitemap count
-	}
-	type extractResult struct {
-		sitemapURL string
-		keywords   []string
-		urls       []string
-		success    bool
-		error      string
-	}
-	
-	results := make([]extractResult, 0, len(sitemapURLs))
-	resultsChan := make(chan extractResult, len(sitemapURLs))
-	semaphore := make(chan struct{}, actualWorkers) // Use adaptive worker count
-	
-	var wg sync.WaitGroup
-	
-	sm.secureLog.SafeInfo("Starting optimized concurrent sitemap processing", map[string]interface{}{
-		"requested_workers": workers,
-		"actual_workers":    actualWorkers,
-		"sitemap_count":     len(sitemapURLs),
-	})
-	
-	// Extract keywords from each sitemap with rate limiting and performance tracking
-	for _, sitemapURL := range sitemapURLs {
-		wg.Add(1)
-		go func(url string) {
-			defer wg.Done()
-			
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-			
-			// Rate limiting: use sitemap-specific rate limiter (more permissive)
-			startTime := time.Now()
-			var keywords []string
-			var urls []string
-			var err error
-			
-			// Direct execution without rate limiting for local operations
-			// Rate limiting should only apply to API calls, not local keyword extraction
-			keywords, urls, err = sm.extractKeywordsFromSitemap(ctx, url)
-			
-			responseTime := time.Since(startTime)
-			success := err == nil
-			
-			// Update performance metrics for adaptive adjustment
-			sm.concurrencyManager.UpdateMetrics(responseTime, success)
-			
-			result := extractResult{
-				sitemapURL: url,
-				keywords:   keywords,
-				urls:       urls,
-				success:    success,
-			}
-			if err != nil {
-				result.error = err.Error()
-			}
-			
-			resultsChan <- result
-		}(sitemapURL)
+// keywordExtraction is the combined output of extracting keywords from every sitemap
+// Keywords are query keys: the formatted keyword, locale-qualified (api.LocaleKey) when a locale applies
+type keywordExtraction struct {
+	keywords    []string
+	keywordURLs map[string]string // Query key -> specific page URL
+	results     []*MonitorResult
+}
+
+// extractAllKeywords extracts keywords from all sitemaps and waits for every one of them
+func (sm *SitemapMonitor) extractAllKeywords(ctx context.Context, sitemapURLs []string, workers int) (*keywordExtraction, error) {
+	extraction := &keywordExtraction{
+		keywordURLs: make(map[string]string), // Maps keyword to specific URL (not sitemap)
 	}
-	
-	// Wait for all workers to complete
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
-	
-	// Collect results
-	for result := range resultsChan {
-		results = append(results, result)
-	}
-	
-	// Aggregate all keywords and build mapping to specific URLs
-	var allKeywords []string
-	keywordToSpecificURLMap := make(map[string]string) // Maps keyword to specific URL (not sitemap)
-	sitemapResults := make([]*MonitorResult, len(results))
-	
-	for i, result := range results {
-		sitemapResults[i] = &MonitorResult{
-			SitemapURL: result.sitemapURL,
-			Keywords:   result.keywords,
-			Success:    result.success,
-			Error:      result.error,
-			Timestamp:  time.Now(),
-			Metadata:   make(map[string]interface{}),
+	for extract := range sm.streamSitemaps(ctx, sitemapURLs, workers) {
+		extraction.results = append(extraction.results, sm.monitorResult(extract))
+		if extract.err != nil {
+			continue
 		}
-		
-		if result.success {
-			// Build keyword to specific URL mapping (1:1 correspondence)
-			for j, keyword := range result.keywords {
-				// Format keyword for API query ("action-games" → "action games")
-				formattedKeyword := sm.formatKeywordForAPI(keyword)
-				allKeywords = append(allKeywords, formattedKeyword)
-				
-				// Map formatted keyword to specific URL
-				if j < len(result.urls) {
-					keywordToSpecificURLMap[formattedKeyword] = result.urls[j]
-				}
+		keys, pages := sm.queryKeys(extract)
+		for i, key := range keys {
+			extraction.keywords = append(extraction.keywords, key)
+			if pages[i] != "" {
+				extraction.keywordURLs[key] = pages[i]
 			}
-			sitemapResults[i].Metadata["url_count"] = len(result.urls)
 		}
 	}
-	
-	return allKeywords, keywordToSpecificURLMap, sitemapResults, nil
+	return extraction, nil
 }
 
 // formatKeywordForAPI formats keywords for Google Trends API query
@@ -900,20 +892,52 @@ func (sm *SitemapMonitor) formatKeywordForAPI(keyword string) string {
 	return formatted
 }
 
-// extractKeywordsFromSitemap extracts keywords from a single sitemap
-func (sm *SitemapMonitor) extractKeywordsFromSitemap(ctx context.Context, sitemapURL string) ([]string, []string, error) {
+// extractKeywordsFromSitemap extracts keywords from a single sitemap and compares its pages
+// with the previous snapshot
+func (sm *SitemapMonitor) extractKeywordsFromSitemap(ctx context.Context, sitemapURL string) sitemapExtract {
 	sm.secureLog.InfoWithURL("Starting keyword extraction from sitemap", sitemapURL, nil)
 	
 	// Parse sitemap
 	format := sm.determineFormat(sitemapURL)
 	sitemapParser := sm.parserFactory.GetParser(format)
 	if sitemapParser == nil {
-		return nil, nil, fmt.Errorf("no parser available for format: %s", format)
+		return sitemapExtract{sitemapURL: sitemapURL, err: fmt.Errorf("no parser available for format: %s", format)}
 	}
 	
-	urls, err := sitemapParser.Parse(ctx, sitemapURL)
+	profile := sm.siteProfiles[sitemapURL]
+	var urls []parser.URL
+	err := profile.fetch(ctx, func() error {
+		var parseErr error
+		urls, parseErr = sitemapParser.Parse(ctx, sitemapURL)
+		return parseErr
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse sitemap: %w", err)
+		return sitemapExtract{sitemapURL: sitemapURL, err: fmt.Errorf("failed to parse sitemap: %w", err)}
+	}
+	
+	// Site filters and the URL cap bound what keywords are extracted from; snapshots cover
+	// every filtered URL, so the cap does not report pages as removed. With -changed-only the
+	// cap applies to the changed pages, and those it cuts are left for a later run
+	urls = profile.filterURLs(urls)
+	config := sm.concurrencyManager.GetCurrentConfig()
+	limit := profile.urlLimit(config.MaxURLsPerSitemap)
+	diff := sm.trackSitemapChanges(ctx, sitemapURL, urls, limit)
+	sm.trackRemovals(ctx, sitemapURL, urls, diff)
+	var modified map[string]bool
+	if sm.changeTracker.changedOnly && diff != nil && diff.changeSet != nil {
+		total := len(urls)
+		urls, modified = diff.changedURLs(urls)
+		sm.secureLog.DebugWithURL("Processing only changed pages", sitemapURL, map[string]interface{}{
+			"changed_urls": len(urls),
+			"total_urls":   total,
+		})
+	}
+	if limit > 0 && len(urls) > limit {
+		sm.secureLog.WarnWithURL("Sitemap exceeds the URL limit, extra URLs skipped", sitemapURL, map[string]interface{}{
+			"total_urls": len(urls),
+			"max_urls":   limit,
+		})
+		urls = urls[:limit]
 	}
 	
 	// Only log for large sitemaps to reduce noise
@@ -922,10 +946,18 @@ func (sm *SitemapMonitor) extractKeywordsFromSitemap(ctx context.Context, sitema
 	}
 	
 	// Use parallel extraction with configured worker count for optimal performance
-	config := sm.concurrencyManager.GetCurrentConfig()
 	parallelExtractor := NewParallelKeywordExtractorWithWorkers(config.ExtractWorkers)
+	if profile != nil && profile.extractor != nil {
+		parallelExtractor.extractor = profile.extractor
+	}
 	keywords, urlList, failedCount := parallelExtractor.ExtractFromURLs(ctx, urls, sm.selectPrimaryKeyword)
 	
+	// Keep priority/lastmod for ranking keywords before API queries
+	signals := make(map[string]URLSignals, len(urls))
+	for _, u := range urls {
+		signals[u.Address] = signalsFromURL(u)
+	}
+	
 	// Log summary only if there are significant failures
 	if failedCount > 10 {
 		sm.secureLog.WarnWithURL("High keyword extraction failure rate", sitemapURL, map[string]interface{}{
@@ -940,60 +972,22 @@ func (sm *SitemapMonitor) extractKeywordsFromSitemap(ctx context.Context, sitema
 		"url_count":     len(urlList),
 	})
 	
-	return keywords, urlList, nil
-}
-
-// deduplicateKeywords removes duplicate keywords globally with intelligent similarity detection
-func (sm *SitemapMonitor) deduplicateKeywords(keywords []string) []string {
-	if len(keywords) == 0 {
-		return keywords
-	}
-
-	// Pre-allocate with estimated capacity
-	keywordSet := make(map[string]bool, len(keywords))
-	normalizedSet := make(map[string]string, len(keywords)) // normalized -> original mapping
-	var uniqueKeywords []string
-
-	for _, keyword := range keywords {
-		if keyword == "" {
-			continue
+	if sm.changeTracker.events {
+		keywordByURL := make(map[string]string, len(urlList))
+		for i, pageURL := range urlList {
+			keywordByURL[pageURL] = sm.formatKeywordForAPI(keywords[i])
 		}
-
-		// Skip exact duplicates
-		if keywordSet[keyword] {
-			continue
-		}
-
-		// Normalize for similarity detection
-		normalized := sm.normalizeForDeduplication(keyword)
-
-		// Check if we already have a similar keyword
-		if existingKeyword, exists := normalizedSet[normalized]; exists {
-			// Keep the better quality keyword (higher score)
-			if sm.scoreKeyword(keyword) > sm.scoreKeyword(existingKeyword) {
-				// Replace the existing keyword with the better one
-				normalizedSet[normalized] = keyword
-				// Update in uniqueKeywords slice
-				for i, existing := range uniqueKeywords {
-					if existing == existingKeyword {
-						uniqueKeywords[i] = keyword
-						break
-					}
-				}
-				keywordSet[existingKeyword] = false // Mark old as removed
-				keywordSet[keyword] = true
-			}
-			// Skip adding the lower quality duplicate
-			continue
-		}
-
-		// Add new unique keyword
-		normalizedSet[normalized] = keyword
-		keywordSet[keyword] = true
-		uniqueKeywords = append(uniqueKeywords, keyword)
+		sm.reportSitemapChanges(ctx, sitemapURL, diff, keywordByURL)
+	}
+	
+	return sitemapExtract{
+		sitemapURL: sitemapURL,
+		keywords:   keywords,
+		urls:       urlList,
+		signals:    signals,
+		changes:    diff.counts(),
+		modified:   modified,
 	}
-
-	return uniqueKeywords
 }
 
 // normalizeForDeduplication creates a normalized form for similarity detection
@@ -1012,48 +1006,62 @@ func (sm *SitemapMonitor) normalizeForDeduplication(keyword string) string {
 	return strings.TrimSpace(normalized)
 }
 
+// toBackendRecords converts answered keywords to backend records for their page URLs
+// Keywords without a known page URL are skipped; records carry the run a keyword first failed
+// in, else runID
+func (sm *SitemapMonitor) toBackendRecords(keywords []api.Keyword, keywordToSpecificURLMap map[string]string, originRunIDs map[string]string, runID string) []backend.KeywordMetricsData {
+	var records []backend.KeywordMetricsData
+	for _, keyword := range keywords {
+		specificURL := keywordToSpecificURLMap[keyword.LocaleKey()]
+		if specificURL == "" {
+			continue
+		}
+		recordRunID := originRunIDs[keyword.LocaleKey()]
+		if recordRunID == "" {
+			recordRunID = runID
+		}
+		records = append(records, backend.KeywordMetricsData{
+			Keyword: keyword.Word,
+			Locale:  keyword.Locale,
+			URL:     specificURL, // Use specific game page URL
+			Metrics: sm.dataConverter.ConvertKeywordMetrics(keyword),
+			RunID:   recordRunID,
+		})
+	}
+	return records
+}
+
 // batchResult holds the result of a batch API query
 type batchResult struct {
-	batch     []string
-	trendData *api.APIResponse
-	err       error
+	batch      []string
+	candidates []KeywordCandidate // The batch's keywords with their page and sitemap URLs
+	trendData  *api.APIResponse
+	err        error
 }
 
+// submitChunkSize is how many answered records are collected before they go to the submission pool
+const submitChunkSize = 200
+
 // queryAndSubmitKeywords queries SEOKey API in batches and submits results to backend
-// Uses concurrent batch processing with configurable workers and optimized batch size
-func (sm *SitemapMonitor) queryAndSubmitKeywords(ctx context.Context, keywords []string, keywordToSpecificURLMap map[string]string, keywordToSitemapMap map[string]string) error {
+// Workers take batches from the candidate stream as the pipeline fills it, highest-value
+// first, and results are submitted as they arrive; anything left unqueried when the run
+// stops is persisted for the next run
+func (sm *SitemapMonitor) queryAndSubmitKeywords(ctx context.Context, stream *candidateStream) error {
+	run := sm.currentRun(ctx)
 	// Get current configuration for dynamic worker count
 	config := sm.concurrencyManager.GetCurrentConfig()
 	batchSize := 10 // Maximum batch size for SEOKey API (API limit: 10 keywords per request)
 	concurrentWorkers := config.APIWorkers // Use configured worker count
 	
-	sm.log.WithField("total_keywords", len(keywords)).Info("🔍 Starting API keyword analysis")
+	sm.log.WithField("api_workers", concurrentWorkers).Info("🔍 Starting API keyword analysis")
 	
-	// Create batches
-	var batches [][]string
-	for i := 0; i < len(keywords); i += batchSize {
-		end := i + batchSize
-		if end > len(keywords) {
-			end = len(keywords)
-		}
-		batches = append(batches, keywords[i:end])
-	}
-	
-	// Channel for batch processing
-	batchChan := make(chan []string, len(batches))
-	resultChan := make(chan batchResult, len(batches))
-	
-	// Send batches to channel
-	for _, batch := range batches {
-		batchChan <- batch
-	}
-	close(batchChan)
+	resultChan := make(chan batchResult, concurrentWorkers)
 	
 	// Start concurrent workers
 	var wg sync.WaitGroup
 	for i := 0; i < concurrentWorkers; i++ {
 		wg.Add(1)
-		go sm.processBatchWorker(ctx, i, batchChan, resultChan, keywordToSpecificURLMap, &wg)
+		go sm.processBatchWorker(ctx, i, stream, batchSize, resultChan, &wg)
 	}
 
 	// Wait for all workers to complete - pass wg as parameter to avoid race condition
@@ -1064,6 +1072,7 @@ func (sm *SitemapMonitor) queryAndSubmitKeywords(ctx context.Context, keywords [
 			}
 		}()
 		waitGroup.Wait()
+		stream.abandon() // Producers must not wait for workers that are gone
 		close(resChan)
 	}(&wg, resultChan)
 	
@@ -1072,8 +1081,53 @@ func (sm *SitemapMonitor) queryAndSubmitKeywords(ctx context.Context, keywords [
 	var totalErrors int
 	var successfulKeywords []string // Track keywords that were successfully queried
 	
+	var deferredKeywords []string
+	var unqueriedKeywords []string
+	var noDataKeywords []string
+	var quotaErr error
+	batchCount := 0
+	originRunIDs := sm.simpleTracker.FailedKeywordRunIDs(ctx) // Keywords that failed before keep their first run's keys
+	streamedRecords := 0
+	submittedRecords := 0
+	var pendingRecords []backend.KeywordMetricsData
+	var pendingQueried []string  // Batches whose records wait in pendingRecords
+	var pendingFound []string    // Answered keywords of those batches
+	var refusedKeywords []string // Answered keywords whose records the pool refused
+	// A batch only counts as queried in the checkpoint once its records are with the pool, which
+	// keeps them in its outbox; keywords whose records the pool refused are carried over to the
+	// next run instead of having their URLs saved as processed
+	flushRecords := func() {
+		if len(pendingRecords) > 0 && !sm.submitRecords(ctx, pendingRecords) {
+			refusedKeywords = append(refusedKeywords, pendingFound...)
+			pendingRecords, pendingQueried, pendingFound = nil, nil, nil
+			return
+		}
+		submittedRecords += len(pendingRecords)
+		run.checkpoint.recordQueried(ctx, pendingQueried)
+		pendingRecords, pendingQueried, pendingFound = nil, nil, nil
+	}
+	keywordToSpecificURLMap := make(map[string]string)
+	keywordToSitemapMap := make(map[string]string)
+	mapCandidates := func(candidates []KeywordCandidate) {
+		for _, candidate := range candidates {
+			if candidate.URL != "" {
+				keywordToSpecificURLMap[candidate.Keyword] = candidate.URL
+			}
+			keywordToSitemapMap[candidate.Keyword] = candidate.SitemapURL
+		}
+	}
+	
 	for result := range resultChan {
-		if result.err != nil {
+		batchCount++
+		mapCandidates(result.candidates)
+		if errors.Is(result.err, api.ErrQuotaExhausted) {
+			// Budget spent: keep the keywords for the next quota window instead of failing them
+			deferredKeywords = append(deferredKeywords, result.batch...)
+			quotaErr = result.err
+		} else if result.err != nil && ctx.Err() != nil {
+			// Run deadline hit mid-query: not the keywords' fault, carry them over
+			unqueriedKeywords = append(unqueriedKeywords, result.batch...)
+		} else if result.err != nil {
 			totalErrors++
 			sm.secureLog.SafeError("Batch processing failed", result.err, map[string]interface{}{
 				"batch_size": len(result.batch),
@@ -1087,70 +1141,141 @@ func (sm *SitemapMonitor) queryAndSubmitKeywords(ctx context.Context, keywords [
 				}
 			}
 			if len(failedKeywords) > 0 {
-				if saveErr := sm.simpleTracker.SaveFailedKeywords(ctx, failedKeywords, "", "", result.err); saveErr != nil {
+				if saveErr := sm.simpleTracker.SaveFailedKeywords(context.WithoutCancel(ctx), failedKeywords, "", "", result.err); saveErr != nil {
 					sm.secureLog.SafeError("Failed to save failed keywords", saveErr, nil)
 				}
 			}
-		} else if result.trendData != nil && len(result.trendData.Keywords) > 0 {
-			allTrendData = append(allTrendData, result.trendData.Keywords...)
-			successfulKeywords = append(successfulKeywords, result.batch...)
+			run.checkpoint.recordQueried(ctx, result.batch) // Left to the retry processor
+		} else {
+			// Providers silently leave out keywords they have no data for; only answered ones count
+			keywords, found, missing := reconcileBatch(result.batch, result.trendData)
+			allTrendData = append(allTrendData, keywords...)
+			successfulKeywords = append(successfulKeywords, found...)
+			noDataKeywords = append(noDataKeywords, missing...)
+			records := sm.toBackendRecords(keywords, keywordToSpecificURLMap, originRunIDs, run.id)
+			if sm.streamSubmitter != nil {
+				// Records of failed stream requests go to the pool, like submitted ones
+				sm.streamSubmitter.Add(records...)
+				streamedRecords += len(records)
+				run.checkpoint.recordQueried(ctx, result.batch)
+			} else {
+				pendingRecords = append(pendingRecords, records...)
+				pendingQueried = append(pendingQueried, result.batch...)
+				pendingFound = append(pendingFound, found...)
+				if len(pendingRecords) >= submitChunkSize {
+					// Submit while querying goes on; the pool applies backpressure when the backend is slow
+					flushRecords()
+				}
+			}
+		}
+	}
+	// SEOKey API queries completed; streamed records are already on their way
+	if sm.streamSubmitter != nil {
+		sm.streamSubmitter.Flush()
+	} else {
+		flushRecords()
+	}
+	if len(refusedKeywords) > 0 {
+		// Their answers never reached the pool, so they are queried again rather than settled
+		refused := make(map[string]bool, len(refusedKeywords))
+		for _, keyword := range refusedKeywords {
+			refused[keyword] = true
+		}
+		submitted := successfulKeywords[:0]
+		for _, keyword := range successfulKeywords {
+			if !refused[keyword] {
+				submitted = append(submitted, keyword)
+			}
 		}
+		successfulKeywords = submitted
+		unqueriedKeywords = append(unqueriedKeywords, refusedKeywords...)
 	}
 	
 	sm.log.WithFields(map[string]interface{}{
 		"successful_results": len(allTrendData),
 		"failed_batches":    totalErrors,
-		"total_batches":     len(batches),
+		"total_batches":     batchCount,
+		"no_data_keywords":  len(noDataKeywords),
 	}).Info("Concurrent API queries completed")
 	
-	if len(allTrendData) == 0 {
-		return fmt.Errorf("no successful trend data retrieved from any batch")
+	// Whatever the workers did not reach stays in the stream, highest value first; the
+	// pipeline may still be adding to it, so wait for it to finish
+	leftover := stream.drain()
+	mapCandidates(leftover)
+	for _, candidate := range leftover {
+		if run.quotaSpent() {
+			deferredKeywords = append(deferredKeywords, candidate.Keyword)
+		} else {
+			unqueriedKeywords = append(unqueriedKeywords, candidate.Keyword)
+		}
+	}
+	totalCandidates := stream.total()
+	if totalCandidates == 0 {
+		sm.log.Info("No keywords from unprocessed sitemaps found to query")
+		return nil
+	}
+	
+	// Keywords without data keep their URLs unprocessed until the retry policy gives up on them
+	var givenUpKeywords []string
+	if len(noDataKeywords) > 0 {
+		givenUpKeywords = sm.recordNoDataKeywords(context.WithoutCancel(ctx), noDataKeywords, keywordToSpecificURLMap, keywordToSitemapMap)
 	}
 	
-	// SEOKey API queries completed
+	if len(unqueriedKeywords) > 0 {
+		// The run context may already be done; persisting must still happen
+		sm.carryOverKeywords(context.WithoutCancel(ctx), unqueriedKeywords, keywordToSpecificURLMap, keywordToSitemapMap)
+	}
 	
-	// Convert to backend format and submit
-	var allBackendData []backend.KeywordMetricsData
+	if len(deferredKeywords) > 0 {
+		resetAt := sm.quotaResetTime(quotaErr)
+		sm.deferKeywords(context.WithoutCancel(ctx), deferredKeywords, keywordToSpecificURLMap, keywordToSitemapMap, resetAt)
+		sm.log.WithFields(map[string]interface{}{
+			"deferred_keywords": len(deferredKeywords),
+			"resets_at":         resetAt.Format(time.RFC3339),
+		}).Warn("💳 API quota exhausted, remaining keywords deferred to next window")
+	}
+	run.stats.update(func(summary *RunSummary) {
+		summary.QueriedKeywords += len(successfulKeywords)
+		summary.FailedKeywords += totalCandidates - len(successfulKeywords) - len(deferredKeywords) - len(unqueriedKeywords) - len(noDataKeywords)
+		summary.NoDataKeywords += len(noDataKeywords)
+		summary.DeferredKeywords += len(deferredKeywords)
+		summary.UnqueriedKeywords += len(unqueriedKeywords)
+		summary.QuotaExhausted = summary.QuotaExhausted || len(deferredKeywords) > 0
+	})
 	
+	// Remember volumes so the next run can rank keywords that searched well
+	volumes := make(map[string]int64, len(allTrendData))
 	for _, keyword := range allTrendData {
-		metrics := sm.dataConverter.ConvertKeywordMetrics(keyword)
-		
-		// Get specific URL for this keyword
-		specificURL := keywordToSpecificURLMap[keyword.Word]
-		if specificURL != "" {
-			allBackendData = append(allBackendData, backend.KeywordMetricsData{
-				Keyword: keyword.Word,
-				URL:     specificURL, // Use specific game page URL
-				Metrics: metrics,
-			})
-		}
+		volumes[keyword.LocaleKey()] = int64(keyword.SearchVolume)
+	}
+	if err := sm.simpleTracker.SaveKeywordVolumes(context.WithoutCancel(ctx), volumes); err != nil {
+		sm.log.WithError(err).Warn("Failed to save keyword search volumes")
 	}
 	
-	// Submit to backend (non-blocking)
-	if len(allBackendData) > 0 {
-		// Submit deduplicated results to backend
-		
-		success := sm.submissionPool.Submit(allBackendData, func(err error) {
-			if err != nil {
-				sm.secureLog.SafeError("Backend submission failed for deduplicated data", err, nil)
-			} else {
-				// Backend submission successful
-			}
-		})
-		
-		if !success {
-			sm.log.Warn("Failed to queue deduplicated data for backend submission")
+	if len(allTrendData) == 0 && len(noDataKeywords) == 0 {
+		if len(deferredKeywords) > 0 || len(unqueriedKeywords) > 0 {
+			return nil // Nothing was queried, but nothing was lost either
 		}
+		return fmt.Errorf("no successful trend data retrieved from any batch")
+	}
+	
+	if streamedRecords > 0 {
+		sm.log.WithField("streamed_records", streamedRecords).Debug("Results streamed to backend")
+	}
+	if submittedRecords > 0 {
+		sm.log.WithField("submitted_records", submittedRecords).Debug("Results queued for backend submission")
 	}
 	
 	// ✅ FIX: Save URLs that had successful API queries (URL级别去重 + 防竞态条件)
-	if len(successfulKeywords) > 0 {
+	// Keywords the no-data retry policy gave up on are settled too and must not be rediscovered
+	settledKeywords := append(append([]string{}, successfulKeywords...), givenUpKeywords...)
+	if len(settledKeywords) > 0 {
 		// Group successful URLs by sitemap for batch saving
 		sitemapURLsMap := make(map[string][]string)
-		for _, keyword := range successfulKeywords {
+		for _, keyword := range settledKeywords {
 			if specificURL := keywordToSpecificURLMap[keyword]; specificURL != "" {
 				if sitemapURL := keywordToSitemapMap[keyword]; sitemapURL != "" {
-					sitemapURLsMap[sitemapURL] = append(sitemapURLsMap[sitemapURL], specificURL)
+					sitemapURLsMap[sitemapURL] = append(sitemapURLsMap[sitemapURL], processedURLKey(keyword, specificURL))
 				}
 			}
 		}
@@ -1158,7 +1283,7 @@ func (sm *SitemapMonitor) queryAndSubmitKeywords(ctx context.Context, keywords [
 		// Save URLs in batches (thread-safe with mutex)
 		totalSavedURLs := 0
 		for sitemapURL, urls := range sitemapURLsMap {
-			if err := sm.simpleTracker.SaveProcessedURLs(ctx, urls, sitemapURL); err != nil {
+			if err := sm.simpleTracker.SaveProcessedURLs(context.WithoutCancel(ctx), urls, sitemapURL); err != nil {
 				sm.secureLog.WarnWithURL("Failed to save successfully queried URLs", sitemapURL, map[string]interface{}{
 					"error": err.Error(),
 					"url_count": len(urls),
@@ -1173,11 +1298,35 @@ func (sm *SitemapMonitor) queryAndSubmitKeywords(ctx context.Context, keywords [
 			"successful_urls":     totalSavedURLs,
 			"successful_keywords": len(successfulKeywords),
 		}).Info("✅ Saved successfully queried URLs to avoid reprocessing (URL级别去重)")
+		
+		// Answered keywords may still have a failed record from an earlier run
+		if err := sm.simpleTracker.RemoveSuccessfulKeywords(context.WithoutCancel(ctx), successfulKeywords); err != nil {
+			sm.log.WithError(err).Warn("Failed to clear answered keywords from the failed list")
+		}
 	}
 	
 	return nil
 }
 
+// submitRecords queues answered records for the backend
+// It waits a while for queue space so a slow backend slows the run down; whatever still
+// does not fit is spilled to the outbox rather than dropped
+// It reports whether the pool took the records
+func (sm *SitemapMonitor) submitRecords(ctx context.Context, records []backend.KeywordMetricsData) bool {
+	submitCtx, cancel := context.WithTimeout(ctx, submissionQueueWait)
+	defer cancel()
+	err := sm.submissionPool.SubmitContext(submitCtx, records, func(err error) {
+		if err != nil {
+			sm.secureLog.SafeError("Backend submission failed for deduplicated data", err, nil)
+		}
+	})
+	if err != nil {
+		sm.log.WithError(err).Warn("Failed to queue deduplicated data for backend submission")
+		return false
+	}
+	return true
+}
+
 // filterUnprocessedSitemaps returns sitemaps that haven't been processed yet
 func (sm *SitemapMonitor) filterUnprocessedSitemaps(ctx context.Context, sitemapURLs []string) ([]string, error) {
 	var unprocessedSitemaps []string
@@ -1236,8 +1385,9 @@ func (sm *SitemapMonitor) filterUnprocessedKeywordURLs(ctx context.Context, keyw
 	
 	for _, keyword := range keywords {
 		if specificURL, exists := keywordToSpecificURLMap[keyword]; exists && specificURL != "" {
-			urlsToCheck = append(urlsToCheck, specificURL)
-			keywordToURL[keyword] = specificURL
+			urlKey := processedURLKey(keyword, specificURL)
+			urlsToCheck = append(urlsToCheck, urlKey)
+			keywordToURL[keyword] = urlKey
 		}
 	}
 
@@ -1330,10 +1480,22 @@ func (sm *SitemapMonitor) Close() error {
 		sm.rateLimiter.Close()
 	})
 
+	// Streamed requests hand failures to the pool, so they finish first
+	if sm.streamSubmitter != nil {
+		safeClose("stream submitter", func() error {
+			return sm.streamSubmitter.Close()
+		})
+	}
+
 	safeCloseNoError("submission pool", func() {
 		sm.submissionPool.Stop()
 	})
 
+	// Retries may have spent quota after the last run ended
+	safeCloseNoError("quota ledger", func() {
+		sm.flushQuota(context.Background())
+	})
+
 	// Note: Simple retry processor doesn't need cleanup as it's fire-and-forget
 	
 	// Return combined error if any occurred
@@ -1348,20 +1510,33 @@ func (sm *SitemapMonitor) Close() error {
 	return nil
 }
 
-// processBatchWorker processes batches of keywords concurrently
-func (sm *SitemapMonitor) processBatchWorker(ctx context.Context, workerID int, batchChan <-chan []string, resultChan chan<- batchResult, keywordToSpecificURLMap map[string]string, wg *sync.WaitGroup) {
+// processBatchWorker takes the highest-value batches from the candidate stream until the pipeline is done
+// Workers stop early on cancellation, interruption or an exhausted quota, leaving the rest queued
+func (sm *SitemapMonitor) processBatchWorker(ctx context.Context, workerID int, stream *candidateStream, batchSize int, resultChan chan<- batchResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 	
 	// Removed worker startup debug logging for cleaner output
 
-	for batch := range batchChan {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			resultChan <- batchResult{batch: batch, err: ctx.Err()}
+	// Stop taking work on cancellation, interruption or an exhausted quota
+	run := sm.currentRun(ctx)
+	stop := func() bool {
+		return sm.isInterrupted() || run.quotaSpent()
+	}
+	for {
+		// Waits for the pipeline to queue more; nil once it is done and empty
+		candidates := stream.popBatch(ctx, batchSize, stop)
+		if len(candidates) == 0 {
 			return
-		default:
 		}
+		// Batches share one locale (see KeywordQueue.PopBatch); the API gets plain keywords
+		batch := make([]string, len(candidates))
+		words := make([]string, len(candidates))
+		for i, candidate := range candidates {
+			batch[i] = candidate.Keyword
+			words[i], _ = api.SplitLocaleKey(candidate.Keyword)
+		}
+		locale := candidates[0].Locale
+		queryCtx := api.WithLocale(ctx, locale)
 
 		// Removed batch processing debug logging for cleaner output
 		
@@ -1369,21 +1544,37 @@ func (sm *SitemapMonitor) processBatchWorker(ctx context.Context, workerID int,
 		var trendData *api.APIResponse
 		config := sm.concurrencyManager.GetCurrentConfig()
 
-		// Get API endpoint for proper rate limiting
-		apiEndpoint := sm.getAPIEndpointForRateLimiting()
-		workerRateLimiter := sm.rateLimiterPool.GetOrCreateForAPI(apiEndpoint, config.APIRequestsPerSecond)
-
-		err := workerRateLimiter.Execute(ctx, func() error {
+		query := func() error {
 			var queryErr error
-			trendData, queryErr = sm.apiClient.Query(ctx, batch)
+			trendData, queryErr = sm.apiClient.Query(queryCtx, words)
 			return queryErr
-		})
+		}
+
+		var err error
+		if sm.clientHandlesRateLimiting() {
+			// Multi-endpoint clients pace each endpoint themselves
+			err = query()
+		} else {
+			// Get API endpoint for proper rate limiting
+			apiEndpoint := sm.getAPIEndpointForRateLimiting()
+			workerRateLimiter := sm.rateLimiterPool.GetOrCreateForAPI(apiEndpoint, config.APIRequestsPerSecond)
+			err = workerRateLimiter.Execute(ctx, query)
+		}
+		if errors.Is(err, api.ErrQuotaExhausted) {
+			run.spendQuota()
+		}
+		if trendData != nil && !locale.IsZero() {
+			for i := range trendData.Keywords {
+				trendData.Keywords[i].Locale = locale.String() // Clients that ignore locales still map back
+			}
+		}
 		
 		// Send result
 		resultChan <- batchResult{
-			batch:     batch,
-			trendData: trendData,
-			err:       err,
+			batch:      batch,
+			candidates: candidates,
+			trendData:  trendData,
+			err:        err,
 		}
 		
 		if err != nil {
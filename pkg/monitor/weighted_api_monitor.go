@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"fmt"
+
+	"sitemap-go/pkg/api"
+)
+
+// NewMonitorWithWeightedAPI creates a monitor that load balances across N keyword API endpoints
+// Each endpoint gets its own rate limiter and atomic concurrency limiter from the monitor's pool
+func NewMonitorWithWeightedAPI(config MonitorConfig, backendURL, apiKey string, batchSize int, endpoints []api.EndpointConfig, options api.WeightedClientOptions) (*SitemapMonitor, error) {
+	weightedClient, err := api.NewWeightedAPIClient(endpoints, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create weighted API client: %w", err)
+	}
+
+	monitor, err := createSitemapMonitorInternal(config, backendURL, apiKey, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	monitor.apiClient = weightedClient
+	monitor.retryProcessor = NewSimpleRetryProcessor(weightedClient, monitor.simpleTracker, monitor.submissionPool, monitor.dataConverter)
+	monitor.configureEndpointLimiters(weightedClient)
+
+	return monitor, nil
+}
+
+// configureEndpointLimiters registers per-endpoint rate and concurrency limiters
+// Endpoints without explicit settings fall back to the ConcurrencyConfig defaults
+func (sm *SitemapMonitor) configureEndpointLimiters(client *api.WeightedAPIClient) {
+	config := sm.concurrencyManager.GetCurrentConfig()
+
+	for _, endpoint := range client.Endpoints() {
+		rate := endpoint.RequestsPerSecond
+		if rate <= 0 {
+			rate = config.APIRequestsPerSecond
+		}
+		maxConcurrent := endpoint.MaxConcurrent
+		if maxConcurrent <= 0 {
+			maxConcurrent = config.MaxConcurrentPerAPI
+		}
+
+		rateLimiter := sm.rateLimiterPool.GetOrCreateForAPI(endpoint.URL, rate)
+		atomicLimiter := sm.rateLimiterPool.GetOrCreateAtomicLimiter(endpoint.URL, maxConcurrent, config.ConcurrencyTimeout)
+		client.SetEndpointLimiters(endpoint.URL, rateLimiter, NewAtomicLimiterAdapter(atomicLimiter))
+
+		sm.log.WithFields(map[string]interface{}{
+			"api_endpoint":        sm.maskAPIEndpoint(endpoint.URL),
+			"weight":              endpoint.Weight,
+			"requests_per_second": rate,
+			"max_concurrent":      maxConcurrent,
+		}).Info("Configured API endpoint limiters")
+	}
+}
@@ -423,6 +423,7 @@ func (p *EncodingSafeXMLParser) parseXMLContent(content []byte) ([]URL, error) {
 			Metadata: map[string]string{
 				"changefreq": xmlURL.ChangeFreq,
 				"priority":   xmlURL.Priority,
+				"hreflang":   xmlURL.hreflang(),
 			},
 		}
 		urls = append(urls, url)
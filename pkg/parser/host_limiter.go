@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Politeness defaults applied to every host without its own limit
+const (
+	DefaultHostMaxConcurrent = 4
+	DefaultHostBurst         = 1
+)
+
+// HostLimit bounds the requests sent to one host
+// Zero fields in a per-host limit fall back to the default limit
+type HostLimit struct {
+	MaxConcurrent int           // Requests in flight at once, 0 = unlimited
+	MinInterval   time.Duration // Gap between request starts once the burst is spent, 0 = none
+	Burst         int           // Requests that may start back to back before MinInterval applies
+}
+
+// HostStats are the limiter counters of one host
+type HostStats struct {
+	Host       string `json:"host"`
+	Requests   int64  `json:"requests"`
+	Throttled  int64  `json:"throttled"` // Requests that had to wait for a slot or the interval
+	WaitMs     int64  `json:"wait_ms"`   // Total time requests waited
+	PeakActive int    `json:"peak_active"`
+}
+
+// HostLimiter is a host-keyed concurrency and pacing limiter for sitemap fetches
+// All parser HTTP clients share DefaultHostLimiter, so shards of one portal fetched by
+// different sitemaps and parsers count against the same limit
+type HostLimiter struct {
+	mu        sync.Mutex
+	defaults  HostLimit
+	overrides map[string]HostLimit
+	hosts     map[string]*hostState
+}
+
+// hostState tracks one host; slots is replaced when the host's concurrency limit changes,
+// requests holding the old channel release into it
+type hostState struct {
+	limit  HostLimit
+	slots  chan struct{} // nil when concurrency is unlimited
+	tokens float64
+	last   time.Time
+	active int
+	stats  HostStats
+}
+
+var (
+	defaultHostLimiter     *HostLimiter
+	defaultHostLimiterOnce sync.Once
+)
+
+// DefaultHostLimiter returns the limiter shared by all parser HTTP clients
+func DefaultHostLimiter() *HostLimiter {
+	defaultHostLimiterOnce.Do(func() {
+		defaultHostLimiter = NewHostLimiter(HostLimit{MaxConcurrent: DefaultHostMaxConcurrent, Burst: DefaultHostBurst})
+	})
+	return defaultHostLimiter
+}
+
+// NewHostLimiter creates a limiter applying defaults to every host
+func NewHostLimiter(defaults HostLimit) *HostLimiter {
+	return &HostLimiter{
+		defaults:  defaults,
+		overrides: make(map[string]HostLimit),
+		hosts:     make(map[string]*hostState),
+	}
+}
+
+// Validate checks that no field is negative
+func (l HostLimit) Validate() error {
+	if l.MaxConcurrent < 0 || l.MinInterval < 0 || l.Burst < 0 {
+		return fmt.Errorf("host limit cannot be negative: max_concurrent=%d min_interval=%s burst=%d", l.MaxConcurrent, l.MinInterval, l.Burst)
+	}
+	return nil
+}
+
+// SetDefaultLimit changes the limit of hosts without an override
+func (h *HostLimiter) SetDefaultLimit(limit HostLimit) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.defaults = limit
+}
+
+// SetHostLimit overrides the limit of one host; the host is matched as HostKey returns it
+func (h *HostLimiter) SetHostLimit(host string, limit HostLimit) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.overrides[strings.TrimPrefix(strings.ToLower(host), "www.")] = limit
+}
+
+// HostKey returns the host a URL is limited under: lowercase, without "www."
+func HostKey(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// Acquire waits until a request to rawURL may start and returns the function that ends it
+func (h *HostLimiter) Acquire(ctx context.Context, rawURL string) (func(), error) {
+	if h == nil {
+		return func() {}, nil
+	}
+	host := HostKey(rawURL)
+	start := time.Now()
+
+	h.mu.Lock()
+	state := h.stateLocked(host)
+	slots := state.slots
+	h.mu.Unlock()
+
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		h.mu.Lock()
+		state.active--
+		h.mu.Unlock()
+		if slots != nil {
+			<-slots
+		}
+	}
+
+	h.mu.Lock()
+	delay := state.reserveLocked(time.Now())
+	h.mu.Unlock()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			h.mu.Lock()
+			state.tokens++ // Give back the unused reservation
+			h.mu.Unlock()
+			if slots != nil {
+				<-slots
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	waited := time.Since(start)
+	h.mu.Lock()
+	state.active++
+	state.stats.Requests++
+	if waited >= time.Millisecond {
+		state.stats.Throttled++
+		state.stats.WaitMs += waited.Milliseconds()
+	}
+	if state.active > state.stats.PeakActive {
+		state.stats.PeakActive = state.active
+	}
+	h.mu.Unlock()
+	return release, nil
+}
+
+// Stats returns the counters of every host that was requested, busiest first
+func (h *HostLimiter) Stats() []HostStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stats := make([]HostStats, 0, len(h.hosts))
+	for _, state := range h.hosts {
+		stats = append(stats, state.stats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Requests != stats[j].Requests {
+			return stats[i].Requests > stats[j].Requests
+		}
+		return stats[i].Host < stats[j].Host
+	})
+	return stats
+}
+
+// stateLocked returns the host's state, applying the current limit
+func (h *HostLimiter) stateLocked(host string) *hostState {
+	limit := h.defaults
+	if override, ok := h.overrides[host]; ok {
+		if override.MaxConcurrent > 0 {
+			limit.MaxConcurrent = override.MaxConcurrent
+		}
+		if override.MinInterval > 0 {
+			limit.MinInterval = override.MinInterval
+		}
+		if override.Burst > 0 {
+			limit.Burst = override.Burst
+		}
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	state, ok := h.hosts[host]
+	if !ok {
+		state = &hostState{stats: HostStats{Host: host}, tokens: float64(limit.Burst)}
+		h.hosts[host] = state
+	}
+	if !ok || state.limit.MaxConcurrent != limit.MaxConcurrent {
+		state.slots = nil
+		if limit.MaxConcurrent > 0 {
+			state.slots = make(chan struct{}, limit.MaxConcurrent)
+		}
+	}
+	state.limit = limit
+	return state
+}
+
+// reserveLocked takes a token from the host's bucket and returns how long to wait for it
+// The bucket holds Burst tokens and refills one per MinInterval
+func (s *hostState) reserveLocked(now time.Time) time.Duration {
+	if s.limit.MinInterval <= 0 {
+		return 0
+	}
+	if !s.last.IsZero() {
+		s.tokens += float64(now.Sub(s.last)) / float64(s.limit.MinInterval)
+		if s.tokens > float64(s.limit.Burst) {
+			s.tokens = float64(s.limit.Burst)
+		}
+	}
+	s.last = now
+	s.tokens--
+	if s.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-s.tokens * float64(s.limit.MinInterval))
+}
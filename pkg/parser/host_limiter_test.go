@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterBoundsConcurrencyPerHost(t *testing.T) {
+	limiter := NewHostLimiter(HostLimit{MaxConcurrent: 2})
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(shard int) {
+			defer wg.Done()
+			release, err := limiter.Acquire(ctx, "https://www.portal.example/sitemap-"+string(rune('a'+shard))+".xml")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			active++
+			if active > peak {
+				peak = active
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			release()
+		}(i)
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent requests to one host, got %d", peak)
+	}
+	stats := limiter.Stats()
+	if len(stats) != 1 || stats[0].Host != "portal.example" || stats[0].Requests != 8 || stats[0].PeakActive != 2 || stats[0].Throttled == 0 {
+		t.Errorf("Unexpected host stats: %+v", stats)
+	}
+}
+
+func TestHostLimiterPacesAfterBurst(t *testing.T) {
+	limiter := NewHostLimiter(HostLimit{})
+	limiter.SetHostLimit("www.slow.example", HostLimit{MinInterval: 30 * time.Millisecond, Burst: 2})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := limiter.Acquire(ctx, "https://slow.example/sitemap.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected two requests after the burst to wait about 60ms, took %s", elapsed)
+	}
+
+	// Other hosts keep the unpaced default
+	start = time.Now()
+	for i := 0; i < 4; i++ {
+		release, _ := limiter.Acquire(ctx, "https://fast.example/sitemap.xml")
+		release()
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected no pacing for a host without a limit, took %s", elapsed)
+	}
+}
+
+func TestHostLimiterHonoursContext(t *testing.T) {
+	limiter := NewHostLimiter(HostLimit{MaxConcurrent: 1})
+	release, _ := limiter.Acquire(context.Background(), "https://busy.example/a.xml")
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx, "https://busy.example/b.xml"); err == nil {
+		t.Error("Expected Acquire to give up when its context ends")
+	}
+}
@@ -18,6 +18,7 @@ type HTTPClient struct {
 	client     *fasthttp.Client
 	userAgents []string
 	secureLog  *logger.SecurityLogger
+	limiter    *HostLimiter // Per-host politeness limits, shared with other clients
 }
 
 // NewHTTPClient creates a new HTTP client for sitemap parsing
@@ -35,6 +36,7 @@ func NewHTTPClient() *HTTPClient {
 			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:109.0) Gecko/20100101 Firefox/121.0",
 		},
 		secureLog: logger.GetSecurityLogger(),
+		limiter:   DefaultHostLimiter(),
 	}
 }
 
@@ -68,8 +70,13 @@ func (h *HTTPClient) Download(ctx context.Context, targetURL string) (io.ReadClo
 	// Add browser-like headers for anti-bot protection
 	h.setRequestHeaders(req, targetURL)
 
-	// Execute request with timeout
+	// Execute request with timeout, within the host's limits
+	release, err := h.limiter.Acquire(ctx, targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
 	err = h.client.DoTimeout(req, resp, 30*time.Second)
+	release()
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -21,6 +21,7 @@ type ResilientHTTPClient struct {
 	userAgents     []string
 	proxies        []string
 	retryStrategy  *RetryStrategy
+	limiter        *HostLimiter // Per-host politeness limits, shared with other clients
 	log            *logger.Logger
 }
 
@@ -57,6 +58,7 @@ func NewResilientHTTPClient() *ResilientHTTPClient {
 			BackoffFactor: 2.0,
 			JitterEnabled: true,
 		},
+		limiter: DefaultHostLimiter(),
 		log: logger.GetLogger().WithField("component", "resilient_http_client"),
 	}
 }
@@ -152,7 +154,7 @@ func (r *ResilientHTTPClient) standardDownload(ctx context.Context, targetURL st
 	req.Header.SetMethod(fasthttp.MethodGet)
 	r.setStandardHeaders(req, targetURL, attempt)
 	
-	err := r.client.DoTimeout(req, resp, 45*time.Second)
+	err := r.do(ctx, targetURL, req, resp)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -174,7 +176,7 @@ func (r *ResilientHTTPClient) sessionSimulationDownload(ctx context.Context, tar
 	req.Header.SetMethod(fasthttp.MethodGet)
 	r.setSessionSimulationHeaders(req, targetURL, attempt)
 	
-	err := r.client.DoTimeout(req, resp, 45*time.Second)
+	err := r.do(ctx, targetURL, req, resp)
 	if err != nil {
 		return nil, fmt.Errorf("session simulation request failed: %w", err)
 	}
@@ -204,7 +206,7 @@ func (r *ResilientHTTPClient) robotsCompliantDownload(ctx context.Context, targe
 	req.Header.SetMethod(fasthttp.MethodGet)
 	r.setRobotsCompliantHeaders(req, targetURL, attempt)
 	
-	err := r.client.DoTimeout(req, resp, 45*time.Second)
+	err := r.do(ctx, targetURL, req, resp)
 	if err != nil {
 		return nil, fmt.Errorf("robots compliant request failed: %w", err)
 	}
@@ -226,7 +228,7 @@ func (r *ResilientHTTPClient) minimalHeadersDownload(ctx context.Context, target
 	req.Header.SetMethod(fasthttp.MethodGet)
 	r.setMinimalHeaders(req, targetURL, attempt)
 	
-	err := r.client.DoTimeout(req, resp, 45*time.Second)
+	err := r.do(ctx, targetURL, req, resp)
 	if err != nil {
 		return nil, fmt.Errorf("minimal headers request failed: %w", err)
 	}
@@ -238,6 +240,16 @@ func (r *ResilientHTTPClient) minimalHeadersDownload(ctx context.Context, target
 	return r.processResponse(targetURL, resp)
 }
 
+// do sends one attempt within the host's limits; every retry counts as a request to the host
+func (r *ResilientHTTPClient) do(ctx context.Context, targetURL string, req *fasthttp.Request, resp *fasthttp.Response) error {
+	release, err := r.limiter.Acquire(ctx, targetURL)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return r.client.DoTimeout(req, resp, 45*time.Second)
+}
+
 func (r *ResilientHTTPClient) setStandardHeaders(req *fasthttp.Request, targetURL string, attempt int) {
 	userAgent := r.userAgents[(hash(targetURL)+uint32(attempt))%uint32(len(r.userAgents))]
 	req.Header.SetUserAgent(userAgent)
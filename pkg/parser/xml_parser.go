@@ -13,10 +13,28 @@ import (
 )
 
 type xmlURL struct {
-	Loc        string `xml:"loc"`
-	LastMod    string `xml:"lastmod"`
-	ChangeFreq string `xml:"changefreq"`
-	Priority   string `xml:"priority"`
+	Loc        string         `xml:"loc"`
+	LastMod    string         `xml:"lastmod"`
+	ChangeFreq string         `xml:"changefreq"`
+	Priority   string         `xml:"priority"`
+	Alternates []xmlAlternate `xml:"http://www.w3.org/1999/xhtml link"`
+}
+
+// xmlAlternate is an <xhtml:link rel="alternate" hreflang=".." href=".."/> entry
+type xmlAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	HrefLang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// hreflang returns the language the URL declares for itself via its alternates, or ""
+func (u xmlURL) hreflang() string {
+	for _, alternate := range u.Alternates {
+		if alternate.Href == u.Loc && alternate.HrefLang != "x-default" && strings.EqualFold(alternate.Rel, "alternate") {
+			return alternate.HrefLang
+		}
+	}
+	return ""
 }
 
 type xmlSitemap struct {
@@ -124,6 +142,7 @@ func (p *XMLParser) Parse(ctx context.Context, sitemapURL string) ([]URL, error)
 			Metadata: map[string]string{
 				"changefreq": xmlURL.ChangeFreq,
 				"priority":   xmlURL.Priority,
+				"hreflang":   xmlURL.hreflang(),
 			},
 		}
 		urls = append(urls, url)
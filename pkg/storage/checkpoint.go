@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/logger"
+)
+
+const checkpointKey = "run_checkpoint"
+
+// DefaultCheckpointMaxAge is how long an interrupted run can be resumed
+const DefaultCheckpointMaxAge = 24 * time.Hour
+
+// Checkpoint is the pipeline state of an interrupted run
+type Checkpoint struct {
+	RunID              string                       `json:"run_id"` // Resumed runs reuse it, so resent records keep their idempotency keys
+	CreatedAt          time.Time                    `json:"created_at"`
+	UpdatedAt          time.Time                    `json:"updated_at"`
+	Sitemaps           []string                     `json:"sitemaps"`                   // Sitemaps the run was asked to process
+	Parsed             map[string]SitemapCheckpoint `json:"parsed"`                     // Extraction results by sitemap URL
+	Keywords           []string                     `json:"keywords,omitempty"`         // Deduplicated keywords to query, set once extraction is done
+	KeywordURLs        map[string]string            `json:"keyword_urls,omitempty"`     // Query key -> page URL
+	KeywordSitemaps    map[string]string            `json:"keyword_sitemaps,omitempty"` // Query key -> sitemap URL
+	Queried            []string                     `json:"queried,omitempty"`          // Keywords whose batch finished and was handed over for submission
+	PendingSubmissions int                          `json:"pending_submissions"`        // Submissions waiting in the outbox
+}
+
+// SitemapCheckpoint is what one parsed sitemap produced
+type SitemapCheckpoint struct {
+	Keywords []string                  `json:"keywords"` // Primary keyword per page, in page order
+	URLs     []string                  `json:"urls"`
+	Pages    map[string]CheckpointPage `json:"pages,omitempty"` // Ranking signals by page URL
+}
+
+// CheckpointPage holds the sitemap signals of a page
+type CheckpointPage struct {
+	Priority float64   `json:"priority"`
+	LastMod  time.Time `json:"lastmod"`
+	HrefLang string    `json:"hreflang,omitempty"`
+}
+
+// CheckpointStore keeps the checkpoint of the current or last interrupted run
+type CheckpointStore struct {
+	storage Storage
+	maxAge  time.Duration
+	log     *logger.Logger
+	mu      sync.Mutex
+}
+
+// NewCheckpointStore creates a checkpoint store; checkpoints older than maxAge are discarded
+func NewCheckpointStore(storage Storage, maxAge time.Duration) *CheckpointStore {
+	if maxAge <= 0 {
+		maxAge = DefaultCheckpointMaxAge
+	}
+	return &CheckpointStore{
+		storage: storage,
+		maxAge:  maxAge,
+		log:     logger.GetLogger().WithField("component", "checkpoint_store"),
+	}
+}
+
+// Load returns the last checkpoint, or nil when there is none or it has expired
+// Expired checkpoints are deleted
+func (s *CheckpointStore) Load(ctx context.Context) *Checkpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var checkpoint Checkpoint
+	if err := s.storage.Load(ctx, checkpointKey, &checkpoint); err != nil || checkpoint.RunID == "" {
+		return nil
+	}
+	if age := time.Since(checkpoint.CreatedAt); age > s.maxAge {
+		s.log.WithFields(map[string]interface{}{
+			"run_id":  checkpoint.RunID,
+			"age":     age.Round(time.Minute).String(),
+			"max_age": s.maxAge.String(),
+		}).Info("Discarding expired checkpoint")
+		_ = s.storage.Delete(ctx, checkpointKey)
+		return nil
+	}
+	return &checkpoint
+}
+
+// Save replaces the stored checkpoint
+func (s *CheckpointStore) Save(ctx context.Context, checkpoint *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint.UpdatedAt = time.Now()
+	return s.storage.Save(ctx, checkpointKey, checkpoint)
+}
+
+// Clear removes the checkpoint once its run has finished
+func (s *CheckpointStore) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if exists, _ := s.storage.Exists(ctx, checkpointKey); !exists {
+		return nil
+	}
+	return s.storage.Delete(ctx, checkpointKey)
+}
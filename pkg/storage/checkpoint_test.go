@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCheckpointStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := NewCheckpointStore(NewMemoryStorage(), 0)
+	if store.Load(ctx) != nil {
+		t.Fatal("Expected no checkpoint in empty storage")
+	}
+
+	checkpoint := &Checkpoint{
+		RunID:     "run-1",
+		CreatedAt: time.Now(),
+		Sitemaps:  []string{"https://a.example/sitemap.xml"},
+		Parsed: map[string]SitemapCheckpoint{
+			"https://a.example/sitemap.xml": {
+				Keywords: []string{"subway-surfers"},
+				URLs:     []string{"https://a.example/g/subway-surfers"},
+				Pages:    map[string]CheckpointPage{"https://a.example/g/subway-surfers": {Priority: 0.8, HrefLang: "en"}},
+			},
+		},
+		Queried: []string{"subway surfers"},
+	}
+	if err := store.Save(ctx, checkpoint); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := store.Load(ctx)
+	if loaded == nil || loaded.RunID != "run-1" || len(loaded.Queried) != 1 || loaded.UpdatedAt.IsZero() {
+		t.Fatalf("Unexpected checkpoint: %+v", loaded)
+	}
+	if page := loaded.Parsed["https://a.example/sitemap.xml"].Pages["https://a.example/g/subway-surfers"]; page.Priority != 0.8 {
+		t.Errorf("Page signals not restored: %+v", page)
+	}
+
+	if err := store.Clear(ctx); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if store.Load(ctx) != nil {
+		t.Error("Expected no checkpoint after Clear")
+	}
+	if err := store.Clear(ctx); err != nil {
+		t.Errorf("Clear without a checkpoint failed: %v", err)
+	}
+}
+
+func TestCheckpointStoreDiscardsExpired(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	store := NewCheckpointStore(storage, time.Hour)
+	store.Save(ctx, &Checkpoint{RunID: "old", CreatedAt: time.Now().Add(-2 * time.Hour)})
+
+	if store.Load(ctx) != nil {
+		t.Fatal("Expected an expired checkpoint to be discarded")
+	}
+	if exists, _ := storage.Exists(ctx, checkpointKey); exists {
+		t.Error("Expected the expired checkpoint to be deleted")
+	}
+}
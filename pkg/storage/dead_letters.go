@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/logger"
+)
+
+const (
+	deadLetterKey  = "backend_dead_letters"
+	maxDeadLetters = 10000 // Oldest letters are dropped beyond this
+)
+
+// DeadLetter is a record the receiver rejected; resending it unchanged would fail again
+type DeadLetter struct {
+	Key        string          `json:"key"` // Idempotency key of the record
+	Keyword    string          `json:"keyword"`
+	URL        string          `json:"url"`
+	RunID      string          `json:"run_id,omitempty"`
+	Reason     string          `json:"reason"`
+	Rejections int             `json:"rejections"` // Times the receiver rejected this record
+	RejectedAt time.Time       `json:"rejected_at"`
+	Record     json.RawMessage `json:"record"` // The record as it was submitted
+}
+
+// DeadLetterStore keeps rejected records for inspection instead of retrying them
+type DeadLetterStore struct {
+	storage Storage
+	log     *logger.Logger
+	mu      sync.Mutex
+}
+
+// NewDeadLetterStore creates a dead-letter store backed by the given storage
+func NewDeadLetterStore(storage Storage) *DeadLetterStore {
+	return &DeadLetterStore{
+		storage: storage,
+		log:     logger.GetLogger().WithField("component", "dead_letters"),
+	}
+}
+
+// Add stores rejected records; a record rejected again replaces its earlier letter
+func (d *DeadLetterStore) Add(ctx context.Context, letters []DeadLetter) error {
+	if len(letters) == 0 {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	existing, err := d.loadLocked(ctx)
+	if err != nil {
+		return err
+	}
+	index := make(map[string]int, len(existing))
+	for i, letter := range existing {
+		index[letter.Key] = i
+	}
+
+	now := time.Now()
+	for _, letter := range letters {
+		letter.RejectedAt = now
+		letter.Rejections = 1
+		if i, exists := index[letter.Key]; exists {
+			letter.Rejections = existing[i].Rejections + 1
+			existing[i] = letter
+			continue
+		}
+		index[letter.Key] = len(existing)
+		existing = append(existing, letter)
+	}
+
+	if len(existing) > maxDeadLetters {
+		sort.SliceStable(existing, func(i, j int) bool { return existing[i].RejectedAt.Before(existing[j].RejectedAt) })
+		d.log.WithField("dropped", len(existing)-maxDeadLetters).Warn("Dead-letter store full, dropping oldest letters")
+		existing = existing[len(existing)-maxDeadLetters:]
+	}
+
+	if err := d.storage.Save(ctx, deadLetterKey, existing); err != nil {
+		return fmt.Errorf("failed to save dead letters: %w", err)
+	}
+	d.log.WithField("letters", len(letters)).Warn("Stored records rejected by the backend as dead letters")
+	return nil
+}
+
+// List returns all dead letters, most recently rejected first
+func (d *DeadLetterStore) List(ctx context.Context) ([]DeadLetter, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	letters, err := d.loadLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(letters, func(i, j int) bool { return letters[i].RejectedAt.After(letters[j].RejectedAt) })
+	return letters, nil
+}
+
+func (d *DeadLetterStore) loadLocked(ctx context.Context) ([]DeadLetter, error) {
+	var letters []DeadLetter
+	if exists, _ := d.storage.Exists(ctx, deadLetterKey); !exists {
+		return letters, nil
+	}
+	if err := d.storage.Load(ctx, deadLetterKey, &letters); err != nil {
+		return nil, fmt.Errorf("failed to load dead letters: %w", err)
+	}
+	return letters, nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"sort"
+)
+
+const keywordVolumesKey = "keyword_volumes"
+
+// maxKeywordVolumes bounds the volume history so it cannot grow without limit
+const maxKeywordVolumes = 100000
+
+// SaveKeywordVolumes records the latest known search volume for each keyword
+// Volumes are used to rank keywords in later runs; zero volumes are kept so a
+// keyword that was queried and found empty is not mistaken for an unknown one
+func (st *SimpleTracker) SaveKeywordVolumes(ctx context.Context, volumes map[string]int64) error {
+	if len(volumes) == 0 {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	existing := make(map[string]int64)
+	_ = st.storage.Load(ctx, keywordVolumesKey, &existing)
+	if existing == nil {
+		existing = make(map[string]int64)
+	}
+	for keyword, volume := range volumes {
+		existing[keyword] = volume
+	}
+
+	// Drop the lowest volumes first when the history is full; they carry the least ranking value
+	if len(existing) > maxKeywordVolumes {
+		trimLowestVolumes(existing, maxKeywordVolumes/2)
+	}
+	return st.storage.Save(ctx, keywordVolumesKey, existing)
+}
+
+// LoadKeywordVolumes returns previously recorded search volumes keyed by keyword
+func (st *SimpleTracker) LoadKeywordVolumes(ctx context.Context) map[string]int64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	volumes := make(map[string]int64)
+	if err := st.storage.Load(ctx, keywordVolumesKey, &volumes); err != nil || volumes == nil {
+		return make(map[string]int64)
+	}
+	return volumes
+}
+
+// trimLowestVolumes keeps roughly the keep highest-volume entries (ties at the cutoff are kept)
+func trimLowestVolumes(volumes map[string]int64, keep int) {
+	sorted := make([]int64, 0, len(volumes))
+	for _, volume := range volumes {
+		sorted = append(sorted, volume)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	cutoff := sorted[keep-1]
+	for keyword, volume := range volumes {
+		if volume < cutoff {
+			delete(volumes, keyword)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+const failedKeywordsKey = "failed_keywords"
+
+// Reasons a keyword ended up in the failed-keyword store
+const (
+	FailedReasonQueryError = "query_failed" // The API request itself failed
+	FailedReasonNoData     = "no_data"      // The request succeeded but the response had no entry for the keyword
+)
+
+// RetryPolicy spaces out retries of a failed keyword
+type RetryPolicy struct {
+	Delays      []time.Duration // Delay after the nth attempt; the last delay repeats
+	MaxAttempts int             // Give up after this many attempts (0 = never)
+}
+
+// NoDataRetryPolicy backs off slowly: a provider that has no data for a keyword today
+// rarely has it an hour later, and niche games often never get any
+var NoDataRetryPolicy = RetryPolicy{
+	Delays:      []time.Duration{6 * time.Hour, 24 * time.Hour, 72 * time.Hour, 7 * 24 * time.Hour},
+	MaxAttempts: 4,
+}
+
+// NextRetryAt returns when the keyword may be tried again after the given attempt (1-based)
+func (p RetryPolicy) NextRetryAt(now time.Time, attempt int) time.Time {
+	if len(p.Delays) == 0 {
+		return now
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > len(p.Delays) {
+		attempt = len(p.Delays)
+	}
+	return now.Add(p.Delays[attempt-1])
+}
+
+// Exhausted reports whether no attempt remains after the given one
+func (p RetryPolicy) Exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt >= p.MaxAttempts
+}
+
+// SaveNoDataKeywords records keywords the API answered without data, using NoDataRetryPolicy
+// Records only need Keyword, SourceURL and SitemapURL. Keywords that used up their attempts are
+// removed from the store and returned so the caller can stop rediscovering them
+func (st *SimpleTracker) SaveNoDataKeywords(ctx context.Context, records []FailedKeywordRecord) ([]FailedKeywordRecord, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var existing []FailedKeywordRecord
+	_ = st.storage.Load(ctx, failedKeywordsKey, &existing)
+
+	index := make(map[string]int, len(existing))
+	for i, record := range existing {
+		index[record.Keyword] = i
+	}
+
+	now := time.Now()
+	givenUp := make(map[string]bool)
+	var exhausted []FailedKeywordRecord
+	for _, record := range records {
+		attempt := 1
+		record.RunID = st.runIDFor(ctx)
+		if i, exists := index[record.Keyword]; exists {
+			if existing[i].Reason == FailedReasonNoData {
+				attempt = existing[i].RetryCount + 1
+			}
+			if existing[i].RunID != "" {
+				record.RunID = existing[i].RunID
+			}
+		}
+		record.FailedAt = now
+		record.RetryCount = attempt
+		record.Reason = FailedReasonNoData
+		record.LastError = "no data returned for keyword"
+		record.NextRetryAt = NoDataRetryPolicy.NextRetryAt(now, attempt)
+
+		if NoDataRetryPolicy.Exhausted(attempt) {
+			givenUp[record.Keyword] = true
+			exhausted = append(exhausted, record)
+		}
+		if i, exists := index[record.Keyword]; exists {
+			existing[i] = record
+			continue
+		}
+		index[record.Keyword] = len(existing)
+		existing = append(existing, record)
+	}
+
+	remaining := make([]FailedKeywordRecord, 0, len(existing))
+	for _, record := range existing {
+		if !givenUp[record.Keyword] {
+			remaining = append(remaining, record)
+		}
+	}
+
+	st.log.WithFields(map[string]interface{}{
+		"no_data_keywords": len(records),
+		"given_up":         len(exhausted),
+	}).Info("Saved keywords without API data for retry")
+	if err := st.storage.Save(ctx, failedKeywordsKey, remaining); err != nil {
+		return nil, err
+	}
+	return exhausted, nil
+}
+
+// NoDataBackoff returns keywords whose no-data retry is not due yet, with the time it becomes due
+// Their URLs stay unprocessed, so every run rediscovers them; callers skip them until then
+func (st *SimpleTracker) NoDataBackoff(ctx context.Context) map[string]time.Time {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var failed []FailedKeywordRecord
+	_ = st.storage.Load(ctx, failedKeywordsKey, &failed)
+
+	now := time.Now()
+	backoff := make(map[string]time.Time)
+	for _, record := range failed {
+		if record.Reason == FailedReasonNoData && now.Before(record.NextRetryAt) {
+			backoff[record.Keyword] = record.NextRetryAt
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/logger"
+)
+
+const (
+	outboxIndexKey         = "backend_outbox"
+	outboxPayloadKeyFormat = "outbox_%s"
+)
+
+// OutboxEntry describes one persisted submission waiting for the backend to accept it
+type OutboxEntry struct {
+	ID            string    `json:"id"`
+	Kind          string    `json:"kind,omitempty"` // Payload type chosen by the writer, "" for the default
+	Items         int       `json:"items"`          // Records in the payload
+	CreatedAt     time.Time `json:"created_at"`
+	Attempts      int       `json:"attempts"`
+	LastAttemptAt time.Time `json:"last_attempt_at,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// Outbox persists payloads before they are sent so a crash, full queue or early exit
+// cannot lose them; entries are removed only once the receiver has accepted them
+// Payloads live under their own keys so the index stays small as batches grow
+type Outbox struct {
+	storage Storage
+	entries map[string]*OutboxEntry
+	loaded  bool
+	seq     int64
+	log     *logger.Logger
+	mu      sync.Mutex
+}
+
+// NewOutbox creates an outbox backed by the given storage
+func NewOutbox(storage Storage) *Outbox {
+	return &Outbox{
+		storage: storage,
+		entries: make(map[string]*OutboxEntry),
+		log:     logger.GetLogger().WithField("component", "outbox"),
+	}
+}
+
+// Put writes a payload and returns its entry ID; the payload is durable when Put returns
+func (o *Outbox) Put(ctx context.Context, payload interface{}, items int) (string, error) {
+	return o.PutKind(ctx, "", payload, items)
+}
+
+// PutKind writes a payload tagged with kind, so a replay knows how to decode it
+func (o *Outbox) PutKind(ctx context.Context, kind string, payload interface{}, items int) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.loadLocked(ctx); err != nil {
+		return "", err
+	}
+	o.seq++
+	now := time.Now()
+	id := strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.FormatInt(o.seq, 36)
+
+	if err := o.storage.Save(ctx, fmt.Sprintf(outboxPayloadKeyFormat, id), payload); err != nil {
+		return "", fmt.Errorf("failed to write outbox payload: %w", err)
+	}
+	o.entries[id] = &OutboxEntry{ID: id, Kind: kind, Items: items, CreatedAt: now}
+	if err := o.saveLocked(ctx); err != nil {
+		delete(o.entries, id)
+		_ = o.storage.Delete(ctx, fmt.Sprintf(outboxPayloadKeyFormat, id))
+		return "", err
+	}
+	return id, nil
+}
+
+// Load reads the payload of an entry into dest
+func (o *Outbox) Load(ctx context.Context, id string, dest interface{}) error {
+	if err := o.storage.Load(ctx, fmt.Sprintf(outboxPayloadKeyFormat, id), dest); err != nil {
+		return fmt.Errorf("failed to read outbox payload %s: %w", id, err)
+	}
+	return nil
+}
+
+// Delete removes an entry after the receiver accepted it
+func (o *Outbox) Delete(ctx context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.loadLocked(ctx); err != nil {
+		return err
+	}
+	if _, exists := o.entries[id]; !exists {
+		return nil
+	}
+	delete(o.entries, id)
+	if err := o.saveLocked(ctx); err != nil {
+		return err
+	}
+	// A payload left behind by a failed delete is harmless: nothing in the index points to it
+	_ = o.storage.Delete(ctx, fmt.Sprintf(outboxPayloadKeyFormat, id))
+	return nil
+}
+
+// MarkFailed records a failed delivery attempt; the entry stays for the next replay
+func (o *Outbox) MarkFailed(ctx context.Context, id string, cause error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.loadLocked(ctx); err != nil {
+		return err
+	}
+	entry, exists := o.entries[id]
+	if !exists {
+		return nil
+	}
+	entry.Attempts++
+	entry.LastAttemptAt = time.Now()
+	if cause != nil {
+		entry.LastError = cause.Error()
+	}
+	return o.saveLocked(ctx)
+}
+
+// List returns all pending entries, oldest first
+func (o *Outbox) List(ctx context.Context) ([]OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	entries := make([]OutboxEntry, 0, len(o.entries))
+	for _, entry := range o.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+// loadLocked reads the index once; a missing index means an empty outbox
+func (o *Outbox) loadLocked(ctx context.Context) error {
+	if o.loaded {
+		return nil
+	}
+	var persisted []OutboxEntry
+	if exists, _ := o.storage.Exists(ctx, outboxIndexKey); exists {
+		if err := o.storage.Load(ctx, outboxIndexKey, &persisted); err != nil {
+			return fmt.Errorf("failed to load outbox: %w", err)
+		}
+	}
+	for i := range persisted {
+		entry := persisted[i]
+		o.entries[entry.ID] = &entry
+	}
+	o.loaded = true
+	return nil
+}
+
+func (o *Outbox) saveLocked(ctx context.Context) error {
+	entries := make([]OutboxEntry, 0, len(o.entries))
+	for _, entry := range o.entries {
+		entries = append(entries, *entry)
+	}
+	if err := o.storage.Save(ctx, outboxIndexKey, entries); err != nil {
+		o.log.WithError(err).Warn("Failed to persist outbox index")
+		return fmt.Errorf("failed to save outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOutboxPersistsUntilDeleted(t *testing.T) {
+	ctx := context.Background()
+	backing := NewMemoryStorage()
+	outbox := NewOutbox(backing)
+
+	first, err := outbox.Put(ctx, []string{"a", "b"}, 2)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	second, err := outbox.Put(ctx, []string{"c"}, 1)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := outbox.MarkFailed(ctx, first, errors.New("backend returned 503")); err != nil {
+		t.Fatalf("MarkFailed failed: %v", err)
+	}
+	if err := outbox.Delete(ctx, second); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	// A new process sees only the undelivered entry, with its payload and attempt history
+	reopened := NewOutbox(backing)
+	entries, err := reopened.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != first || entries[0].Items != 2 || entries[0].Attempts != 1 || entries[0].LastError == "" {
+		t.Fatalf("Unexpected entries after reopen: %+v", entries)
+	}
+	var payload []string
+	if err := reopened.Load(ctx, first, &payload); err != nil || len(payload) != 2 || payload[1] != "b" {
+		t.Errorf("Unexpected payload %v (err %v)", payload, err)
+	}
+	if err := reopened.Load(ctx, second, &payload); err == nil {
+		t.Error("Expected delivered payload to be removed")
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// OverlayStorage reads through to a base storage but keeps every write and delete in memory
+// It lets a dry run see real state while leaving it untouched; Changes reports what a real
+// run would have persisted
+type OverlayStorage struct {
+	base    Storage
+	writes  map[string][]byte
+	deletes map[string]bool
+	mu      sync.RWMutex
+}
+
+// NewOverlayStorage creates an overlay on top of base
+func NewOverlayStorage(base Storage) *OverlayStorage {
+	return &OverlayStorage{
+		base:    base,
+		writes:  make(map[string][]byte),
+		deletes: make(map[string]bool),
+	}
+}
+
+// Save keeps data in the overlay
+func (o *OverlayStorage) Save(ctx context.Context, key string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.writes[key] = jsonData
+	delete(o.deletes, key)
+	return nil
+}
+
+// Load prefers overlay data and falls back to the base storage
+func (o *OverlayStorage) Load(ctx context.Context, key string, dest interface{}) error {
+	o.mu.RLock()
+	jsonData, written := o.writes[key]
+	deleted := o.deletes[key]
+	o.mu.RUnlock()
+
+	if deleted {
+		return fmt.Errorf("key not found: %s", key)
+	}
+	if !written {
+		return o.base.Load(ctx, key, dest)
+	}
+	if err := json.Unmarshal(jsonData, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+	return nil
+}
+
+// Delete hides the key from later reads without touching the base storage
+func (o *OverlayStorage) Delete(ctx context.Context, key string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.writes, key)
+	o.deletes[key] = true
+	return nil
+}
+
+// Exists reports whether the key is visible through the overlay
+func (o *OverlayStorage) Exists(ctx context.Context, key string) (bool, error) {
+	o.mu.RLock()
+	_, written := o.writes[key]
+	deleted := o.deletes[key]
+	o.mu.RUnlock()
+
+	if written {
+		return true, nil
+	}
+	if deleted {
+		return false, nil
+	}
+	return o.base.Exists(ctx, key)
+}
+
+// Changes returns the values a real run would have written and the existing keys it would
+// have deleted; deletes of keys the base never had are left out
+func (o *OverlayStorage) Changes(ctx context.Context) (map[string]json.RawMessage, []string) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	writes := make(map[string]json.RawMessage, len(o.writes))
+	for key, data := range o.writes {
+		writes[key] = json.RawMessage(data)
+	}
+	var deletes []string
+	for key := range o.deletes {
+		if exists, _ := o.base.Exists(ctx, key); exists {
+			deletes = append(deletes, key)
+		}
+	}
+	sort.Strings(deletes)
+	return writes, deletes
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOverlayStorageLeavesBaseUntouched(t *testing.T) {
+	ctx := context.Background()
+	base := NewMemoryStorage()
+	if err := base.Save(ctx, "processed_urls", []string{"https://example.com/a"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := base.Save(ctx, "failed_keywords", []string{"old"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	overlay := NewOverlayStorage(base)
+
+	// Reads see the real state until the overlay changes it
+	var urls []string
+	if err := overlay.Load(ctx, "processed_urls", &urls); err != nil || len(urls) != 1 {
+		t.Fatalf("Expected base data through the overlay, got %v (err %v)", urls, err)
+	}
+	if err := overlay.Save(ctx, "processed_urls", []string{"https://example.com/a", "https://example.com/b"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := overlay.Delete(ctx, "failed_keywords"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := overlay.Delete(ctx, "never_existed"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := overlay.Load(ctx, "processed_urls", &urls); err != nil || len(urls) != 2 {
+		t.Errorf("Expected the overlay write, got %v (err %v)", urls, err)
+	}
+	if exists, _ := overlay.Exists(ctx, "failed_keywords"); exists {
+		t.Error("Expected deleted key to be hidden")
+	}
+
+	if err := base.Load(ctx, "processed_urls", &urls); err != nil || len(urls) != 1 {
+		t.Errorf("Expected base to keep its data, got %v (err %v)", urls, err)
+	}
+	if exists, _ := base.Exists(ctx, "failed_keywords"); !exists {
+		t.Error("Expected base key to survive an overlay delete")
+	}
+
+	writes, deletes := overlay.Changes(ctx)
+	if len(writes) != 1 || string(writes["processed_urls"]) == "" {
+		t.Errorf("Unexpected writes: %v", writes)
+	}
+	if len(deletes) != 1 || deletes[0] != "failed_keywords" {
+		t.Errorf("Expected only the existing key as a delete, got %v", deletes)
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+const pendingKeywordsKey = "pending_keywords"
+
+// Reasons a keyword was deferred to a later run
+const (
+	PendingReasonQuota     = "quota_exhausted" // API budget ran out before the keyword was queried
+	PendingReasonUnqueried = "not_queried"     // Run ended (e.g. deadline) before the keyword was reached
+)
+
+// PendingKeywordRecord is a keyword that was extracted but not queried in its run
+// Unlike FailedKeywordRecord it never reached the API, so it carries no error or retry count
+type PendingKeywordRecord struct {
+	Keyword    string    `json:"keyword"`
+	SourceURL  string    `json:"source_url"`
+	SitemapURL string    `json:"sitemap_url"`
+	Reason     string    `json:"reason"`
+	QueuedAt   time.Time `json:"queued_at"`
+	NotBefore  time.Time `json:"not_before"` // Earliest time the keyword should be queried again
+}
+
+// SavePendingKeywords adds keywords to the pending queue, replacing older entries for the same keyword
+func (st *SimpleTracker) SavePendingKeywords(ctx context.Context, records []PendingKeywordRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var existing []PendingKeywordRecord
+	_ = st.storage.Load(ctx, pendingKeywordsKey, &existing)
+
+	index := make(map[string]int, len(existing))
+	for i, record := range existing {
+		index[record.Keyword] = i
+	}
+	for _, record := range records {
+		if i, exists := index[record.Keyword]; exists {
+			existing[i] = record
+			continue
+		}
+		index[record.Keyword] = len(existing)
+		existing = append(existing, record)
+	}
+
+	st.log.WithFields(map[string]interface{}{
+		"added":         len(records),
+		"total_pending": len(existing),
+	}).Info("Saved pending keywords for next run")
+	return st.storage.Save(ctx, pendingKeywordsKey, existing)
+}
+
+// TakeReadyPendingKeywords removes and returns pending keywords whose NotBefore has passed
+// Taken keywords are the caller's responsibility; their URLs remain unprocessed, so a
+// crash before they are queried only means they are rediscovered from the sitemap
+func (st *SimpleTracker) TakeReadyPendingKeywords(ctx context.Context) ([]PendingKeywordRecord, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var pending []PendingKeywordRecord
+	if err := st.storage.Load(ctx, pendingKeywordsKey, &pending); err != nil || len(pending) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	var ready, waiting []PendingKeywordRecord
+	for _, record := range pending {
+		if now.Before(record.NotBefore) {
+			waiting = append(waiting, record)
+		} else {
+			ready = append(ready, record)
+		}
+	}
+	if len(ready) == 0 {
+		return nil, nil
+	}
+
+	if waiting == nil {
+		waiting = []PendingKeywordRecord{}
+	}
+	if err := st.storage.Save(ctx, pendingKeywordsKey, waiting); err != nil {
+		return nil, err
+	}
+	return ready, nil
+}
+
+// CountPendingKeywords returns how many keywords are waiting for a later run
+func (st *SimpleTracker) CountPendingKeywords(ctx context.Context) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var pending []PendingKeywordRecord
+	_ = st.storage.Load(ctx, pendingKeywordsKey, &pending)
+	return len(pending)
+}
@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/logger"
+)
+
+const quotaLedgerKey = "api_quota_ledger"
+
+// quotaFlushInterval bounds how often reservations are persisted; Flush writes the rest
+const quotaFlushInterval = 5 * time.Second
+
+// QuotaLimits defines request caps for one provider endpoint (0 = unlimited)
+type QuotaLimits struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+// QuotaUsage tracks spend for one provider endpoint in the current day and month windows
+// Windows are UTC calendar days and months, matching how providers reset quotas
+type QuotaUsage struct {
+	Provider    string      `json:"provider"`
+	Endpoint    string      `json:"endpoint"`
+	Day         string      `json:"day"`   // 2006-01-02
+	Month       string      `json:"month"` // 2006-01
+	DailyUsed   int64       `json:"daily_used"`
+	MonthlyUsed int64       `json:"monthly_used"`
+	Limits      QuotaLimits `json:"limits"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// DailyRemaining returns requests left today, or -1 when unlimited
+func (u QuotaUsage) DailyRemaining() int64 {
+	return remaining(u.Limits.Daily, u.DailyUsed)
+}
+
+// MonthlyRemaining returns requests left this month, or -1 when unlimited
+func (u QuotaUsage) MonthlyRemaining() int64 {
+	return remaining(u.Limits.Monthly, u.MonthlyUsed)
+}
+
+func remaining(limit, used int64) int64 {
+	if limit <= 0 {
+		return -1
+	}
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// ErrQuotaExhausted is returned by QuotaLedger.Reserve when a daily or monthly cap is reached
+type ErrQuotaExhausted struct {
+	Provider string
+	Endpoint string
+	Window   string    // "daily" or "monthly"
+	ResetAt  time.Time // Start of the next window
+}
+
+func (e *ErrQuotaExhausted) Error() string {
+	return fmt.Sprintf("%s quota exhausted for %s (resets %s)", e.Window, e.Provider, e.ResetAt.Format(time.RFC3339))
+}
+
+// QuotaLedger persists API spend per provider and endpoint across runs
+// Counts live in memory and are written at most every quotaFlushInterval, so the query hot
+// path does not encrypt and save the ledger per request; call Flush when a run ends
+type QuotaLedger struct {
+	storage   Storage
+	entries   map[string]*QuotaUsage
+	loaded    bool
+	dirty     bool // Reservations not persisted yet
+	lastFlush time.Time
+	now       func() time.Time
+	log       *logger.Logger
+	mu        sync.Mutex
+}
+
+// NewQuotaLedger creates a ledger backed by the given storage
+func NewQuotaLedger(storage Storage) *QuotaLedger {
+	return &QuotaLedger{
+		storage: storage,
+		entries: make(map[string]*QuotaUsage),
+		now:     func() time.Time { return time.Now().UTC() },
+		log:     logger.GetLogger().WithField("component", "quota_ledger"),
+	}
+}
+
+// SetLimits registers the caps for an endpoint; usage already recorded is kept
+func (ql *QuotaLedger) SetLimits(ctx context.Context, provider, endpoint string, limits QuotaLimits) error {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+
+	entry, err := ql.entryLocked(ctx, provider, endpoint)
+	if err != nil {
+		return err
+	}
+	entry.Limits = limits
+	return nil
+}
+
+// Available reports whether at least one more request fits in the current windows
+func (ql *QuotaLedger) Available(ctx context.Context, provider, endpoint string) bool {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+
+	entry, err := ql.entryLocked(ctx, provider, endpoint)
+	if err != nil {
+		return true // Fail open: a storage problem should not stop the run
+	}
+	return ql.checkLocked(entry, 1) == nil
+}
+
+// Reserve records n requests against the endpoint, or returns *ErrQuotaExhausted without recording
+// The check and the charge happen under one lock, so concurrent callers cannot overshoot a cap
+func (ql *QuotaLedger) Reserve(ctx context.Context, provider, endpoint string, n int64) error {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+
+	entry, err := ql.entryLocked(ctx, provider, endpoint)
+	if err != nil {
+		return err
+	}
+	if err := ql.checkLocked(entry, n); err != nil {
+		return err
+	}
+
+	now := ql.now()
+	entry.DailyUsed += n
+	entry.MonthlyUsed += n
+	entry.UpdatedAt = now
+	ql.dirty = true
+	if now.Sub(ql.lastFlush) < quotaFlushInterval {
+		return nil
+	}
+	return ql.saveLocked(ctx)
+}
+
+// Flush persists reservations not written yet
+func (ql *QuotaLedger) Flush(ctx context.Context) error {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+	if !ql.dirty {
+		return nil
+	}
+	return ql.saveLocked(ctx)
+}
+
+// Snapshot returns current usage for every known endpoint, sorted by provider and endpoint
+func (ql *QuotaLedger) Snapshot(ctx context.Context) ([]QuotaUsage, error) {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+
+	if err := ql.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	usages := make([]QuotaUsage, 0, len(ql.entries))
+	for _, entry := range ql.entries {
+		ql.rollLocked(entry)
+		usages = append(usages, *entry)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Provider != usages[j].Provider {
+			return usages[i].Provider < usages[j].Provider
+		}
+		return usages[i].Endpoint < usages[j].Endpoint
+	})
+	return usages, nil
+}
+
+// NextWindow returns when the given window ("daily" or "monthly") next resets
+func (ql *QuotaLedger) NextWindow(window string) time.Time {
+	now := ql.now()
+	if window == "monthly" {
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+// checkLocked verifies n more requests fit in both windows
+func (ql *QuotaLedger) checkLocked(entry *QuotaUsage, n int64) error {
+	ql.rollLocked(entry)
+	if entry.Limits.Daily > 0 && entry.DailyUsed+n > entry.Limits.Daily {
+		return &ErrQuotaExhausted{Provider: entry.Provider, Endpoint: entry.Endpoint, Window: "daily", ResetAt: ql.NextWindow("daily")}
+	}
+	if entry.Limits.Monthly > 0 && entry.MonthlyUsed+n > entry.Limits.Monthly {
+		return &ErrQuotaExhausted{Provider: entry.Provider, Endpoint: entry.Endpoint, Window: "monthly", ResetAt: ql.NextWindow("monthly")}
+	}
+	return nil
+}
+
+// rollLocked resets counters when the day or month has changed since the last update
+func (ql *QuotaLedger) rollLocked(entry *QuotaUsage) {
+	now := ql.now()
+	day := now.Format("2006-01-02")
+	month := now.Format("2006-01")
+	if entry.Month != month {
+		entry.Month = month
+		entry.MonthlyUsed = 0
+	}
+	if entry.Day != day {
+		entry.Day = day
+		entry.DailyUsed = 0
+	}
+}
+
+func (ql *QuotaLedger) entryLocked(ctx context.Context, provider, endpoint string) (*QuotaUsage, error) {
+	if err := ql.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	key := provider + "|" + endpoint
+	entry, exists := ql.entries[key]
+	if !exists {
+		entry = &QuotaUsage{Provider: provider, Endpoint: endpoint}
+		ql.entries[key] = entry
+	}
+	return entry, nil
+}
+
+// loadLocked reads persisted usage once; a missing ledger starts empty
+func (ql *QuotaLedger) loadLocked(ctx context.Context) error {
+	if ql.loaded {
+		return nil
+	}
+	var persisted []QuotaUsage
+	if exists, _ := ql.storage.Exists(ctx, quotaLedgerKey); exists {
+		if err := ql.storage.Load(ctx, quotaLedgerKey, &persisted); err != nil {
+			return fmt.Errorf("failed to load quota ledger: %w", err)
+		}
+	}
+	for i := range persisted {
+		usage := persisted[i]
+		ql.entries[usage.Provider+"|"+usage.Endpoint] = &usage
+	}
+	ql.loaded = true
+	return nil
+}
+
+func (ql *QuotaLedger) saveLocked(ctx context.Context) error {
+	usages := make([]QuotaUsage, 0, len(ql.entries))
+	for _, entry := range ql.entries {
+		usages = append(usages, *entry)
+	}
+	if err := ql.storage.Save(ctx, quotaLedgerKey, usages); err != nil {
+		ql.log.WithError(err).Warn("Failed to persist quota ledger")
+		return fmt.Errorf("failed to save quota ledger: %w", err)
+	}
+	ql.dirty = false
+	ql.lastFlush = ql.now()
+	return nil
+}
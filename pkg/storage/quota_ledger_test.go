@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQuotaLedgerReserveUntilExhausted(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewQuotaLedger(NewMemoryStorage())
+	if err := ledger.SetLimits(ctx, "seokey", "https://api.example.com", QuotaLimits{Daily: 3}); err != nil {
+		t.Fatalf("SetLimits failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := ledger.Reserve(ctx, "seokey", "https://api.example.com", 1); err != nil {
+			t.Fatalf("Reserve %d failed: %v", i, err)
+		}
+	}
+	if ledger.Available(ctx, "seokey", "https://api.example.com") {
+		t.Error("Expected quota to be unavailable after 3 requests")
+	}
+
+	err := ledger.Reserve(ctx, "seokey", "https://api.example.com", 1)
+	var exhausted *ErrQuotaExhausted
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("Expected ErrQuotaExhausted, got %v", err)
+	}
+	if exhausted.Window != "daily" || !exhausted.ResetAt.After(time.Now()) {
+		t.Errorf("Unexpected exhaustion details: %+v", exhausted)
+	}
+
+	usage, _ := ledger.Snapshot(ctx)
+	if len(usage) != 1 || usage[0].DailyUsed != 3 || usage[0].DailyRemaining() != 0 || usage[0].MonthlyRemaining() != -1 {
+		t.Errorf("Unexpected usage snapshot: %+v", usage)
+	}
+}
+
+func TestQuotaLedgerPersistsAndRollsOver(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	day := time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)
+
+	first := NewQuotaLedger(storage)
+	first.now = func() time.Time { return day }
+	first.SetLimits(ctx, "seokey", "a", QuotaLimits{Daily: 2, Monthly: 10})
+	first.Reserve(ctx, "seokey", "a", 2)
+	first.Flush(ctx)
+
+	// A new run on the same day sees the spend recorded by the previous run
+	second := NewQuotaLedger(storage)
+	second.now = func() time.Time { return day }
+	if second.Available(ctx, "seokey", "a") {
+		t.Fatal("Expected persisted spend to exhaust the daily quota")
+	}
+
+	// Next day (and month) resets both windows
+	second.now = func() time.Time { return day.Add(2 * time.Hour) }
+	if err := second.Reserve(ctx, "seokey", "a", 1); err != nil {
+		t.Fatalf("Expected quota after rollover, got %v", err)
+	}
+	usage, _ := second.Snapshot(ctx)
+	if usage[0].DailyUsed != 1 || usage[0].MonthlyUsed != 1 || usage[0].Month != "2026-04" {
+		t.Errorf("Unexpected usage after rollover: %+v", usage[0])
+	}
+}
+
+func TestQuotaLedgerThrottlesWrites(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ledger := NewQuotaLedger(storage)
+	ledger.now = func() time.Time { return now }
+
+	ledger.Reserve(ctx, "seokey", "a", 1) // First reservation is written at once
+	ledger.Reserve(ctx, "seokey", "a", 1)
+	ledger.Reserve(ctx, "seokey", "a", 1)
+
+	persisted := func() int64 {
+		reader := NewQuotaLedger(storage)
+		reader.now = ledger.now
+		usage, _ := reader.Snapshot(ctx)
+		if len(usage) == 0 {
+			return 0
+		}
+		return usage[0].DailyUsed
+	}
+	if used := persisted(); used != 1 {
+		t.Fatalf("Expected reservations within the flush interval kept in memory, %d persisted", used)
+	}
+	if err := ledger.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if used := persisted(); used != 3 {
+		t.Errorf("Expected Flush to persist every reservation, %d persisted", used)
+	}
+
+	now = now.Add(quotaFlushInterval)
+	ledger.Reserve(ctx, "seokey", "a", 1)
+	if used := persisted(); used != 4 {
+		t.Errorf("Expected a reservation after the interval to be written, %d persisted", used)
+	}
+}
+
+func TestQuotaLedgerConcurrentReserveNeverOvershoots(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewQuotaLedger(NewMemoryStorage())
+	ledger.SetLimits(ctx, "seokey", "a", QuotaLimits{Daily: 50})
+
+	var wg sync.WaitGroup
+	var granted int64
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if ledger.Reserve(ctx, "seokey", "a", 1) == nil {
+					atomic.AddInt64(&granted, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if granted != 50 {
+		t.Errorf("Expected exactly 50 reservations granted, got %d", granted)
+	}
+}
@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/logger"
+)
+
+const (
+	removalIndexKey     = "removal_ledger_index"
+	removalLedgerPrefix = "removal_ledger_"
+)
+
+// Removal ledger defaults
+const (
+	DefaultRemovalGrace     = 24 * time.Hour      // How long a page must stay missing before its removal is confirmed
+	DefaultRemovalRetention = 90 * 24 * time.Hour // How long confirmed removals are kept for reappearance checks
+)
+
+// RemovedURL is a page missing from its sitemap, pending until the grace period confirms it
+type RemovedURL struct {
+	URL          string     `json:"url"`
+	SitemapURL   string     `json:"sitemap_url"`
+	FirstSeen    time.Time  `json:"first_seen"` // First run that listed the page; zero when unknown
+	LastSeen     time.Time  `json:"last_seen"`  // Last run that listed the page
+	MissingSince time.Time  `json:"missing_since"`
+	Misses       int        `json:"misses"`                 // Runs in a row that did not list the page
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"` // nil while the removal is pending
+}
+
+// Confirmed reports whether the grace period has confirmed the removal
+func (r RemovedURL) Confirmed() bool {
+	return r.ConfirmedAt != nil
+}
+
+// RemovalObservation is what one run saw of a sitemap
+type RemovalObservation struct {
+	SitemapURL string
+	Present    []string  // Every page the sitemap lists
+	Removed    []string  // Pages the change detector reports as removed since the previous snapshot
+	PreviousAt time.Time // Previous snapshot, used as last-seen time until the ledger has its own
+	At         time.Time
+}
+
+// RemovalUpdate is how one observation changed the ledger
+type RemovalUpdate struct {
+	Opened     int          // Pages that went missing this run
+	Recovered  int          // Pending pages listed again before their removal was confirmed
+	Pending    int          // Pages still missing within the grace period
+	Confirmed  []RemovedURL // Removals confirmed this run
+	Reappeared []RemovedURL // Confirmed removals listed again
+}
+
+// sitemapRemovals is the ledger of one sitemap
+type sitemapRemovals struct {
+	SitemapURL string                 `json:"sitemap_url"`
+	ObservedAt time.Time              `json:"observed_at"` // Last run that observed the sitemap
+	FirstSeen  map[string]time.Time   `json:"first_seen"`  // Listed pages
+	Missing    map[string]*RemovedURL `json:"missing,omitempty"`
+}
+
+// RemovalLedger records pages that disappeared from their sitemaps
+// A page only counts as removed once it has been missing for the grace period and from at least
+// two runs, so a single flaky or truncated fetch does not delist it
+type RemovalLedger struct {
+	storage   Storage
+	grace     time.Duration
+	retention time.Duration
+	log       *logger.Logger
+	mu        sync.Mutex
+}
+
+// NewRemovalLedger creates a removal ledger; a negative grace uses DefaultRemovalGrace
+func NewRemovalLedger(storage Storage, grace time.Duration) *RemovalLedger {
+	if grace < 0 {
+		grace = DefaultRemovalGrace
+	}
+	return &RemovalLedger{
+		storage:   storage,
+		grace:     grace,
+		retention: DefaultRemovalRetention,
+		log:       logger.GetLogger().WithField("component", "removal_ledger"),
+	}
+}
+
+// SetGrace changes how long a page must stay missing before its removal is confirmed
+func (l *RemovalLedger) SetGrace(grace time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if grace >= 0 {
+		l.grace = grace
+	}
+}
+
+// Grace returns the confirmation grace period
+func (l *RemovalLedger) Grace() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.grace
+}
+
+// Observe records the pages a run found in a sitemap
+// Pages reported removed, or listed before and missing now, open a pending removal; pending
+// removals still missing after the grace period are confirmed, and confirmed removals listed
+// again are returned as reappeared
+func (l *RemovalLedger) Observe(ctx context.Context, observation RemovalObservation) (RemovalUpdate, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var update RemovalUpdate
+	ledger, isNew, err := l.loadLocked(ctx, observation.SitemapURL)
+	if err != nil {
+		return update, err
+	}
+	now := observation.At
+	if now.IsZero() {
+		now = time.Now()
+	}
+	lastSeen := ledger.ObservedAt
+	if lastSeen.IsZero() {
+		lastSeen = observation.PreviousAt
+	}
+
+	present := make(map[string]bool, len(observation.Present))
+	for _, pageURL := range observation.Present {
+		present[pageURL] = true
+		if entry, missing := ledger.Missing[pageURL]; missing {
+			delete(ledger.Missing, pageURL)
+			if entry.Confirmed() {
+				update.Reappeared = append(update.Reappeared, *entry)
+			} else {
+				update.Recovered++
+			}
+			if !entry.FirstSeen.IsZero() {
+				ledger.FirstSeen[pageURL] = entry.FirstSeen
+			}
+		}
+		if _, known := ledger.FirstSeen[pageURL]; !known {
+			// Pages already listed when the ledger started were seen at least since the previous snapshot
+			firstSeen := now
+			if isNew && !observation.PreviousAt.IsZero() {
+				firstSeen = observation.PreviousAt
+			}
+			ledger.FirstSeen[pageURL] = firstSeen
+		}
+	}
+
+	// Pending removals seen missing again count towards confirmation before new ones open
+	for _, entry := range ledger.Missing {
+		if present[entry.URL] {
+			continue
+		}
+		if entry.Confirmed() {
+			if now.Sub(*entry.ConfirmedAt) > l.retention {
+				delete(ledger.Missing, entry.URL)
+			}
+			continue
+		}
+		entry.Misses++
+		if now.Sub(entry.MissingSince) >= l.grace {
+			confirmedAt := now
+			entry.ConfirmedAt = &confirmedAt
+			update.Confirmed = append(update.Confirmed, *entry)
+			continue
+		}
+		update.Pending++
+	}
+
+	open := func(pageURL string) {
+		if present[pageURL] {
+			return
+		}
+		if _, missing := ledger.Missing[pageURL]; missing {
+			return
+		}
+		firstSeen, known := ledger.FirstSeen[pageURL]
+		if !known {
+			firstSeen = observation.PreviousAt
+		}
+		delete(ledger.FirstSeen, pageURL)
+		ledger.Missing[pageURL] = &RemovedURL{
+			URL:          pageURL,
+			SitemapURL:   observation.SitemapURL,
+			FirstSeen:    firstSeen,
+			LastSeen:     lastSeen,
+			MissingSince: now,
+			Misses:       1,
+		}
+		update.Opened++
+		update.Pending++
+	}
+	for _, pageURL := range observation.Removed {
+		open(pageURL)
+	}
+	for pageURL := range ledger.FirstSeen {
+		open(pageURL)
+	}
+
+	ledger.ObservedAt = now
+	if err := l.saveLocked(ctx, ledger, isNew); err != nil {
+		return update, err
+	}
+	sortRemovedURLs(update.Confirmed)
+	sortRemovedURLs(update.Reappeared)
+	if len(update.Confirmed) > 0 {
+		l.log.WithFields(map[string]interface{}{
+			"confirmed": len(update.Confirmed),
+			"pending":   update.Pending,
+		}).Info("Confirmed pages removed from sitemap")
+	}
+	return update, nil
+}
+
+// MarkReappeared clears a page from every sitemap's ledger, pending or confirmed, for pages that are
+// back but not listed yet or were delisted by mistake. It returns the cleared entries
+func (l *RemovalLedger) MarkReappeared(ctx context.Context, pageURL string) ([]RemovedURL, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sitemaps, err := l.indexLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cleared []RemovedURL
+	for _, sitemapURL := range sitemaps {
+		ledger, _, err := l.loadLocked(ctx, sitemapURL)
+		if err != nil {
+			return cleared, err
+		}
+		entry, missing := ledger.Missing[pageURL]
+		if !missing {
+			continue
+		}
+		delete(ledger.Missing, pageURL)
+		if err := l.saveLocked(ctx, ledger, false); err != nil {
+			return cleared, err
+		}
+		cleared = append(cleared, *entry)
+	}
+	return cleared, nil
+}
+
+// List returns every missing page, confirmed removals first, most recently missing first
+func (l *RemovalLedger) List(ctx context.Context) ([]RemovedURL, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sitemaps, err := l.indexLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entries []RemovedURL
+	for _, sitemapURL := range sitemaps {
+		ledger, _, err := l.loadLocked(ctx, sitemapURL)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range ledger.Missing {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Confirmed() != entries[j].Confirmed() {
+			return entries[i].Confirmed()
+		}
+		if !entries[i].MissingSince.Equal(entries[j].MissingSince) {
+			return entries[i].MissingSince.After(entries[j].MissingSince)
+		}
+		return entries[i].URL < entries[j].URL
+	})
+	return entries, nil
+}
+
+func (l *RemovalLedger) loadLocked(ctx context.Context, sitemapURL string) (*sitemapRemovals, bool, error) {
+	ledger := &sitemapRemovals{SitemapURL: sitemapURL}
+	key := removalLedgerKey(sitemapURL)
+	exists, _ := l.storage.Exists(ctx, key)
+	if exists {
+		if err := l.storage.Load(ctx, key, ledger); err != nil {
+			return nil, false, fmt.Errorf("failed to load removal ledger: %w", err)
+		}
+	}
+	if ledger.FirstSeen == nil {
+		ledger.FirstSeen = make(map[string]time.Time)
+	}
+	if ledger.Missing == nil {
+		ledger.Missing = make(map[string]*RemovedURL)
+	}
+	return ledger, !exists, nil
+}
+
+func (l *RemovalLedger) saveLocked(ctx context.Context, ledger *sitemapRemovals, isNew bool) error {
+	if err := l.storage.Save(ctx, removalLedgerKey(ledger.SitemapURL), ledger); err != nil {
+		return fmt.Errorf("failed to save removal ledger: %w", err)
+	}
+	if !isNew {
+		return nil
+	}
+	sitemaps, err := l.indexLocked(ctx)
+	if err != nil {
+		return err
+	}
+	if err := l.storage.Save(ctx, removalIndexKey, append(sitemaps, ledger.SitemapURL)); err != nil {
+		return fmt.Errorf("failed to save removal ledger index: %w", err)
+	}
+	return nil
+}
+
+func (l *RemovalLedger) indexLocked(ctx context.Context) ([]string, error) {
+	var sitemaps []string
+	if exists, _ := l.storage.Exists(ctx, removalIndexKey); !exists {
+		return sitemaps, nil
+	}
+	if err := l.storage.Load(ctx, removalIndexKey, &sitemaps); err != nil {
+		return nil, fmt.Errorf("failed to load removal ledger index: %w", err)
+	}
+	return sitemaps, nil
+}
+
+// removalLedgerKey hashes the sitemap URL into a storage-safe key
+func removalLedgerKey(sitemapURL string) string {
+	sum := sha256.Sum256([]byte(sitemapURL))
+	return removalLedgerPrefix + hex.EncodeToString(sum[:8])
+}
+
+func sortRemovedURLs(entries []RemovedURL) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].URL < entries[j].URL })
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const ledgerSitemap = "https://a.example/sitemap.xml"
+
+func TestRemovalLedgerConfirmsAfterGrace(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewRemovalLedger(NewMemoryStorage(), time.Hour)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pages := []string{"https://a.example/g/subway-surfers", "https://a.example/g/temple-run"}
+
+	if _, err := ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: pages, At: start}); err != nil {
+		t.Fatalf("Observe failed: %v", err)
+	}
+
+	// Missing for the first time: pending, even though the grace period is short
+	update, err := ledger.Observe(ctx, RemovalObservation{
+		SitemapURL: ledgerSitemap,
+		Present:    pages[:1],
+		Removed:    pages[1:],
+		At:         start.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Observe failed: %v", err)
+	}
+	if update.Opened != 1 || update.Pending != 1 || len(update.Confirmed) != 0 {
+		t.Fatalf("Expected one pending removal, got %+v", update)
+	}
+
+	// Still missing within the grace period
+	update, _ = ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: pages[:1], At: start.Add(150 * time.Minute)})
+	if update.Pending != 1 || len(update.Confirmed) != 0 {
+		t.Fatalf("Expected the removal to stay pending within the grace period, got %+v", update)
+	}
+
+	update, _ = ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: pages[:1], At: start.Add(4 * time.Hour)})
+	if len(update.Confirmed) != 1 {
+		t.Fatalf("Expected the removal confirmed after the grace period, got %+v", update)
+	}
+	removed := update.Confirmed[0]
+	if removed.URL != pages[1] || !removed.FirstSeen.Equal(start) || !removed.LastSeen.Equal(start) || removed.Misses != 3 {
+		t.Errorf("Unexpected removal: %+v", removed)
+	}
+
+	entries, err := ledger.List(ctx)
+	if err != nil || len(entries) != 1 || !entries[0].Confirmed() {
+		t.Fatalf("Expected one confirmed removal listed, got %+v (%v)", entries, err)
+	}
+
+	update, _ = ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: pages, At: start.Add(5 * time.Hour)})
+	if len(update.Reappeared) != 1 || update.Reappeared[0].URL != pages[1] {
+		t.Fatalf("Expected the page to reappear, got %+v", update)
+	}
+	if entries, _ := ledger.List(ctx); len(entries) != 0 {
+		t.Errorf("Expected an empty ledger after reappearance, got %+v", entries)
+	}
+}
+
+func TestRemovalLedgerIgnoresFlakyFetch(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewRemovalLedger(NewMemoryStorage(), 0)
+	start := time.Now()
+	pages := []string{"https://a.example/g/one", "https://a.example/g/two"}
+
+	ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: pages, At: start})
+	update, _ := ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: pages[:1], At: start.Add(time.Minute)})
+	if len(update.Confirmed) != 0 || update.Pending != 1 {
+		t.Fatalf("A single missed fetch must not confirm a removal, got %+v", update)
+	}
+
+	update, _ = ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: pages, At: start.Add(2 * time.Minute)})
+	if update.Recovered != 1 || len(update.Reappeared) != 0 {
+		t.Fatalf("Expected the pending removal recovered silently, got %+v", update)
+	}
+	if entries, _ := ledger.List(ctx); len(entries) != 0 {
+		t.Errorf("Expected nothing missing, got %+v", entries)
+	}
+}
+
+func TestRemovalLedgerMarkReappeared(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	ledger := NewRemovalLedger(storage, 0)
+	start := time.Now()
+	previous := start.Add(-24 * time.Hour)
+	page := "https://a.example/g/gone"
+
+	// The detector reports a removal before the ledger ever saw the sitemap
+	ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: []string{"https://a.example/g/kept"}, Removed: []string{page}, PreviousAt: previous, At: start})
+	update, _ := ledger.Observe(ctx, RemovalObservation{SitemapURL: ledgerSitemap, Present: []string{"https://a.example/g/kept"}, At: start.Add(time.Minute)})
+	if len(update.Confirmed) != 1 || !update.Confirmed[0].LastSeen.Equal(previous) || !update.Confirmed[0].FirstSeen.Equal(previous) {
+		t.Fatalf("Expected a confirmed removal last seen at the previous snapshot, got %+v", update)
+	}
+
+	cleared, err := NewRemovalLedger(storage, 0).MarkReappeared(ctx, page)
+	if err != nil || len(cleared) != 1 || cleared[0].SitemapURL != ledgerSitemap {
+		t.Fatalf("Expected the removal cleared, got %+v (%v)", cleared, err)
+	}
+	if entries, _ := ledger.List(ctx); len(entries) != 0 {
+		t.Errorf("Expected an empty ledger, got %+v", entries)
+	}
+	if cleared, _ := ledger.MarkReappeared(ctx, page); len(cleared) != 0 {
+		t.Errorf("Expected nothing to clear twice, got %+v", cleared)
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sitemap-go/pkg/logger"
+)
+
+const scheduleStateKey = "daemon_schedule"
+
+// ScheduleState is the persisted schedule of one site in daemon mode
+type ScheduleState struct {
+	Site      string    `json:"site"`     // Sitemap URL
+	Schedule  string    `json:"schedule"` // Spec NextRun was computed from
+	NextRun   time.Time `json:"next_run"`
+	LastRun   time.Time `json:"last_run,omitempty"`
+	LastRunID string    `json:"last_run_id,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// ScheduleStore keeps next-run times across daemon restarts
+type ScheduleStore struct {
+	storage Storage
+	log     *logger.Logger
+	mu      sync.Mutex
+}
+
+// NewScheduleStore creates a schedule store backed by the given storage
+func NewScheduleStore(storage Storage) *ScheduleStore {
+	return &ScheduleStore{
+		storage: storage,
+		log:     logger.GetLogger().WithField("component", "schedule_store"),
+	}
+}
+
+// Load returns the persisted state per site; a missing schedule is an empty map
+func (s *ScheduleStore) Load(ctx context.Context) map[string]ScheduleState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadLocked(ctx)
+}
+
+// Save replaces the persisted state of the given sites, keeping the others
+func (s *ScheduleStore) Save(ctx context.Context, states ...ScheduleState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := s.loadLocked(ctx)
+	for _, state := range states {
+		all[state.Site] = state
+	}
+	list := make([]ScheduleState, 0, len(all))
+	for _, state := range all {
+		list = append(list, state)
+	}
+	return s.storage.Save(ctx, scheduleStateKey, list)
+}
+
+func (s *ScheduleStore) loadLocked(ctx context.Context) map[string]ScheduleState {
+	var list []ScheduleState
+	if err := s.storage.Load(ctx, scheduleStateKey, &list); err != nil {
+		s.log.WithError(err).Debug("No persisted schedule, starting fresh")
+	}
+	states := make(map[string]ScheduleState, len(list))
+	for _, state := range list {
+		states[state.Site] = state
+	}
+	return states
+}
@@ -14,6 +14,7 @@ type SimpleTracker struct {
 	storage Storage
 	log     *logger.Logger
 	mu      sync.Mutex // 防止竞态条件的互斥锁
+	runID   string     // Stamped on new failed keyword records
 }
 
 // NewSimpleTracker创建简化的跟踪器
@@ -24,6 +25,29 @@ func NewSimpleTracker(storage Storage) *SimpleTracker {
 	}
 }
 
+// SetRunID sets the run ID recorded on keywords that start failing from now on
+func (st *SimpleTracker) SetRunID(runID string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.runID = runID
+}
+
+type runIDContextKey struct{}
+
+// WithRunID attaches the run ID that records saved with ctx carry, taking precedence over
+// SetRunID so that overlapping runs keep their own
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDContextKey{}, runID)
+}
+
+// runIDFor returns the run ID to stamp on new records; callers hold st.mu
+func (st *SimpleTracker) runIDFor(ctx context.Context) string {
+	if runID, _ := ctx.Value(runIDContextKey{}).(string); runID != "" {
+		return runID
+	}
+	return st.runID
+}
+
 // ProcessedURLSet represents a set of processed URL hashes (极简设计)
 type ProcessedURLSet map[string]bool // URLHash -> processed
 
@@ -36,6 +60,8 @@ type FailedKeywordRecord struct {
 	RetryCount  int       `json:"retry_count"`
 	LastError   string    `json:"last_error"`
 	NextRetryAt time.Time `json:"next_retry_at"`
+	Reason      string    `json:"reason,omitempty"` // FailedReasonQueryError or FailedReasonNoData; empty in older records
+	RunID       string    `json:"run_id,omitempty"` // Run the keyword first failed in; retries submit under it
 }
 
 // SaveProcessedURLs saves multiple URL hashes to avoid duplicate processing (极简设计)
@@ -133,6 +159,9 @@ func (st *SimpleTracker) SaveFailedKeywords(ctx context.Context, keywords []stri
 		return nil
 	}
 	
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	
 	// Load existing failed keywords
 	var existingFailed []FailedKeywordRecord
 	_ = st.storage.Load(ctx, "failed_keywords", &existingFailed)
@@ -149,6 +178,7 @@ func (st *SimpleTracker) SaveFailedKeywords(ctx context.Context, keywords []stri
 			// Update existing record
 			existing.RetryCount++
 			existing.LastError = err.Error()
+			existing.Reason = FailedReasonQueryError
 			existing.FailedAt = now
 			existing.NextRetryAt = st.calculateNextRetryTime(existing.RetryCount)
 			failedMap[keyword] = existing
@@ -162,6 +192,8 @@ func (st *SimpleTracker) SaveFailedKeywords(ctx context.Context, keywords []stri
 				RetryCount:  1,
 				LastError:   err.Error(),
 				NextRetryAt: st.calculateNextRetryTime(1),
+				Reason:      FailedReasonQueryError,
+				RunID:       st.runIDFor(ctx),
 			}
 		}
 	}
@@ -177,6 +209,7 @@ func (st *SimpleTracker) SaveFailedKeywords(ctx context.Context, keywords []stri
 }
 
 // GetRetryableKeywords gets keywords ready for retry
+// No-data keywords are excluded: re-sending them without reconciling the response would loop
 func (st *SimpleTracker) GetRetryableKeywords(ctx context.Context) ([]FailedKeywordRecord, error) {
 	var failedKeywords []FailedKeywordRecord
 	err := st.storage.Load(ctx, "failed_keywords", &failedKeywords)
@@ -188,6 +221,9 @@ func (st *SimpleTracker) GetRetryableKeywords(ctx context.Context) ([]FailedKeyw
 	now := time.Now()
 	
 	for _, failed := range failedKeywords {
+		if failed.Reason == FailedReasonNoData {
+			continue // Retried by the main run, see NoDataBackoff
+		}
 		if now.After(failed.NextRetryAt) {
 			retryable = append(retryable, failed)
 		}
@@ -201,11 +237,27 @@ func (st *SimpleTracker) GetRetryableKeywords(ctx context.Context) ([]FailedKeyw
 	return retryable, nil
 }
 
+// FailedKeywordRunIDs maps failed keywords to the run they first failed in
+func (st *SimpleTracker) FailedKeywordRunIDs(ctx context.Context) map[string]string {
+	var failedKeywords []FailedKeywordRecord
+	_ = st.storage.Load(ctx, "failed_keywords", &failedKeywords)
+
+	runIDs := make(map[string]string, len(failedKeywords))
+	for _, failed := range failedKeywords {
+		if failed.RunID != "" {
+			runIDs[failed.Keyword] = failed.RunID
+		}
+	}
+	return runIDs
+}
+
 // RemoveSuccessfulKeywords removes keywords that were successfully processed
 func (st *SimpleTracker) RemoveSuccessfulKeywords(ctx context.Context, successfulKeywords []string) error {
 	if len(successfulKeywords) == 0 {
 		return nil
 	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
 	
 	var failedKeywords []FailedKeywordRecord
 	err := st.storage.Load(ctx, "failed_keywords", &failedKeywords)
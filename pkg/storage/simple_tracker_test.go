@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"sitemap-go/pkg/utils"
@@ -136,3 +137,36 @@ func TestSimpleTracker_DuplicateSaveHandling(t *testing.T) {
 		t.Error("Expected URL to remain processed after duplicate save")
 	}
 }
+
+func TestSimpleTracker_NoDataKeywordsBackOffAndGiveUp(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewSimpleTracker(NewMemoryStorage())
+
+	if err := tracker.SaveFailedKeywords(ctx, []string{"broken game"}, "", "", errors.New("timeout")); err != nil {
+		t.Fatalf("SaveFailedKeywords failed: %v", err)
+	}
+	record := FailedKeywordRecord{Keyword: "rare game", SourceURL: "https://example.com/rare-game"}
+	for attempt := 1; attempt <= NoDataRetryPolicy.MaxAttempts; attempt++ {
+		givenUp, err := tracker.SaveNoDataKeywords(ctx, []FailedKeywordRecord{record})
+		if err != nil {
+			t.Fatalf("SaveNoDataKeywords attempt %d failed: %v", attempt, err)
+		}
+		if last := attempt == NoDataRetryPolicy.MaxAttempts; last != (len(givenUp) == 1) {
+			t.Fatalf("Attempt %d: unexpected given up keywords %+v", attempt, givenUp)
+		}
+		if attempt == 1 {
+			if _, waiting := tracker.NoDataBackoff(ctx)["rare game"]; !waiting {
+				t.Error("Expected no-data keyword to be in backoff")
+			}
+		}
+	}
+
+	if _, waiting := tracker.NoDataBackoff(ctx)["rare game"]; waiting {
+		t.Error("Expected given up keyword to leave the store")
+	}
+	var stored []FailedKeywordRecord
+	_ = tracker.storage.Load(ctx, failedKeywordsKey, &stored)
+	if len(stored) != 1 || stored[0].Keyword != "broken game" || stored[0].Reason != FailedReasonQueryError {
+		t.Errorf("Expected only the query failure to remain, got %+v", stored)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"sitemap-go/pkg/storage"
+)
+
+// runRemovals inspects the ledger of pages that disappeared from their sitemaps
+func runRemovals(args []string) int {
+	fs := flag.NewFlagSet("removals", flag.ContinueOnError)
+	var (
+		dataDir       = fs.String("data-dir", "./data", "Directory holding the encrypted run state")
+		encryptionKey = fs.String("encryption-key", getEnvOrDefault("ENCRYPTION_KEY", ""), "Encryption key used by the monitor (env: ENCRYPTION_KEY)")
+	)
+	fs.Usage = func() {
+		fmt.Println("USAGE:")
+		fmt.Println("    ./sitemap-go removals list     [-data-dir DIR]")
+		fmt.Println("    ./sitemap-go removals reappear [-data-dir DIR] PAGE_URL")
+		fmt.Println("")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		fs.Usage()
+		return 2
+	}
+	action := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *encryptionKey == "" {
+		fmt.Println("ERROR: Encryption key is required to read the removal ledger.")
+		fmt.Println("Use -encryption-key flag or ENCRYPTION_KEY environment variable.")
+		return 1
+	}
+
+	storageService, err := storage.NewEncryptedFileStorage(storage.StorageConfig{
+		DataDir:     *dataDir,
+		EncryptData: true,
+	}, *encryptionKey)
+	if err != nil {
+		fmt.Printf("ERROR: failed to open data directory: %v\n", err)
+		return 1
+	}
+	ledger := storage.NewRemovalLedger(storageService, storage.DefaultRemovalGrace)
+
+	switch action {
+	case "list":
+		return listRemovals(ledger)
+	case "reappear":
+		if fs.NArg() != 1 {
+			fmt.Println("ERROR: reappear takes exactly one page URL")
+			return 2
+		}
+		cleared, err := ledger.MarkReappeared(context.Background(), fs.Arg(0))
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			return 1
+		}
+		if len(cleared) == 0 {
+			fmt.Println("🪦 Page is not in the removal ledger")
+			return 1
+		}
+		for _, entry := range cleared {
+			fmt.Printf("♻️  Cleared from %s (missing since %s)\n", entry.SitemapURL, entry.MissingSince.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Println("   The backend is not notified; the page is tracked again once its sitemap lists it")
+		return 0
+	default:
+		fmt.Printf("ERROR: unknown removals action %q\n\n", action)
+		fs.Usage()
+		return 2
+	}
+}
+
+func listRemovals(ledger *storage.RemovalLedger) int {
+	entries, err := ledger.List(context.Background())
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Println("🪦 No pages missing from their sitemaps")
+		return 0
+	}
+
+	confirmed := 0
+	fmt.Printf("%-10s %-20s %-20s %-20s %6s  %s\n", "STATUS", "FIRST SEEN", "LAST SEEN", "MISSING SINCE", "MISSES", "URL")
+	for _, entry := range entries {
+		status := "pending"
+		if entry.Confirmed() {
+			status = "removed"
+			confirmed++
+		}
+		fmt.Printf("%-10s %-20s %-20s %-20s %6d  %s\n", status, formatLedgerTime(entry.FirstSeen), formatLedgerTime(entry.LastSeen),
+			formatLedgerTime(entry.MissingSince), entry.Misses, entry.URL)
+	}
+	fmt.Printf("🪦 %d pages removed, %d pending confirmation\n", confirmed, len(entries)-confirmed)
+	return 0
+}
+
+func formatLedgerTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
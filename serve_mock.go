@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"sitemap-go/pkg/mock"
+)
+
+// runServeMock starts the mock keyword API and backend until interrupted
+func runServeMock(args []string) int {
+	fs := flag.NewFlagSet("serve-mock", flag.ContinueOnError)
+	var (
+		apiAddr       = fs.String("api-addr", "127.0.0.1:8081", "Listen address for the mock keyword API")
+		backendAddr   = fs.String("backend-addr", "127.0.0.1:8082", "Listen address for the mock backend")
+		backendAPIKey = fs.String("backend-api-key", getEnvOrDefault("BACKEND_API_KEY", ""), "Credential the mock backend requires: API key, bearer token or HMAC secret (empty accepts any)")
+		backendAuth   = fs.String("backend-auth", getEnvOrDefault("BACKEND_AUTH", ""), "Auth scheme the mock backend verifies: api-key, bearer or hmac (env: BACKEND_AUTH)")
+		tokenFile     = fs.String("backend-token-file", getEnvOrDefault("BACKEND_TOKEN_FILE", ""), "File holding the required credential, re-read on every request (env: BACKEND_TOKEN_FILE)")
+		keyID         = fs.String("backend-key-id", getEnvOrDefault("BACKEND_KEY_ID", ""), "X-Key-Id required with hmac auth (env: BACKEND_KEY_ID)")
+		latency       = fs.Duration("latency", 0, "Fixed response latency")
+		jitter        = fs.Duration("jitter", 0, "Random extra latency up to this value")
+		errorRate     = fs.Float64("error-rate", 0, "Fraction of requests answered with 500")
+		rateLimitRate = fs.Float64("rate-limit-rate", 0, "Fraction of requests answered with 429")
+		malformedRate = fs.Float64("malformed-rate", 0, "Fraction of requests answered with malformed JSON")
+		partialRate   = fs.Float64("partial-rate", 0, "Fraction of batches answered only partially")
+		seed          = fs.Int64("seed", 0, "Random seed for fault injection (0 = time based)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	behavior := mock.Behavior{
+		Latency:       *latency,
+		LatencyJitter: *jitter,
+		ErrorRate:     *errorRate,
+		RateLimitRate: *rateLimitRate,
+		MalformedRate: *malformedRate,
+		PartialRate:   *partialRate,
+		Seed:          *seed,
+	}
+
+	keywordAPI, err := mock.NewKeywordAPIServer(*apiAddr, behavior)
+	if err != nil {
+		fmt.Printf("ERROR: failed to start mock keyword API: %v\n", err)
+		return 1
+	}
+	defer keywordAPI.Close()
+
+	backendServer, err := mock.NewBackendServer(*backendAddr, *backendAPIKey, behavior)
+	if err != nil {
+		fmt.Printf("ERROR: failed to start mock backend: %v\n", err)
+		return 1
+	}
+	defer backendServer.Close()
+	if err := backendServer.SetAuth(mock.Auth{Scheme: *backendAuth, Secret: *backendAPIKey, TokenFile: *tokenFile, KeyID: *keyID}); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("🧪 Mock servers running (Ctrl+C to stop)\n")
+	fmt.Printf("   TRENDS_API_URL=%s\n", keywordAPI.URL())
+	fmt.Printf("   BACKEND_URL=%s\n", backendServer.URL())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	apiRequests := keywordAPI.Requests()
+	backendRequests := backendServer.Requests()
+	fmt.Printf("\n🧪 === Mock Server Summary ===\n")
+	fmt.Printf("🔍 Keyword API requests: %d (%d keywords)\n", len(apiRequests), len(keywordAPI.QueriedKeywords()))
+	fmt.Printf("📤 Backend submissions: %d (%d records stored)\n", len(backendRequests), len(backendServer.Stored()))
+	fmt.Printf("⏱️  Stopped at %s\n", time.Now().Format(time.RFC3339))
+	return 0
+}
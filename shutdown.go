@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"sitemap-go/pkg/monitor"
+)
+
+// defaultShutdownGrace is how long in-flight work may run after SIGINT or SIGTERM
+const defaultShutdownGrace = 30 * time.Second
+
+// runShutdown stops a one-shot run in stages on SIGINT or SIGTERM: new work stops at once,
+// in-flight API batches get the grace period, then the run's context is cancelled
+// A second signal cancels immediately
+type runShutdown struct {
+	grace   time.Duration
+	signals chan os.Signal
+	done    chan struct{}
+	mu      sync.Mutex
+	signal  os.Signal
+}
+
+func watchShutdown(sitemapMonitor *monitor.SitemapMonitor, cancelRun context.CancelFunc, grace time.Duration) *runShutdown {
+	s := &runShutdown{
+		grace:   grace,
+		signals: make(chan os.Signal, 2),
+		done:    make(chan struct{}),
+	}
+	signal.Notify(s.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-s.signals:
+			s.mu.Lock()
+			s.signal = sig
+			s.mu.Unlock()
+			fmt.Printf("\n🛑 %s received: stopping new work, in-flight API batches have %s to finish (signal again to stop now)\n", sig, grace)
+			sitemapMonitor.Interrupt()
+		case <-s.done:
+			return
+		}
+
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			fmt.Println("⏱️  Grace period over, cancelling in-flight work")
+		case sig := <-s.signals:
+			fmt.Printf("🛑 %s received again, cancelling in-flight work\n", sig)
+		case <-s.done:
+			return
+		}
+		cancelRun()
+	}()
+	return s
+}
+
+// received returns the signal that stopped the run, nil if none did
+func (s *runShutdown) received() os.Signal {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signal
+}
+
+// drainContext bounds the submission flush after the run: the grace period once a signal
+// arrived, otherwise the usual wait
+func (s *runShutdown) drainContext(ctx context.Context, wait time.Duration) (context.Context, context.CancelFunc) {
+	if s.received() != nil {
+		return context.WithTimeout(context.WithoutCancel(ctx), s.grace)
+	}
+	return context.WithTimeout(ctx, wait)
+}
+
+// exitCode is 128 + the signal number (130 for SIGINT, 143 for SIGTERM), 0 when the run finished
+func (s *runShutdown) exitCode() int {
+	if sig, ok := s.received().(syscall.Signal); ok {
+		return 128 + int(sig)
+	}
+	return 0
+}
+
+// stop restores default signal handling
+func (s *runShutdown) stop() {
+	if s == nil {
+		return
+	}
+	signal.Stop(s.signals)
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		shutdown *runShutdown
+		want     int
+	}{
+		{"no signal", &runShutdown{}, 0},
+		{"no watcher", nil, 0},
+		{"SIGINT", &runShutdown{signal: syscall.SIGINT}, 130},
+		{"SIGTERM", &runShutdown{signal: syscall.SIGTERM}, 143},
+	}
+	for _, tt := range tests {
+		if got := tt.shutdown.exitCode(); got != tt.want {
+			t.Errorf("%s: exitCode() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}